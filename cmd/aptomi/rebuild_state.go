@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rebuildStateCmd = &cobra.Command{
+		Use:   "rebuild-state",
+		Short: "rebuild actual state from the cloud",
+		Long:  "rebuild actual state from what is running in the cloud (e.g. after the store got lost), so component instances found in the cloud get adopted instead of being created again. It refuses to run while Aptomi server holds the leader lease, use the API to rebuild actual state of a running server",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			adopted, err := server.NewServer(cfg).RebuildState()
+			if err != nil {
+				panic(fmt.Sprintf("Error while rebuilding actual state: %s", err))
+			}
+
+			fmt.Printf("Actual state rebuilt, %d component instances adopted\n", len(adopted))
+			for _, key := range adopted {
+				fmt.Println(key)
+			}
+		},
+	}
+)
+
+func init() {
+	serverCmd.AddCommand(rebuildStateCmd)
+}
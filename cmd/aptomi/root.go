@@ -40,7 +40,28 @@ func init() {
 	common.AddStringFlag(aptomiCmd, "db.connection", "db", "", "/var/lib/aptomi/db.bolt", envPrefix+"_DB_CONN", "DB connection string")
 	common.AddStringFlag(aptomiCmd, "ui.schema", "ui-schema", "", "http", envPrefix+"_SCHEMA", "Server UI schema")
 	common.AddBoolFlag(aptomiCmd, "ui.enable", "ui", "", true, envPrefix+"_UI", "Enable server to serve UI")
-	common.AddDurationFlag(aptomiCmd, "enforcer.interval", "enforcer-interval", "", 5*time.Second, envPrefix+"_ENFORCER_INTERVAL", "Enforcer interval")
+	common.AddStringFlag(aptomiCmd, "auth.secret", "auth-secret", "", "", envPrefix+"_AUTH_SECRET", "Secret used to sign auth tokens")
+	common.AddDurationFlag(aptomiCmd, "auth.tokenttl", "auth-token-ttl", "", 24*time.Hour, envPrefix+"_AUTH_TOKEN_TTL", "Auth token TTL")
+	common.AddDurationFlag(aptomiCmd, "enforcer.interval", "enforcer-interval", "", 30*time.Second, envPrefix+"_ENFORCER_INTERVAL", "Interval between periodic enforcer runs, policy changes are enforced right away")
+	common.AddDurationFlag(aptomiCmd, "enforcer.debounce", "enforcer-debounce", "", time.Second, envPrefix+"_ENFORCER_DEBOUNCE", "Delay before enforcing changed policy, so a burst of policy changes is enforced at once")
+	common.AddIntFlag(aptomiCmd, "enforcer.maxconcurrentactions", "enforcer-max-concurrent-actions", "", 8, envPrefix+"_ENFORCER_MAX_CONCURRENT_ACTIONS", "Max number of actions applied by enforcer in parallel")
+	common.AddDurationFlag(aptomiCmd, "enforcer.leaseduration", "enforcer-lease-duration", "", 15*time.Second, envPrefix+"_ENFORCER_LEASE_DURATION", "Duration of the leader lease, only the leader replica runs enforcer")
+	common.AddIntFlag(aptomiCmd, "enforcer.logretention", "enforcer-log-retention", "", 100, envPrefix+"_ENFORCER_LOG_RETENTION", "Number of latest revisions to keep event logs for")
+	common.AddDurationFlag(aptomiCmd, "enforcer.retrybackoffbase", "enforcer-retry-backoff-base", "", 10*time.Second, envPrefix+"_ENFORCER_RETRY_BACKOFF_BASE", "Delay before the first retry of a failed component, doubled with every next attempt")
+	common.AddDurationFlag(aptomiCmd, "enforcer.retrybackoffmax", "enforcer-retry-backoff-max", "", 10*time.Minute, envPrefix+"_ENFORCER_RETRY_BACKOFF_MAX", "Max delay between retries of a failed component")
+	common.AddIntFlag(aptomiCmd, "enforcer.retrymaxattempts", "enforcer-retry-max-attempts", "", 5, envPrefix+"_ENFORCER_RETRY_MAX_ATTEMPTS", "Max number of attempts to apply a failed component before it's marked as stuck")
+	common.AddBoolFlag(aptomiCmd, "enforcer.waitforready", "enforcer-wait-for-ready", "", false, envPrefix+"_ENFORCER_WAIT_FOR_READY", "Wait for components to become ready before deploying components depending on them")
+	common.AddDurationFlag(aptomiCmd, "enforcer.readytimeout", "enforcer-ready-timeout", "", 5*time.Minute, envPrefix+"_ENFORCER_READY_TIMEOUT", "Default time to wait for a component to become ready, could be overridden per component")
+	common.AddDurationFlag(aptomiCmd, "enforcer.readycheckinterval", "enforcer-ready-check-interval", "", 5*time.Second, envPrefix+"_ENFORCER_READY_CHECK_INTERVAL", "Interval between component readiness checks")
+	common.AddDurationFlag(aptomiCmd, "enforcer.actiontimeout", "enforcer-action-timeout", "", 10*time.Minute, envPrefix+"_ENFORCER_ACTION_TIMEOUT", "Max time a single action could take, 0 means no limit")
+	common.AddDurationFlag(aptomiCmd, "enforcer.revisiontimeout", "enforcer-revision-timeout", "", time.Hour, envPrefix+"_ENFORCER_REVISION_TIMEOUT", "Max time applying a single revision could take, 0 means no limit")
+	common.AddDurationFlag(aptomiCmd, "enforcer.shutdowntimeout", "enforcer-shutdown-timeout", "", 30*time.Second, envPrefix+"_ENFORCER_SHUTDOWN_TIMEOUT", "Max time to wait for the revision in progress to get interrupted on server shutdown")
+	common.AddIntFlag(aptomiCmd, "enforcer.maxdeleteactions", "enforcer-max-delete-actions", "", 0, envPrefix+"_ENFORCER_MAX_DELETE_ACTIONS", "Max number of component instances a revision could delete before it gets blocked until approved, 0 means no limit")
+	common.AddIntFlag(aptomiCmd, "enforcer.maxdeletepercent", "enforcer-max-delete-percent", "", 0, envPrefix+"_ENFORCER_MAX_DELETE_PERCENT", "Max percentage of component instances a revision could delete before it gets blocked until approved, 0 means no limit")
+	common.AddStringFlag(aptomiCmd, "enforcer.approvalnamespaces", "enforcer-approval-namespaces", "", "", envPrefix+"_ENFORCER_APPROVAL_NAMESPACES", "Comma-separated list of namespaces, revisions changing component instances in which wait for manual approval")
+	common.AddDurationFlag(aptomiCmd, "enforcer.driftcheckinterval", "enforcer-drift-check-interval", "", 0, envPrefix+"_ENFORCER_DRIFT_CHECK_INTERVAL", "Interval between checks whether component instances running in the cloud drifted from the actual state, 0 disables drift checks")
+	common.AddBoolFlag(aptomiCmd, "enforcer.driftcorrection", "enforcer-drift-correction", "", false, envPrefix+"_ENFORCER_DRIFT_CORRECTION", "Update drifted component instances, so they get back to the actual state")
+	common.AddDurationFlag(aptomiCmd, "enforcer.readinessinterval", "enforcer-readiness-interval", "", time.Minute, envPrefix+"_ENFORCER_READINESS_INTERVAL", "Interval between refreshes of component instance readiness reported by API, 0 disables refreshes")
 
 	aptomiCmd.AddCommand(NewVersionCommand())
 }
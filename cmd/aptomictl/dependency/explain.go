@@ -0,0 +1,40 @@
+package dependency
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func newExplainCommand(cfg *config.Client) *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain <namespace>/<name>",
+		Short: "dependency explain",
+		Long:  "explain why dependency resolved the way it did (contexts tested, rules applied, resulting instances)",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				panic("Dependency to explain should be specified as <namespace>/<name>")
+			}
+			parts := strings.Split(args[0], "/")
+			if len(parts) != 2 {
+				panic(fmt.Sprintf("Dependency should be specified as <namespace>/<name>, got: %s", args[0]))
+			}
+
+			result, err := rest.New(cfg, http.NewClient(cfg)).Dependency().Explain(parts[0], parts[1])
+			if err != nil {
+				panic(fmt.Sprintf("Error while explaining dependency: %s", err))
+			}
+
+			data, err := common.Format(cfg.Output, false, result)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating dependency explanation: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+}
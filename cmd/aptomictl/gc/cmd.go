@@ -0,0 +1,22 @@
+package gc
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns cobra command for gc subcommand
+func NewCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "gc subcommand",
+		Long:  "garbage collection of deployments left in the cloud, which aren't referenced by any component instance",
+	}
+
+	cmd.AddCommand(
+		newPlanCommand(cfg),
+		newRunCommand(cfg),
+	)
+
+	return cmd
+}
@@ -0,0 +1,53 @@
+package gc
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/gc"
+	"github.com/Aptomi/aptomi/pkg/util/retry"
+	"github.com/gosuri/uitable"
+	"time"
+)
+
+// waitForGarbageCollection waits until the requested garbage collection step is done by the server and returns its
+// results
+func waitForGarbageCollection(attempts int, interval time.Duration, client client.Core) *engine.GarbageCollection {
+	fmt.Print("Waiting for garbage collection...")
+
+	var collection *engine.GarbageCollection
+	finished := retry.Do(attempts, interval, func() bool {
+		var err error
+		collection, err = client.GC().Show()
+		if err != nil || collection.IsInProgress() {
+			fmt.Print(".")
+			return false
+		}
+		return true
+	})
+	fmt.Println()
+
+	if !finished {
+		panic(fmt.Sprintf("Timeout. Garbage collection hasn't been done in %s", time.Duration(attempts)*interval))
+	}
+	if collection.Status == engine.GarbageCollectionStatusError {
+		panic(fmt.Sprintf("Error. Garbage collection failed: %s", collection.Error))
+	}
+
+	return collection
+}
+
+func printOrphans(title string, orphans []*gc.Orphan) {
+	if len(orphans) <= 0 {
+		fmt.Println("No orphaned deployments")
+		return
+	}
+
+	fmt.Println(title)
+	table := uitable.New()
+	table.AddRow("Cluster", "Name")
+	for _, orphan := range orphans {
+		table.AddRow(orphan.Cluster, orphan.Name)
+	}
+	fmt.Println(table)
+}
@@ -0,0 +1,40 @@
+package gc
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+func newPlanCommand(cfg *config.Client) *cobra.Command {
+	var waitInterval time.Duration
+	var waitAttempts int
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "gc plan",
+		Long:  "find deployments left in the cloud, which aren't referenced by any component instance, without deleting them",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			client := rest.New(cfg, http.NewClient(cfg))
+			_, err := client.GC().Plan()
+			if err != nil {
+				panic(fmt.Sprintf("Error while planning garbage collection: %s", err))
+			}
+
+			collection := waitForGarbageCollection(waitAttempts, waitInterval, client)
+			printOrphans("Orphaned deployments found:", collection.Orphans)
+			if len(collection.Orphans) > 0 {
+				fmt.Println("Run 'aptomictl gc run' to delete them")
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&waitInterval, "wait-interval", 2*time.Second, "Seconds to sleep between wait attempts")
+	cmd.Flags().IntVar(&waitAttempts, "wait-attempts", 150, "Number of attempts to do before failure while waiting")
+
+	return cmd
+}
@@ -0,0 +1,72 @@
+package gc
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+func newRunCommand(cfg *config.Client) *cobra.Command {
+	var confirmed bool
+	var waitInterval time.Duration
+	var waitAttempts int
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "gc run",
+		Long:  "delete orphaned deployments found by the latest 'aptomictl gc plan', which are still not referenced by any component instance",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			client := rest.New(cfg, http.NewClient(cfg))
+			collection, err := client.GC().Show()
+			if err != nil {
+				panic(fmt.Sprintf("Error while getting garbage collection: %s", err))
+			}
+			if collection.Status != engine.GarbageCollectionStatusPlanned {
+				panic(fmt.Sprintf("Garbage collection should be planned first using 'aptomictl gc plan', current status: %s", collection.Status))
+			}
+
+			printOrphans("Orphaned deployments to delete:", collection.Orphans)
+			if len(collection.Orphans) <= 0 {
+				return
+			}
+
+			if !confirmed && !confirm(fmt.Sprintf("Delete %d orphaned deployments?", len(collection.Orphans))) {
+				fmt.Println("Garbage collection cancelled")
+				return
+			}
+
+			_, err = client.GC().Run(collection.PlannedAt)
+			if err != nil {
+				panic(fmt.Sprintf("Error while running garbage collection: %s", err))
+			}
+
+			collection = waitForGarbageCollection(waitAttempts, waitInterval, client)
+			printOrphans("Orphaned deployments deleted:", collection.Deleted)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&confirmed, "yes", "y", false, "Delete orphaned deployments without asking for confirmation")
+	cmd.Flags().DurationVar(&waitInterval, "wait-interval", 2*time.Second, "Seconds to sleep between wait attempts")
+	cmd.Flags().IntVar(&waitAttempts, "wait-attempts", 150, "Number of attempts to do before failure while waiting")
+
+	return cmd
+}
+
+// confirm asks user the given question and returns true if user answers yes
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
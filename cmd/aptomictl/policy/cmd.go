@@ -15,8 +15,11 @@ func NewCommand(cfg *config.Client) *cobra.Command {
 
 	cmd.AddCommand(
 		newShowCommand(cfg),
+		newDiffCommand(cfg),
 		newApplyCommand(cfg),
+		newPlanCommand(cfg),
 		newDeleteCommand(cfg),
+		newRollbackCommand(cfg),
 	)
 
 	return cmd
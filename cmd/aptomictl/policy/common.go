@@ -155,6 +155,18 @@ func waitForApplyToFinish(attempts int, interval time.Duration, client client.Co
 		progressBar.Done(false)
 		fmt.Printf("Error. Revision %d failed with an error and has not been fully applied\n", rev.GetGeneration())
 		panic("error")
+	} else if rev.Status == engine.RevisionStatusInterrupted {
+		progressBar.Done(false)
+		fmt.Printf("Interrupted. Revision %d got interrupted and has not been fully applied\n", rev.GetGeneration())
+		panic("interrupted")
+	} else if rev.Status == engine.RevisionStatusBlocked {
+		progressBar.Done(false)
+		fmt.Printf("Blocked. Revision %d is too destructive and needs to be approved (aptomictl revision approve -g %d): %s\n", rev.GetGeneration(), rev.GetGeneration(), rev.BlockedReason)
+		panic("blocked")
+	} else if rev.Status == engine.RevisionStatusPendingApproval {
+		progressBar.Done(false)
+		fmt.Printf("Pending approval. Revision %d needs to be approved (aptomictl revision approve -g %d): %s\n", rev.GetGeneration(), rev.GetGeneration(), rev.BlockedReason)
+		panic("pending approval")
 	}
 
 }
@@ -0,0 +1,44 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCommand(cfg *config.Client) *cobra.Command {
+	var from uint64 // == runtime.Generation
+	var to uint64   // == runtime.Generation
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "policy diff",
+		Long:  "show objects added, removed and changed between two policy generations",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			if from == 0 {
+				panic("Policy generation to compare from is not specified")
+			}
+
+			result, err := rest.New(cfg, http.NewClient(cfg)).Policy().Diff(runtime.Generation(to), runtime.Generation(from))
+			if err != nil {
+				panic(fmt.Sprintf("Error while comparing policies: %s", err))
+			}
+
+			data, err := common.Format(cfg.Output, false, result)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating policy diff: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	cmd.Flags().Uint64Var(&from, "from", 0, "Base policy generation")
+	cmd.Flags().Uint64Var(&to, "to", 0, "Policy generation to compare with the base one (latest by default)")
+
+	return cmd
+}
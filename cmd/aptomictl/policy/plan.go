@@ -0,0 +1,43 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newPlanCommand(cfg *config.Client) *cobra.Command {
+	paths := make([]string, 0)
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "show actions for applying policy files",
+		Long:  "show actions, which will be executed after applying policy files, without actually applying them",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			allObjects, err := readLangFromFiles(paths)
+			if err != nil {
+				panic(fmt.Sprintf("Error while reading policy files for planning: %s", err))
+			}
+
+			client := rest.New(cfg, http.NewClient(cfg))
+			result, err := client.Policy().Plan(allObjects)
+			if err != nil {
+				panic(fmt.Sprintf("Error while planning policy: %s", err))
+			}
+
+			data, err := common.Format(cfg.Output, false, result)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating policy plan result: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&paths, "policyPaths", "f", make([]string, 0), "Paths to files, dirs with policy to plan")
+
+	return cmd
+}
@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+func newRollbackCommand(cfg *config.Client) *cobra.Command {
+	var gen uint64 // == runtime.Generation
+	var wait bool
+	var waitInterval time.Duration
+	var waitAttempts int
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "rollback policy to a previous generation",
+		Long:  "rollback policy to a previous generation by creating a new generation with the same objects",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			if gen == 0 {
+				panic("Policy generation to rollback to is not specified")
+			}
+
+			client := rest.New(cfg, http.NewClient(cfg))
+			result, err := client.Policy().Rollback(runtime.Generation(gen))
+			if err != nil {
+				panic(fmt.Sprintf("Error while rolling back policy: %s", err))
+			}
+
+			data, err := common.Format(cfg.Output, false, result)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating policy update result: %s", err))
+			}
+			fmt.Println(string(data))
+
+			if !wait {
+				return
+			}
+
+			waitForApplyToFinish(waitAttempts, waitInterval, client, result)
+		},
+	}
+
+	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Policy generation to rollback to")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait until first revision with rolled back policy will be fully applied")
+	cmd.Flags().DurationVar(&waitInterval, "wait-interval", 2*time.Second, "Seconds to sleep between wait attempts")
+	cmd.Flags().IntVar(&waitAttempts, "wait-attempts", 150, "Number of attempts to do before failure while waiting")
+
+	return cmd
+}
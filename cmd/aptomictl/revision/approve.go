@@ -0,0 +1,33 @@
+package revision
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+func newApproveCommand(cfg *config.Client) *cobra.Command {
+	var gen uint64
+
+	cmd := &cobra.Command{
+		Use:   "approve",
+		Short: "revision approve",
+		Long:  "approve revision, which got blocked as it deletes too many component instances or requires manual approval, so it gets applied",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Revision().Approve(runtime.Generation(gen))
+			if err != nil {
+				panic(fmt.Sprintf("Error while approving revision: %s", err))
+			}
+
+			fmt.Printf("Revision %d (policy gen %d) approved by %s and is going to be applied\n", result.GetGeneration(), result.Policy, result.ApprovedBy)
+		},
+	}
+
+	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Revision generation (latest by default)")
+
+	return cmd
+}
@@ -15,6 +15,9 @@ func NewCommand(cfg *config.Client) *cobra.Command {
 
 	cmd.AddCommand(
 		newShowCommand(cfg),
+		newLogCommand(cfg),
+		newApproveCommand(cfg),
+		newRejectCommand(cfg),
 	)
 
 	return cmd
@@ -0,0 +1,40 @@
+package revision
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+func newLogCommand(cfg *config.Client) *cobra.Command {
+	var gen uint64
+	var level string
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "revision log",
+		Long:  "show event log (policy resolution and apply) of the revision",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Revision().Log(runtime.Generation(gen), level)
+			if err != nil {
+				panic(fmt.Sprintf("Error while requesting revision log: %s", err))
+			}
+
+			data, err := common.Format(cfg.Output, false, result)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating revision log: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Revision generation (latest by default)")
+	cmd.Flags().StringVarP(&level, "level", "l", "", "Show only entries with this level or more severe one (debug, info, warning, error)")
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package revision
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+func newRejectCommand(cfg *config.Client) *cobra.Command {
+	var gen uint64
+
+	cmd := &cobra.Command{
+		Use:   "reject",
+		Short: "revision reject",
+		Long:  "reject revision, which got blocked or requires manual approval, so it doesn't get applied",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Revision().Reject(runtime.Generation(gen))
+			if err != nil {
+				panic(fmt.Sprintf("Error while rejecting revision: %s", err))
+			}
+
+			fmt.Printf("Revision %d (policy gen %d) rejected by %s and is not going to be applied\n", result.GetGeneration(), result.Policy, result.RejectedBy)
+		},
+	}
+
+	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Revision generation (latest by default)")
+
+	return cmd
+}
@@ -7,11 +7,15 @@ import (
 	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
+	"sort"
+	"time"
 )
 
 func newShowCommand(cfg *config.Client) *cobra.Command {
 	var gen, policyGen uint64
+	var showActions bool
 
 	cmd := &cobra.Command{
 		Use:   "show",
@@ -38,11 +42,51 @@ func newShowCommand(cfg *config.Client) *cobra.Command {
 
 			// todo(slukjanov): replace with -o yaml / json / etc handler
 			fmt.Println(result)
+
+			if len(result.Deferred) > 0 {
+				fmt.Println("Changes of the following component instances got deferred due to freeze windows:")
+				keys := make([]string, 0, len(result.Deferred))
+				for key := range result.Deferred {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					fmt.Printf("  %s: %s\n", key, result.Deferred[key])
+				}
+			}
+
+			if showActions {
+				printActions(result)
+			}
 		},
 	}
 
 	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Revision generation")
 	cmd.Flags().Uint64VarP(&policyGen, "policy", "p", 0, "Policy generation")
+	cmd.Flags().BoolVar(&showActions, "actions", false, "Show actions applied within revision along with their results")
 
 	return cmd
 }
+
+func printActions(revision *engine.Revision) {
+	if len(revision.Actions) <= 0 {
+		fmt.Println("No actions have been applied within revision")
+		return
+	}
+
+	table := uitable.New()
+	table.MaxColWidth = 120
+	table.Wrap = true
+	table.AddRow("#", "Action", "Status", "Started At", "Duration", "Error")
+	for idx, act := range revision.Actions {
+		startedAt, duration := "", ""
+		if !act.StartedAt.IsZero() {
+			startedAt = act.StartedAt.Format(time.RFC3339)
+		}
+		if !act.StartedAt.IsZero() && !act.FinishedAt.IsZero() {
+			duration = act.Duration.String()
+		}
+		table.AddRow(idx+1, act.Name, act.Status, startedAt, duration, act.Error)
+	}
+	fmt.Println(table)
+}
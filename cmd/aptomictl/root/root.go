@@ -2,7 +2,9 @@ package root
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/cmd/aptomictl/dependency"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/endpoints"
+	"github.com/Aptomi/aptomi/cmd/aptomictl/gc"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/gen"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/policy"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/revision"
@@ -48,13 +50,17 @@ func init() {
 	common.AddStringFlag(Command, "output", "output", "o", "text", EnvPrefix+"_OUTPUT", "Output format. One of: text (default), json, yaml")
 
 	common.AddStringFlag(Command, "auth.username", "username", "u", "", EnvPrefix+"_USERNAME", "Username")
+	common.AddStringFlag(Command, "auth.password", "password", "", "", EnvPrefix+"_PASSWORD", "Password")
+	common.AddStringFlag(Command, "auth.token", "token", "", "", EnvPrefix+"_TOKEN", "Auth token (if not provided, it'll be obtained using username and password)")
 	common.AddDurationFlag(Command, "http.timeout", "timeout", "", 15*time.Second, EnvPrefix+"_TIMEOUT", "HTTP Timeout")
 
 	// Add sub commands
 	Command.AddCommand(
+		dependency.NewCommand(Config),
 		endpoints.NewCommand(Config),
 		policy.NewCommand(Config),
 		revision.NewCommand(Config),
+		gc.NewCommand(Config),
 		gen.NewCommand(Config),
 		version.NewCommand(Config),
 	)
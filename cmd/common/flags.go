@@ -19,6 +19,12 @@ func AddBoolFlag(command *cobra.Command, key, flagName, flagShorthand string, de
 	bindFlagEnv(command, key, flagName, env)
 }
 
+// AddIntFlag adds int flag to provided cobra command and registers with provided env variable name
+func AddIntFlag(command *cobra.Command, key, flagName, flagShorthand string, defaultValue int, env, usage string) {
+	command.PersistentFlags().IntP(flagName, flagShorthand, defaultValue, usage)
+	bindFlagEnv(command, key, flagName, env)
+}
+
 // AddDurationFlag adds duration flag to provided cobra command and registers with provided env variable name
 func AddDurationFlag(command *cobra.Command, key, flagName, flagShorthand string, defaultValue time.Duration, env, usage string) {
 	command.PersistentFlags().DurationP(flagName, flagShorthand, defaultValue, usage)
@@ -4,9 +4,28 @@ import (
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
+	"strconv"
 )
 
 func (api *coreAPI) handleActualStateReset(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	// with adopt option actual state gets reset and rebuilt from the cloud by enforcer in one step, so it never sees
+	// empty actual state and doesn't create again component instances, which are running in the cloud
+	adopt, _ := strconv.ParseBool(request.URL.Query().Get("adopt"))
+	if adopt {
+		user := api.getUserRequired(request)
+		if !user.DomainAdmin {
+			panic(fmt.Sprintf("User '%s' is not a domain admin and can't reset actual state", user.Name))
+		}
+
+		rebuild, err := api.store.RequestStateRebuild(user.Name, true)
+		if err != nil {
+			panic(fmt.Sprintf("error while requesting actual state reset and rebuild: %s", err))
+		}
+
+		api.contentType.WriteOne(writer, request, rebuild)
+		return
+	}
+
 	err := api.store.ResetActualState()
 	if err != nil {
 		panic(fmt.Sprintf("error while resetting actual state: %s", err))
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/external"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/runtime/store"
@@ -12,12 +13,13 @@ type coreAPI struct {
 	contentType  *codec.ContentTypeHandler
 	store        store.Core
 	externalData *external.Data
+	auth         config.ServerAuth
 }
 
 // Serve initializes everything needed by REST API and registers all API endpoints in the provided http router
-func Serve(router *httprouter.Router, store store.Core, externalData *external.Data) {
+func Serve(router *httprouter.Router, store store.Core, externalData *external.Data, auth config.ServerAuth) {
 	contentTypeHandler := codec.NewContentTypeHandler(runtime.NewRegistry().Append(Objects...))
-	api := &coreAPI{contentTypeHandler, store, externalData}
+	api := &coreAPI{contentTypeHandler, store, externalData, auth}
 	api.serve(router)
 }
 
@@ -39,6 +41,15 @@ func (api *coreAPI) serve(router *httprouter.Router) {
 	router.POST("/api/v1/policy", api.handlePolicyUpdate)
 	router.DELETE("/api/v1/policy", api.handlePolicyDelete)
 
+	// rollback policy to a given generation (creates new generation with the same objects)
+	router.POST("/api/v1/policy/rollback/gen/:gen", api.handlePolicyRollback)
+
+	// plan policy update (returns actions without saving the policy)
+	router.POST("/api/v1/policy/plan", api.handlePolicyPlan)
+
+	// object-level diff between two policy generations
+	router.GET("/api/v1/policy/diff/gen/:gen/genBase/:genBase", api.handlePolicyDiff)
+
 	// policy diagrams
 	router.GET("/api/v1/policy/diagram/mode/:mode", api.handlePolicyDiagram)
 	router.GET("/api/v1/policy/diagram/mode/:mode/gen/:gen", api.handlePolicyDiagram)
@@ -47,6 +58,9 @@ func (api *coreAPI) serve(router *httprouter.Router) {
 	// retrieve dependency along with its status
 	router.GET("/api/v1/policy/dependency/:ns/:name/status", api.handleDependencyStatusGet)
 
+	// explain how dependency gets resolved
+	router.GET("/api/v1/policy/dependency/:ns/:name/explain", api.handleDependencyExplain)
+
 	// retrieve endpoints (all + by dependency)
 	router.GET("/api/v1/endpoints", api.handleEndpointsGet)
 	router.GET("/api/v1/endpoints/dependency/:ns/:name", api.handleEndpointsGet)
@@ -55,12 +69,34 @@ func (api *coreAPI) serve(router *httprouter.Router) {
 	router.GET("/api/v1/revision", api.handleRevisionGet)
 	router.GET("/api/v1/revision/gen/:gen", api.handleRevisionGet)
 
+	// approve or reject revision, which got blocked as too destructive or requires manual approval
+	router.POST("/api/v1/revision/gen/:gen/approve", api.handleRevisionApprove)
+	router.POST("/api/v1/revision/gen/:gen/reject", api.handleRevisionReject)
+
+	// retrieve event log of the revision (optionally filtered by level)
+	router.GET("/api/v1/revision/gen/:gen/log", api.handleRevisionLogGet)
+
 	// retrieve revision(s) (for a given policy)
 	router.GET("/api/v1/revision/policy/:policy", api.handleRevisionGetByPolicy)
 	router.GET("/api/v1/revisions/policy/:policy", api.handleRevisionsGetByPolicy)
 
 	router.DELETE("/api/v1/actualstate", api.handleActualStateReset)
 
+	// rebuild actual state from the cloud (adopting component instances found there) and retrieve its results
+	router.POST("/api/v1/actualstate/rebuild", api.handleStateRebuildRequest)
+	router.GET("/api/v1/actualstate/rebuild", api.handleStateRebuildGet)
+
+	// retrieve results of the latest drift check (component instances, which drifted in the cloud)
+	router.GET("/api/v1/drift", api.handleDriftGet)
+
+	// find orphaned deployments in the cloud (plan), delete them once confirmed (run) and retrieve the results
+	router.GET("/api/v1/gc", api.handleGarbageCollectionGet)
+	router.POST("/api/v1/gc/plan", api.handleGarbageCollectionPlan)
+	router.POST("/api/v1/gc/run", api.handleGarbageCollectionRun)
+
+	// return status of aptomi server replicas (including current leader)
+	router.GET("/api/v1/status", api.handleStatus)
+
 	// return aptomi version
 	router.GET("/version", api.handleVersion)
 	router.GET("/api/v1/version", api.handleVersion)
@@ -1,13 +1,98 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
+	"time"
 )
 
+// AuthSuccessObject contains Info for the AuthSuccess type
+var AuthSuccessObject = &runtime.Info{
+	Kind:        "auth-success",
+	Constructor: func() runtime.Object { return &AuthSuccess{} },
+}
+
+// AuthSuccess represents successful authentication and contains token that should be used for all subsequent requests
+type AuthSuccess struct {
+	runtime.TypeKind `yaml:",inline"`
+	Token            string
+	ExpiresAt        time.Time
+}
+
+// TokenClaims represents claims of the token issued by the API after successful authentication
+type TokenClaims struct {
+	Name string `json:"name"`
+	jwt.StandardClaims
+}
+
+type contextKey string
+
+const authUsernameKey contextKey = "auth-username"
+
+// ContextWithUsername returns copy of the provided context with the name of the authenticated user stored in it
+func ContextWithUsername(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, authUsernameKey, username)
+}
+
+// UsernameFromContext returns name of the authenticated user stored in the provided context or empty string if there
+// is no authenticated user
+func UsernameFromContext(ctx context.Context) string {
+	username, ok := ctx.Value(authUsernameKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return username
+}
+
+// NewToken returns signed token for the provided user name, which will expire after the specified TTL
+func NewToken(secret string, username string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := &TokenClaims{
+		Name: username,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    "aptomi",
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error while signing token: %s", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// ParseToken verifies signature and expiration of the provided token and returns its claims
+func ParseToken(secret string, tokenString string) (*TokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %s", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*TokenClaims)
+	if !ok || !token.Valid || len(claims.Name) == 0 {
+		return nil, fmt.Errorf("token is invalid")
+	}
+
+	return claims, nil
+}
+
 func (api *coreAPI) getUserOptional(request *http.Request) *lang.User {
-	username := request.Header.Get("Username")
+	username := UsernameFromContext(request.Context())
 
 	if len(username) == 0 {
 		return nil
@@ -30,8 +115,15 @@ func (api *coreAPI) authenticateUser(writer http.ResponseWriter, request *http.R
 	password := request.PostFormValue("password")
 	user, err := api.externalData.UserLoader.Authenticate(username, password)
 	if user == nil || err != nil {
-		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusUnauthorized)
-	} else {
-		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusOK)
+		serverErr := NewServerError(fmt.Sprintf("Authentication failed for user '%s'", username))
+		api.contentType.WriteOneWithStatus(writer, request, serverErr, http.StatusUnauthorized)
+		return
 	}
+
+	token, expiresAt, err := NewToken(api.auth.Secret, user.Name, api.auth.TokenTTL)
+	if err != nil {
+		panic(fmt.Sprintf("Error while issuing token for user %s: %s", user.Name, err))
+	}
+
+	api.contentType.WriteOneWithStatus(writer, request, &AuthSuccess{AuthSuccessObject.GetTypeKind(), token, expiresAt}, http.StatusOK)
 }
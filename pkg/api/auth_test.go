@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/external"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenValid(t *testing.T) {
+	token, expiresAt, err := NewToken("secret", "Alice", time.Hour)
+	assert.NoError(t, err, "Token should be issued without errors")
+	assert.True(t, expiresAt.After(time.Now()), "Token expiration time should be in the future")
+
+	claims, err := ParseToken("secret", token)
+	assert.NoError(t, err, "Token should be parsed without errors")
+	assert.Equal(t, "Alice", claims.Name, "Token should contain correct user name")
+}
+
+func TestTokenInvalid(t *testing.T) {
+	token, _, err := NewToken("secret", "Alice", time.Hour)
+	assert.NoError(t, err, "Token should be issued without errors")
+
+	_, err = ParseToken("another-secret", token)
+	assert.Error(t, err, "Token signed with different secret should be rejected")
+
+	_, err = ParseToken("secret", token+"x")
+	assert.Error(t, err, "Token with broken signature should be rejected")
+
+	expiredToken, _, err := NewToken("secret", "Alice", -time.Hour)
+	assert.NoError(t, err, "Token should be issued without errors")
+
+	_, err = ParseToken("secret", expiredToken)
+	assert.Error(t, err, "Expired token should be rejected")
+}
+
+func TestUsernameContext(t *testing.T) {
+	assert.Equal(t, "", UsernameFromContext(context.Background()), "Empty user name should be returned if there is no authenticated user")
+	assert.Equal(t, "Bob", UsernameFromContext(ContextWithUsername(context.Background(), "Bob")), "User name stored in context should be returned")
+}
+
+func TestAuthenticateUserForm(t *testing.T) {
+	api := &coreAPI{
+		contentType:  codec.NewContentTypeHandler(runtime.NewRegistry().Append(Objects...)),
+		externalData: external.NewData(users.NewUserLoaderFromFile("../testdata/unittests/users.yaml", make(map[string]bool)), nil),
+		auth:         config.ServerAuth{Secret: "secret", TokenTTL: time.Hour},
+	}
+
+	// url-encoded form, as posted by CLI
+	form := url.Values{}
+	form.Set("username", "Alice")
+	form.Set("password", "alice")
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/user/authenticate", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", codec.Default)
+	obj := authenticate(t, api, request, http.StatusOK)
+	if authSuccess, ok := obj.(*AuthSuccess); assert.True(t, ok, "Successful authentication should be returned") {
+		claims, err := ParseToken("secret", authSuccess.Token)
+		assert.NoError(t, err, "Valid token should be issued")
+		assert.Equal(t, "Alice", claims.Name, "Token should be issued for the authenticated user")
+	}
+
+	// multipart form without Accept header, as posted by web UI
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.NoError(t, writer.WriteField("username", "Bob"), "Form field should be written")
+	assert.NoError(t, writer.WriteField("password", "wrong"), "Form field should be written")
+	assert.NoError(t, writer.Close(), "Form should be written")
+	request = httptest.NewRequest(http.MethodPost, "/api/v1/user/authenticate", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	obj = authenticate(t, api, request, http.StatusUnauthorized)
+	if serverErr, ok := obj.(*ServerError); assert.True(t, ok, "Server error should be returned") {
+		assert.Contains(t, serverErr.Error, "Bob", "Server error should mention the user")
+	}
+}
+
+func authenticate(t *testing.T, api *coreAPI, request *http.Request, status int) runtime.Object {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	api.authenticateUser(recorder, request, nil)
+
+	assert.Equal(t, status, recorder.Code, "Unexpected response status")
+	assert.Equal(t, codec.Default, recorder.Header().Get("Content-Type"), "Response should be encoded with default codec")
+	obj, err := api.contentType.GetCodec(recorder.Header()).DecodeOne(recorder.Body.Bytes())
+	if !assert.NoError(t, err, "Response should be decoded") {
+		t.FailNow()
+	}
+	return obj
+}
@@ -5,7 +5,9 @@ import (
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/runtime/codec/yaml"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -43,24 +45,43 @@ func (handler *ContentTypeHandler) GetCodecByContentType(contentType string) run
 	return codec
 }
 
-// GetCodec returns runtime codec for specified http headers based on the content type
+// GetCodec returns runtime codec for specified http headers based on the content type. Default codec is used if
+// there is no codec for the content type (e.g. for html forms)
 func (handler *ContentTypeHandler) GetCodec(header http.Header) runtime.Codec {
-	contentType := header.Get("Content-Type")
-	if len(contentType) == 0 {
+	contentType, ok := handler.supported(header.Get("Content-Type"))
+	if !ok {
 		contentType = Default
 	}
 
 	return handler.GetCodecByContentType(contentType)
 }
 
-// GetContentType returns content type for provided http headers
+// GetContentType returns content type of the response for provided http request headers. It's the first content type
+// listed in Accept header, which has a codec, or the content type of the request itself (if it has a codec), or
+// the default content type otherwise (e.g. for html forms)
 func (handler *ContentTypeHandler) GetContentType(header http.Header) string {
-	contentType := header.Get("Content-Type")
-	if len(contentType) == 0 {
-		contentType = "application/yaml"
+	for _, accept := range strings.Split(header.Get("Accept"), ",") {
+		if contentType, ok := handler.supported(accept); ok {
+			return contentType
+		}
+	}
+
+	if contentType, ok := handler.supported(header.Get("Content-Type")); ok {
+		return contentType
+	}
+
+	return Default
+}
+
+// supported returns media type (without parameters) of the given content type and whether there is a codec for it
+func (handler *ContentTypeHandler) supported(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
 	}
+	_, exist := handler.codecs[mediaType]
 
-	return contentType
+	return mediaType, exist
 }
 
 // Read runtime object(s) from the provided request using correct content type (taken from the request)
@@ -79,20 +100,20 @@ func (handler *ContentTypeHandler) Read(request *http.Request) []runtime.Object
 	return objects
 }
 
-// WriteOne runtime object into the provided response writer using correct content type (taken from provided request)
+// WriteOne runtime object into the provided response writer using correct content type (negotiated with provided request)
 // with default http status (200 OK)
 func (handler *ContentTypeHandler) WriteOne(writer http.ResponseWriter, request *http.Request, body runtime.Object) {
 	handler.WriteOneWithStatus(writer, request, body, http.StatusOK)
 }
 
-// WriteOneWithStatus runtime object into the provided response writer using correct content type (taken from provided request)
+// WriteOneWithStatus runtime object into the provided response writer using correct content type (negotiated with provided request)
 // with specified http status
 func (handler *ContentTypeHandler) WriteOneWithStatus(writer http.ResponseWriter, request *http.Request, body runtime.Object, status int) {
 	writer.Header().Set("Content-Type", handler.GetContentType(request.Header))
 	writer.WriteHeader(status)
 
 	if body != nil {
-		data, err := handler.GetCodec(request.Header).EncodeOne(body)
+		data, err := handler.GetCodecByContentType(handler.GetContentType(request.Header)).EncodeOne(body)
 		if err != nil {
 			panic(fmt.Sprintf("Error while encoding body of kind %s: %s", body.GetKind(), err))
 		}
@@ -104,20 +125,20 @@ func (handler *ContentTypeHandler) WriteOneWithStatus(writer http.ResponseWriter
 	}
 }
 
-// WriteMany runtime objects into the provided response writer using correct content type (taken from provided request)
+// WriteMany runtime objects into the provided response writer using correct content type (negotiated with provided request)
 // with default http status (200 OK)
 func (handler *ContentTypeHandler) WriteMany(writer http.ResponseWriter, request *http.Request, body []runtime.Object) {
 	handler.WriteManyWithStatus(writer, request, body, http.StatusOK)
 }
 
-// WriteManyWithStatus runtime objects into the provided response writer using correct content type (taken from provided request)
+// WriteManyWithStatus runtime objects into the provided response writer using correct content type (negotiated with provided request)
 // with specified http status
 func (handler *ContentTypeHandler) WriteManyWithStatus(writer http.ResponseWriter, request *http.Request, body []runtime.Object, status int) {
 	writer.Header().Set("Content-Type", handler.GetContentType(request.Header))
 	writer.WriteHeader(status)
 
 	if body != nil {
-		data, err := handler.GetCodec(request.Header).EncodeMany(body)
+		data, err := handler.GetCodecByContentType(handler.GetContentType(request.Header)).EncodeMany(body)
 		if err != nil {
 			if len(body) > 0 {
 				panic(fmt.Sprintf("Error while encoding body of kind %s: %s", body[0].GetKind(), err))
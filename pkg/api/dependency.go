@@ -2,6 +2,8 @@ package api
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/julienschmidt/httprouter"
@@ -10,13 +12,23 @@ import (
 
 type dependencyStatusWrapper struct {
 	Data interface{}
+
+	// Error is set if dependency can't be resolved due to an error
+	Error string `yaml:",omitempty"`
+
+	// Readiness is a readiness of every deployed component instance (by key) the dependency got resolved into
+	Readiness map[string]string `yaml:",omitempty"`
 }
 
 func (g *dependencyStatusWrapper) GetKind() string {
 	return "dependencyStatus"
 }
 
-func (api *coreAPI) handleDependencyStatusGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+// getDependency loads dependency from the latest policy and verifies that user is allowed to view it. If dependency
+// doesn't exist, then nil will be returned
+func (api *coreAPI) getDependency(request *http.Request, params httprouter.Params) (*lang.Policy, *lang.Dependency) {
+	user := api.getUserRequired(request)
+
 	gen := runtime.LastGen
 	policy, _, err := api.store.GetPolicy(gen)
 	if err != nil {
@@ -32,11 +44,26 @@ func (api *coreAPI) handleDependencyStatusGet(writer http.ResponseWriter, reques
 		panic(fmt.Sprintf("error while getting object %s/%s/%s in policy #%s", ns, kind, name, gen))
 	}
 	if obj == nil {
+		return policy, nil
+	}
+
+	dependency := obj.(*lang.Dependency)
+	errView := policy.View(user).ViewObject(dependency)
+	if errView != nil {
+		panic(fmt.Sprintf("error while getting object %s/%s/%s in policy #%s: %s", ns, kind, name, gen, errView))
+	}
+
+	return policy, dependency
+}
+
+func (api *coreAPI) handleDependencyStatusGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	policy, dependency := api.getDependency(request, params)
+	if dependency == nil {
 		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
 	}
 
 	// once dependency is loaded, we need to find its state in the actual state
-	dependency := obj.(*lang.Dependency)
 	actualState, err := api.store.GetActualState()
 	if err != nil {
 		panic(fmt.Sprintf("Can't load actual state to get endpoints: %s", err))
@@ -45,11 +72,25 @@ func (api *coreAPI) handleDependencyStatusGet(writer http.ResponseWriter, reques
 	var status string
 	key := runtime.KeyForStorable(dependency)
 
+	// resolve policy partially to see if dependency failed to resolve
+	resolver := resolve.NewPolicyResolver(policy, api.externalData, event.NewLog("api-dependency-status", true))
+	desiredState, err := resolver.ResolveAllDependenciesPartially()
+	if err != nil {
+		panic(fmt.Sprintf("Error while resolving policy: %s", err))
+	}
+	if dependencyErr, failed := desiredState.GetDependencyErrors()[key]; failed {
+		api.contentType.WriteOne(writer, request, &dependencyStatusWrapper{Data: "Failed", Error: dependencyErr})
+		return
+	}
+
 	foundRefs := false
-	for _, instance := range actualState.ComponentInstanceMap {
+	readiness := make(map[string]string)
+	for instanceKey, instance := range actualState.ComponentInstanceMap {
 		if _, ok := instance.DependencyKeys[key]; ok {
 			foundRefs = true
-			break
+			if instance.Metadata.Key.IsComponent() {
+				readiness[instanceKey] = instance.Readiness.String()
+			}
 		}
 	}
 	if foundRefs {
@@ -58,5 +99,22 @@ func (api *coreAPI) handleDependencyStatusGet(writer http.ResponseWriter, reques
 		status = "Not Deployed"
 	}
 
-	api.contentType.WriteOne(writer, request, &dependencyStatusWrapper{Data: status})
+	api.contentType.WriteOne(writer, request, &dependencyStatusWrapper{Data: status, Readiness: readiness})
+}
+
+func (api *coreAPI) handleDependencyExplain(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	policy, dependency := api.getDependency(request, params)
+	if dependency == nil {
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
+	}
+
+	// rerun resolution for the dependency and return its trace
+	resolver := resolve.NewPolicyResolver(policy, api.externalData, event.NewLog("api-dependency-explain", true))
+	explanation, err := resolver.ExplainDependency(dependency)
+	if err != nil {
+		panic(fmt.Sprintf("Error while explaining dependency: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, explanation)
 }
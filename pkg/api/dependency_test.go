@@ -0,0 +1,58 @@
+package api
+
+import (
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type policyStoreMock struct {
+	store.Core
+	policy *lang.Policy
+}
+
+func (s *policyStoreMock) GetPolicy(gen runtime.Generation) (*lang.Policy, runtime.Generation, error) {
+	return s.policy, 1, nil
+}
+
+func TestDependencyExplainRequiresUser(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+	service := b.AddService()
+	b.AddServiceComponent(service, b.CodeComponent(nil, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+	user := b.AddUser()
+	dependency := b.AddDependency(user, contract)
+
+	api := &coreAPI{
+		contentType:  codec.NewContentTypeHandler(runtime.NewRegistry().Append(Objects...)),
+		store:        &policyStoreMock{policy: b.Policy()},
+		externalData: b.External(),
+	}
+	params := httprouter.Params{{Key: "ns", Value: dependency.Namespace}, {Key: "name", Value: dependency.Name}}
+
+	// anonymous user shouldn't be able to explain dependency
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/policy/dependency/explain", nil)
+	assert.Panics(t, func() {
+		api.handleDependencyExplain(httptest.NewRecorder(), request, params)
+	}, "Dependency should not be explained to anonymous user")
+
+	// user who can view dependency should get its explanation
+	request = request.WithContext(ContextWithUsername(request.Context(), user.Name))
+	request.Header.Set("Accept", codec.Default)
+	recorder := httptest.NewRecorder()
+	api.handleDependencyExplain(recorder, request, params)
+	assert.Equal(t, http.StatusOK, recorder.Code, "Dependency should be explained to user who can view it")
+
+	obj, err := api.contentType.GetCodecByContentType(codec.Default).DecodeOne(recorder.Body.Bytes())
+	if assert.NoError(t, err, "Explanation should be decoded") {
+		assert.IsType(t, &resolve.DependencyExplanation{}, obj, "Dependency explanation should be returned")
+	}
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+func (api *coreAPI) handleDriftGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	report, err := api.store.GetDriftReport()
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting drift report: %s", err))
+	}
+
+	if report == nil {
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+	} else {
+		api.contentType.WriteOne(writer, request, report)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
@@ -18,6 +19,10 @@ var EndpointsObject = &runtime.Info{
 type Endpoints struct {
 	runtime.TypeKind `yaml:",inline"`
 	List             map[string]map[string]string
+
+	// Failures contain failure state of the component instances, which failed to get applied and haven't been
+	// successfully applied since then
+	Failures map[string]*resolve.ComponentInstanceFailure `yaml:",omitempty"`
 }
 
 func (api *coreAPI) handleEndpointsGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
@@ -26,35 +31,44 @@ func (api *coreAPI) handleEndpointsGet(writer http.ResponseWriter, request *http
 	filterEnabled := len(dependencyNamespace) > 0 && len(dependencyName) > 0
 
 	endpoints := make(map[string]map[string]string)
+	failures := make(map[string]*resolve.ComponentInstanceFailure)
 	actualState, err := api.store.GetActualState()
 	if err != nil {
 		panic(fmt.Sprintf("Can't load actual state to get endpoints: %s", err))
 	}
 	for _, instance := range actualState.ComponentInstanceMap {
-		if len(instance.Endpoints) > 0 {
-			// filter by dependency, if/as needed
-			add := true
-			if filterEnabled {
-				add = false
-				// ideally we want to retrieve the corresponding dependency from policy, but for now let's just do
-				// string-based checks (because key for storable objects is namespace/kind/name)
-				for key := range instance.DependencyKeys {
-					if strings.HasPrefix(key, dependencyNamespace) && strings.HasSuffix(key, dependencyName) {
-						add = true
-						break
-					}
+		if len(instance.Endpoints) == 0 && instance.Failure == nil {
+			continue
+		}
+
+		// filter by dependency, if/as needed
+		add := true
+		if filterEnabled {
+			add = false
+			// ideally we want to retrieve the corresponding dependency from policy, but for now let's just do
+			// string-based checks (because key for storable objects is namespace/kind/name)
+			for key := range instance.DependencyKeys {
+				if strings.HasPrefix(key, dependencyNamespace) && strings.HasSuffix(key, dependencyName) {
+					add = true
+					break
 				}
 			}
+		}
 
-			// collect endpoints
-			if add {
+		// collect endpoints and failures
+		if add {
+			if len(instance.Endpoints) > 0 {
 				endpoints[instance.GetName()] = instance.Endpoints
 			}
+			if instance.Failure != nil {
+				failures[instance.GetName()] = instance.Failure
+			}
 		}
 	}
 
 	api.contentType.WriteOne(writer, request, &Endpoints{
 		TypeKind: EndpointsObject.GetTypeKind(),
 		List:     endpoints,
+		Failures: failures,
 	})
 }
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+func (api *coreAPI) handleGarbageCollectionGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	collection, err := api.store.GetGarbageCollection()
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting garbage collection: %s", err))
+	}
+
+	if collection == nil {
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+	} else {
+		api.contentType.WriteOne(writer, request, collection)
+	}
+}
+
+func (api *coreAPI) handleGarbageCollectionPlan(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+	if !user.DomainAdmin {
+		panic(fmt.Sprintf("User '%s' is not a domain admin and can't collect garbage", user.Name))
+	}
+
+	collection, err := api.store.RequestGarbageCollectionPlan(user.Name)
+	if err != nil {
+		panic(fmt.Sprintf("Error while planning garbage collection: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, collection)
+}
+
+func (api *coreAPI) handleGarbageCollectionRun(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+	if !user.DomainAdmin {
+		panic(fmt.Sprintf("User '%s' is not a domain admin and can't collect garbage", user.Name))
+	}
+
+	// only the plan reviewed by user can be confirmed, so orphans found by a newer plan don't get deleted without review
+	plannedAt, err := time.Parse(time.RFC3339Nano, request.URL.Query().Get("plannedAt"))
+	if err != nil {
+		panic(fmt.Sprintf("Garbage collection plan to run should be specified: %s", err))
+	}
+
+	collection, err := api.store.RequestGarbageCollectionRun(user.Name, plannedAt)
+	if err != nil {
+		panic(fmt.Sprintf("Error while running garbage collection: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, collection)
+}
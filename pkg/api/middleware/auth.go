@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/Sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+type authHandler struct {
+	handler     http.Handler
+	contentType *codec.ContentTypeHandler
+	secret      string
+}
+
+// NewAuthHandler returns HTTP handler that verifies auth token provided in the Authorization header and passes name
+// of the authenticated user to the wrapped handler through the request context. Requests without token are passed
+// as anonymous, while requests with invalid or expired token are rejected.
+func NewAuthHandler(handler http.Handler, secret string) http.Handler {
+	contentTypeHandler := codec.NewContentTypeHandler(runtime.NewRegistry().Append(api.Objects...))
+	return &authHandler{handler, contentTypeHandler, secret}
+}
+
+func (h *authHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	authHeader := request.Header.Get("Authorization")
+	if len(authHeader) == 0 {
+		h.handler.ServeHTTP(writer, request)
+		return
+	}
+
+	if !strings.HasPrefix(authHeader, bearerPrefix) {
+		h.unauthorized(writer, request, fmt.Errorf("unsupported authorization type"))
+		return
+	}
+
+	claims, err := api.ParseToken(h.secret, strings.TrimPrefix(authHeader, bearerPrefix))
+	if err != nil {
+		h.unauthorized(writer, request, err)
+		return
+	}
+
+	h.handler.ServeHTTP(writer, request.WithContext(api.ContextWithUsername(request.Context(), claims.Name)))
+}
+
+func (h *authHandler) unauthorized(writer http.ResponseWriter, request *http.Request, err error) {
+	log.WithField("request", request).Debugf("Rejecting request with invalid auth token: %s", err)
+
+	serverErr := api.NewServerError(fmt.Sprintf("Invalid auth token: %s", err))
+	h.contentType.WriteOneWithStatus(writer, request, serverErr, http.StatusUnauthorized)
+}
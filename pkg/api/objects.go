@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/version"
@@ -10,9 +11,14 @@ import (
 var (
 	// Objects is a list of all objects used in API
 	Objects = runtime.AppendAll([]*runtime.Info{
+		AuthSuccessObject,
+		resolve.DependencyExplanationObject,
 		EndpointsObject,
+		PolicyDiffObject,
+		PolicyPlanResultObject,
 		PolicyUpdateResultObject,
 		ServerErrorObject,
+		ServerStatusObject,
 		version.BuildInfoObject,
 	}, lang.PolicyObjects, engine.Objects)
 )
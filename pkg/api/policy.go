@@ -7,6 +7,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/engine/diff"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
@@ -179,8 +180,21 @@ func (api *coreAPI) handlePolicyUpdate(writer http.ResponseWriter, request *http
 
 	user := api.getUserRequired(request)
 
-	// Verify ACL for updated objects
-	currentPolicy, _, err := api.store.GetPolicy(runtime.LastGen)
+	// Verify ACL for updated objects and make sure updated policy is valid
+	api.getUpdatedPolicy(objects, user)
+
+	changed, policyData, err := api.store.UpdatePolicy(objects, user.Name)
+	if err != nil {
+		panic(fmt.Sprintf("Error while updating policy: %s", err))
+	}
+
+	api.getPolicyUpdateResult(writer, request, changed, policyData)
+}
+
+// getUpdatedPolicy loads the latest policy, adds updated objects to it (verifying that user is allowed to manage
+// them) and validates the resulting policy. Store is not modified
+func (api *coreAPI) getUpdatedPolicy(objects []lang.Base, user *lang.User) (*lang.Policy, runtime.Generation) {
+	currentPolicy, currentPolicyGen, err := api.store.GetPolicy(runtime.LastGen)
 	if err != nil {
 		panic(fmt.Sprintf("Error while loading current policy: %s", err))
 	}
@@ -200,12 +214,7 @@ func (api *coreAPI) handlePolicyUpdate(writer http.ResponseWriter, request *http
 		panic(fmt.Sprintf("Updated policy is invalid: %s", err))
 	}
 
-	changed, policyData, err := api.store.UpdatePolicy(objects, user.Name)
-	if err != nil {
-		panic(fmt.Sprintf("Error while updating policy: %s", err))
-	}
-
-	api.getPolicyUpdateResult(writer, request, changed, policyData)
+	return currentPolicy, currentPolicyGen
 }
 
 func (api *coreAPI) handlePolicyDelete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
@@ -249,14 +258,61 @@ func (api *coreAPI) handlePolicyDelete(writer http.ResponseWriter, request *http
 	api.getPolicyUpdateResult(writer, request, changed, policyData)
 }
 
-func (api *coreAPI) getPolicyUpdateResult(writer http.ResponseWriter, request *http.Request, changed bool, policyData *engine.PolicyData) {
-	desiredPolicyGen := policyData.GetGeneration()
-	desiredPolicy, _, err := api.store.GetPolicy(desiredPolicyGen)
+func (api *coreAPI) getPolicyWithData(gen runtime.Generation) (*engine.PolicyData, *lang.Policy) {
+	policyData, err := api.store.GetPolicyData(gen)
 	if err != nil {
-		panic(fmt.Sprintf("Error while getting desiredPolicy: %s", err))
+		panic(fmt.Sprintf("Error while loading policy #%d: %s", gen, err))
 	}
-	if desiredPolicy == nil {
-		panic(fmt.Sprintf("Can't read policy right after updating it"))
+	if policyData == nil {
+		panic(fmt.Sprintf("Policy #%d not found", gen))
+	}
+
+	policy, _, err := api.store.GetPolicy(gen)
+	if err != nil {
+		panic(fmt.Sprintf("Error while loading policy #%d: %s", gen, err))
+	}
+
+	return policyData, policy
+}
+
+func getPolicyObject(policy *lang.Policy, ns string, kind string, name string) runtime.Object {
+	obj, err := policy.GetObject(kind, name, ns)
+	if err != nil || obj == nil {
+		panic(fmt.Sprintf("Error while getting object %s/%s/%s: %v", ns, kind, name, err))
+	}
+	return obj
+}
+
+func (api *coreAPI) handlePolicyRollback(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	gen := runtime.ParseGeneration(params.ByName("gen"))
+
+	user := api.getUserRequired(request)
+
+	currentPolicyData, currentPolicy := api.getPolicyWithData(runtime.LastGen)
+	targetPolicyData, targetPolicy := api.getPolicyWithData(gen)
+
+	// Verify ACL for objects which are going to be added, changed or removed by the rollback
+	verifyManage := func(policy *lang.Policy, ns string, kind string, name string) {
+		obj := getPolicyObject(policy, ns, kind, name)
+		errManage := currentPolicy.View(user).ManageObject(obj.(lang.Base))
+		if errManage != nil {
+			panic(fmt.Sprintf("Error while rolling back policy: %s", errManage))
+		}
+	}
+	targetPolicyData.ForEach(func(ns string, kind string, name string, targetGen runtime.Generation) {
+		if currentGen, exist := currentPolicyData.GetObjectGeneration(ns, kind, name); !exist || currentGen != targetGen {
+			verifyManage(targetPolicy, ns, kind, name)
+		}
+	})
+	currentPolicyData.ForEach(func(ns string, kind string, name string, currentGen runtime.Generation) {
+		if _, exist := targetPolicyData.GetObjectGeneration(ns, kind, name); !exist {
+			verifyManage(currentPolicy, ns, kind, name)
+		}
+	})
+
+	err := targetPolicy.Validate()
+	if err != nil {
+		panic(fmt.Sprintf("Policy #%d is invalid: %s", gen, err))
 	}
 
 	actualState, err := api.store.GetActualState()
@@ -264,18 +320,31 @@ func (api *coreAPI) getPolicyUpdateResult(writer http.ResponseWriter, request *h
 		panic(fmt.Sprintf("Error while getting actual state: %s", err))
 	}
 
-	// todo we should resolve before saving policy => add Mutex for this method to make sure it's safe
-	// todo: add request id to the event log scope
-	eventLog := event.NewLog("api-policy-update", true)
-	resolver := resolve.NewPolicyResolver(desiredPolicy, api.externalData, eventLog)
-	desiredState, err := resolver.ResolveAllDependencies()
+	err = actualState.Validate(targetPolicy)
+	if err != nil {
+		panic(fmt.Sprintf("Policy #%d is invalid: %s", gen, err))
+	}
 
+	changed, policyData, err := api.store.RollbackPolicy(gen, user.Name)
 	if err != nil {
-		// todo - when printing maps with large # of entries, the errors are pretty long and hard to understand. should not write maps here
-		panic(fmt.Sprintf("Cannot resolve desiredPolicy: %v %v %v", err, desiredState, actualState))
+		panic(fmt.Sprintf("Error while rolling back policy: %s", err))
 	}
 
-	stateDiff := diff.NewPolicyResolutionDiff(desiredState, actualState)
+	api.getPolicyUpdateResult(writer, request, changed, policyData)
+}
+
+func (api *coreAPI) getPolicyUpdateResult(writer http.ResponseWriter, request *http.Request, changed bool, policyData *engine.PolicyData) {
+	desiredPolicyGen := policyData.GetGeneration()
+	desiredPolicy, _, err := api.store.GetPolicy(desiredPolicyGen)
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting desiredPolicy: %s", err))
+	}
+	if desiredPolicy == nil {
+		panic(fmt.Sprintf("Can't read policy right after updating it"))
+	}
+
+	// todo we should resolve before saving policy => add Mutex for this method to make sure it's safe
+	stateDiff := api.getPolicyDiff(desiredPolicy, "api-policy-update")
 
 	actions := make([]string, len(stateDiff.Actions))
 	for idx, action := range stateDiff.Actions {
@@ -289,3 +358,24 @@ func (api *coreAPI) getPolicyUpdateResult(writer http.ResponseWriter, request *h
 		Actions:          actions,
 	})
 }
+
+// getPolicyDiff resolves provided policy and calculates the list of actions needed to get from the current actual
+// state to the resolved desired state
+func (api *coreAPI) getPolicyDiff(desiredPolicy *lang.Policy, eventLogScope string) *diff.PolicyResolutionDiff {
+	actualState, err := api.store.GetActualState()
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting actual state: %s", err))
+	}
+
+	// todo: add request id to the event log scope
+	eventLog := event.NewLog(eventLogScope, true)
+	resolver := resolve.NewPolicyResolver(desiredPolicy, api.externalData, eventLog)
+	desiredState, err := resolver.ResolveAllDependencies()
+
+	if err != nil {
+		// todo - when printing maps with large # of entries, the errors are pretty long and hard to understand. should not write maps here
+		panic(fmt.Sprintf("Cannot resolve desiredPolicy: %v %v %v", err, desiredState, actualState))
+	}
+
+	return diff.NewPolicyResolutionDiffWithPolicy(desiredPolicy, desiredState, actualState, nil)
+}
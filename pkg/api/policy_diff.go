@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang/yaml"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// PolicyDiffObject is an informational data structure with Kind and Constructor for PolicyDiff
+var PolicyDiffObject = &runtime.Info{
+	Kind:        "policy-diff",
+	Constructor: func() runtime.Object { return &PolicyDiff{} },
+}
+
+// PolicyDiff represents object-level difference between two policy generations
+type PolicyDiff struct {
+	runtime.TypeKind `yaml:",inline"`
+	Gen              runtime.Generation
+	GenBase          runtime.Generation
+
+	// Added, Removed and Changed contain keys of policy objects (namespace/kind/name)
+	Added   []string
+	Removed []string
+	Changed []*PolicyObjectDiff
+}
+
+// PolicyObjectDiff represents field-level difference for a single policy object
+type PolicyObjectDiff struct {
+	Key    string
+	Fields []*yaml.FieldDiff
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (diff *PolicyDiff) GetDefaultColumns() []string {
+	return []string{"Policy Changes", "Object Changes"}
+}
+
+// AsColumns returns PolicyDiff representation as columns
+func (diff *PolicyDiff) AsColumns() map[string]string {
+	changes := []string{}
+	for _, key := range diff.Added {
+		changes = append(changes, "[+] "+key)
+	}
+	for _, key := range diff.Removed {
+		changes = append(changes, "[-] "+key)
+	}
+	for _, objDiff := range diff.Changed {
+		changes = append(changes, "[*] "+objDiff.Key)
+		for _, fieldDiff := range objDiff.Fields {
+			changes = append(changes, "\t "+fieldDiff.String())
+		}
+	}
+
+	changesStr := "(none)"
+	if len(changes) > 0 {
+		changesStr = strings.Join(changes, "\n")
+	}
+
+	return map[string]string{
+		"Policy Changes": fmt.Sprintf("Gen %d -> %d", diff.GenBase, diff.Gen),
+		"Object Changes": changesStr,
+	}
+}
+
+func (api *coreAPI) handlePolicyDiff(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	gen := runtime.ParseGeneration(params.ByName("gen"))
+	genBase := runtime.ParseGeneration(params.ByName("genBase"))
+
+	policyData, policy := api.getPolicyWithData(gen)
+	policyDataBase, policyBase := api.getPolicyWithData(genBase)
+
+	diff := &PolicyDiff{
+		TypeKind: PolicyDiffObject.GetTypeKind(),
+		Gen:      policyData.GetGeneration(),
+		GenBase:  policyDataBase.GetGeneration(),
+		Added:    []string{},
+		Removed:  []string{},
+		Changed:  []*PolicyObjectDiff{},
+	}
+
+	policyData.ForEach(func(ns string, kind string, name string, objGen runtime.Generation) {
+		key := runtime.KeyFromParts(ns, kind, name)
+		objGenBase, exist := policyDataBase.GetObjectGeneration(ns, kind, name)
+		if !exist {
+			diff.Added = append(diff.Added, key)
+		} else if objGen != objGenBase {
+			fields, err := yaml.DiffObjects(getPolicyObject(policyBase, ns, kind, name), getPolicyObject(policy, ns, kind, name))
+			if err != nil {
+				panic(fmt.Sprintf("Error while comparing object %s: %s", key, err))
+			}
+			diff.Changed = append(diff.Changed, &PolicyObjectDiff{Key: key, Fields: withoutGenerationField(fields)})
+		}
+	})
+	policyDataBase.ForEach(func(ns string, kind string, name string, objGen runtime.Generation) {
+		if _, exist := policyData.GetObjectGeneration(ns, kind, name); !exist {
+			diff.Removed = append(diff.Removed, runtime.KeyFromParts(ns, kind, name))
+		}
+	})
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Key < diff.Changed[j].Key
+	})
+
+	api.contentType.WriteOne(writer, request, diff)
+}
+
+// withoutGenerationField removes generation of the object from the diff, as it's always different for changed objects
+func withoutGenerationField(fields []*yaml.FieldDiff) []*yaml.FieldDiff {
+	result := []*yaml.FieldDiff{}
+	for _, field := range fields {
+		if field.Path != "metadata.generation" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
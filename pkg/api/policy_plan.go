@@ -0,0 +1,135 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strings"
+)
+
+// PolicyPlanResultObject is an informational data structure with Kind and Constructor for PolicyPlanResult
+var PolicyPlanResultObject = &runtime.Info{
+	Kind:        "policy-plan-result",
+	Constructor: func() runtime.Object { return &PolicyPlanResult{} },
+}
+
+// PolicyPlanResult represents results for the policy plan request (list of actions, which will be executed if the
+// proposed policy change gets applied)
+type PolicyPlanResult struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// PolicyGeneration is a generation of the current policy, which proposed changes were applied to
+	PolicyGeneration runtime.Generation
+
+	// Components is a list of component instances affected by the proposed change, in the order of actions
+	Components []*PlannedComponent
+}
+
+// PlannedComponent represents actions planned for a single component instance
+type PlannedComponent struct {
+	ComponentKey string
+	Actions      []*PlannedAction
+}
+
+// PlannedAction represents a single planned action
+type PlannedAction struct {
+	// Kind is a short action kind (create, update, delete, attach, detach, endpoints)
+	Kind string
+
+	// DependencyID is an ID of the dependency being attached or detached
+	DependencyID string `yaml:",omitempty"`
+}
+
+// plannedActionKinds maps action kinds to the short ones shown in plans
+var plannedActionKinds = map[string]string{
+	component.CreateActionObject.Kind:           "create",
+	component.UpdateActionObject.Kind:           "update",
+	component.DeleteActionObject.Kind:           "delete",
+	component.AttachDependencyActionObject.Kind: "attach",
+	component.DetachDependencyActionObject.Kind: "detach",
+	component.EndpointsActionObject.Kind:        "endpoints",
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (result *PolicyPlanResult) GetDefaultColumns() []string {
+	return []string{"Policy", "Planned Changes"}
+}
+
+// AsColumns returns PolicyPlanResult representation as columns
+func (result *PolicyPlanResult) AsColumns() map[string]string {
+	changes := make([]string, 0, len(result.Components))
+	for _, plannedComponent := range result.Components {
+		actions := make([]string, 0, len(plannedComponent.Actions))
+		for _, plannedAction := range plannedComponent.Actions {
+			if len(plannedAction.DependencyID) > 0 {
+				actions = append(actions, plannedAction.Kind+" "+plannedAction.DependencyID)
+			} else {
+				actions = append(actions, plannedAction.Kind)
+			}
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s", plannedComponent.ComponentKey, strings.Join(actions, ", ")))
+	}
+
+	changesStr := "(none)"
+	if len(changes) > 0 {
+		changesStr = strings.Join(changes, "\n")
+	}
+
+	return map[string]string{
+		"Policy":          fmt.Sprintf("Gen %d + proposed changes", result.PolicyGeneration),
+		"Planned Changes": changesStr,
+	}
+}
+
+// newPolicyPlanResult groups provided actions by component instance. Actions not bound to a particular component
+// instance are omitted, as they are executed on every policy change
+func newPolicyPlanResult(policyGen runtime.Generation, actions []action.Base) *PolicyPlanResult {
+	result := &PolicyPlanResult{
+		TypeKind:         PolicyPlanResultObject.GetTypeKind(),
+		PolicyGeneration: policyGen,
+		Components:       []*PlannedComponent{},
+	}
+
+	componentMap := make(map[string]*PlannedComponent)
+	for _, act := range actions {
+		componentAction, ok := act.(action.ComponentAction)
+		if !ok {
+			continue
+		}
+
+		key := componentAction.GetComponentKey()
+		plannedComponent, exist := componentMap[key]
+		if !exist {
+			plannedComponent = &PlannedComponent{ComponentKey: key}
+			componentMap[key] = plannedComponent
+			result.Components = append(result.Components, plannedComponent)
+		}
+
+		plannedAction := &PlannedAction{Kind: plannedActionKinds[act.GetKind()]}
+		switch a := act.(type) {
+		case *component.AttachDependencyAction:
+			plannedAction.DependencyID = a.DependencyID
+		case *component.DetachDependencyAction:
+			plannedAction.DependencyID = a.DependencyID
+		}
+		plannedComponent.Actions = append(plannedComponent.Actions, plannedAction)
+	}
+
+	return result
+}
+
+func (api *coreAPI) handlePolicyPlan(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	objects := api.readLang(request)
+
+	user := api.getUserRequired(request)
+
+	// Apply proposed changes to the current policy in memory, without saving anything into the store
+	proposedPolicy, currentPolicyGen := api.getUpdatedPolicy(objects, user)
+
+	stateDiff := api.getPolicyDiff(proposedPolicy, "api-policy-plan")
+
+	api.contentType.WriteOne(writer, request, newPolicyPlanResult(currentPolicyGen, stateDiff.Actions))
+}
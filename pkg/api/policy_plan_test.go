@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/global"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPolicyPlanResult(t *testing.T) {
+	actions := []action.Base{
+		component.NewCreateAction("a"),
+		component.NewAttachDependencyAction("a", "dep1"),
+		component.NewDeleteAction("b"),
+		component.NewEndpointsAction("a"),
+		component.NewDetachDependencyAction("c", "dep2"),
+		global.NewPostProcessAction(),
+	}
+
+	result := newPolicyPlanResult(5, actions)
+	assert.Equal(t, 3, len(result.Components), "Actions should be grouped by component instance")
+
+	assert.Equal(t, "a", result.Components[0].ComponentKey, "Components should be in the order of actions")
+	assert.Equal(t, []*PlannedAction{{Kind: "create"}, {Kind: "attach", DependencyID: "dep1"}, {Kind: "endpoints"}}, result.Components[0].Actions, "Component actions should be listed in order")
+	assert.Equal(t, []*PlannedAction{{Kind: "delete"}}, result.Components[1].Actions, "Component actions should be listed in order")
+	assert.Equal(t, []*PlannedAction{{Kind: "detach", DependencyID: "dep2"}}, result.Components[2].Actions, "Component actions should be listed in order")
+
+	columns := result.AsColumns()
+	assert.Equal(t, "a: create, attach dep1, endpoints\nb: delete\nc: detach dep2", columns["Planned Changes"], "Planned changes should be rendered per component")
+
+	empty := newPolicyPlanResult(5, []action.Base{})
+	assert.Equal(t, "(none)", empty.AsColumns()["Planned Changes"], "Empty plan should be rendered as (none)")
+}
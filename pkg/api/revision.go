@@ -2,6 +2,9 @@ package api
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
@@ -27,6 +30,86 @@ func (api *coreAPI) handleRevisionGet(writer http.ResponseWriter, request *http.
 	}
 }
 
+func (api *coreAPI) handleRevisionApprove(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	gen := runtime.ParseGeneration(params.ByName("gen"))
+
+	user := api.getUserRequired(request)
+	api.verifyRevisionDecision(user, gen)
+
+	revision, err := api.store.ApproveRevision(gen, user.Name)
+	if err != nil {
+		panic(fmt.Sprintf("Error while approving revision %d: %s", gen, err))
+	}
+
+	api.contentType.WriteOne(writer, request, revision)
+}
+
+func (api *coreAPI) handleRevisionReject(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	gen := runtime.ParseGeneration(params.ByName("gen"))
+
+	user := api.getUserRequired(request)
+	api.verifyRevisionDecision(user, gen)
+
+	revision, err := api.store.RejectRevision(gen, user.Name)
+	if err != nil {
+		panic(fmt.Sprintf("Error while rejecting revision %d: %s", gen, err))
+	}
+
+	api.contentType.WriteOne(writer, request, revision)
+}
+
+// verifyRevisionDecision verifies that user is allowed to approve or reject the revision. Only domain admins are
+// allowed to override safety limits of blocked revisions, while revisions pending approval could be approved by users
+// who are able to manage services in all namespaces requiring approval
+func (api *coreAPI) verifyRevisionDecision(user *lang.User, gen runtime.Generation) {
+	revision, err := api.store.GetRevision(gen)
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting revision %d: %s", gen, err))
+	}
+	if revision == nil {
+		panic(fmt.Sprintf("Revision %d not found", gen))
+	}
+
+	if revision.Status == engine.RevisionStatusBlocked {
+		if !user.DomainAdmin {
+			panic(fmt.Sprintf("User '%s' is not a domain admin and can't make decision on blocked revision %d", user.Name, revision.GetGeneration()))
+		}
+		return
+	}
+
+	policy, _, err := api.store.GetPolicy(runtime.LastGen)
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting policy: %s", err))
+	}
+	for _, namespace := range revision.ApprovalNamespaces {
+		if _, errApprove := policy.View(user).CanApprove(namespace); errApprove != nil {
+			panic(fmt.Sprintf("Error while making decision on revision %d: %s", revision.GetGeneration(), errApprove))
+		}
+	}
+}
+
+func (api *coreAPI) handleRevisionLogGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	gen := runtime.ParseGeneration(params.ByName("gen"))
+
+	revisionLog, err := api.store.GetRevisionLog(gen)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting requested revision log: %s", err))
+	}
+
+	if revisionLog == nil {
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
+	}
+
+	// return only entries with requested level or more severe
+	revisionLog.Entries, err = event.FilterLogEntries(revisionLog.Entries, request.URL.Query().Get("level"))
+	if err != nil {
+		panic(fmt.Sprintf("error while filtering revision log: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, revisionLog)
+}
+
 func (api *coreAPI) handleRevisionGetByPolicy(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	policyGen := params.ByName("policy")
 
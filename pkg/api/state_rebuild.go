@@ -0,0 +1,34 @@
+package api
+
+import (
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+func (api *coreAPI) handleStateRebuildGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	rebuild, err := api.store.GetStateRebuild()
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting state rebuild: %s", err))
+	}
+
+	if rebuild == nil {
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+	} else {
+		api.contentType.WriteOne(writer, request, rebuild)
+	}
+}
+
+func (api *coreAPI) handleStateRebuildRequest(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+	if !user.DomainAdmin {
+		panic(fmt.Sprintf("User '%s' is not a domain admin and can't rebuild actual state", user.Name))
+	}
+
+	rebuild, err := api.store.RequestStateRebuild(user.Name, false)
+	if err != nil {
+		panic(fmt.Sprintf("Error while requesting state rebuild: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, rebuild)
+}
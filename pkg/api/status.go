@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ServerStatusObject is an informational data structure with Kind and Constructor for ServerStatus
+var ServerStatusObject = &runtime.Info{
+	Kind:        "server-status",
+	Constructor: func() runtime.Object { return &ServerStatus{} },
+}
+
+// ServerStatus represents status of the Aptomi server replicas sharing the same store
+type ServerStatus struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Leader is an ID of the server replica, which currently holds the leader lease and enforces policy. It's empty
+	// if there is no leader at the moment (e.g. leader lease expired and hasn't been taken over yet)
+	Leader string
+
+	// LeaderSince is when the current leader acquired the leader lease
+	LeaderSince time.Time `yaml:",omitempty"`
+
+	// LeaderLeaseExpiresAt is when the leader lease expires, unless renewed by the current leader
+	LeaderLeaseExpiresAt time.Time `yaml:",omitempty"`
+
+	// Failures contain failure state of the component instances (by key), which failed to get applied and haven't
+	// been successfully applied since then
+	Failures map[string]*resolve.ComponentInstanceFailure `yaml:",omitempty"`
+
+	// Deferred contains component instances (by key), which changes got deferred by freeze windows in the latest
+	// revision, along with the explanation why
+	Deferred map[string]string `yaml:",omitempty"`
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (status *ServerStatus) GetDefaultColumns() []string {
+	return []string{"Leader", "Leader Since", "Lease Expires At", "Failed Components", "Deferred Components"}
+}
+
+// AsColumns returns ServerStatus representation as columns
+func (status *ServerStatus) AsColumns() map[string]string {
+	failed := []string{}
+	for key, failure := range status.Failures {
+		retry := "stuck"
+		if !failure.IsStuck() {
+			retry = fmt.Sprintf("retry at %s", failure.NextRetryAt)
+		}
+		failed = append(failed, fmt.Sprintf("%s (attempts: %d, %s): %s", key, failure.Attempts, retry, failure.LastError))
+	}
+	sort.Strings(failed)
+
+	deferred := []string{}
+	for key, reason := range status.Deferred {
+		deferred = append(deferred, fmt.Sprintf("%s: %s", key, reason))
+	}
+	sort.Strings(deferred)
+
+	if len(status.Leader) == 0 {
+		return map[string]string{
+			"Leader":              "(none)",
+			"Failed Components":   strings.Join(failed, "\n"),
+			"Deferred Components": strings.Join(deferred, "\n"),
+		}
+	}
+
+	return map[string]string{
+		"Leader":              status.Leader,
+		"Leader Since":        status.LeaderSince.String(),
+		"Lease Expires At":    status.LeaderLeaseExpiresAt.String(),
+		"Failed Components":   strings.Join(failed, "\n"),
+		"Deferred Components": strings.Join(deferred, "\n"),
+	}
+}
+
+func (api *coreAPI) handleStatus(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	lease, err := api.store.GetLease(engine.LeaderLeaseName)
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting leader lease: %s", err))
+	}
+
+	status := &ServerStatus{TypeKind: ServerStatusObject.GetTypeKind()}
+	if lease != nil && !lease.IsExpired(time.Now()) {
+		status.Leader = lease.Holder
+		status.LeaderSince = lease.AcquiredAt
+		status.LeaderLeaseExpiresAt = lease.ExpiresAt
+	}
+
+	actualState, err := api.store.GetActualState()
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting actual state: %s", err))
+	}
+	for key, instance := range actualState.ComponentInstanceMap {
+		if instance.Failure != nil {
+			if status.Failures == nil {
+				status.Failures = make(map[string]*resolve.ComponentInstanceFailure)
+			}
+			status.Failures[key] = instance.Failure
+		}
+	}
+
+	revision, err := api.store.GetRevision(runtime.LastGen)
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting revision: %s", err))
+	}
+	if revision != nil {
+		status.Deferred = revision.Deferred
+	}
+
+	api.contentType.WriteOne(writer, request, status)
+}
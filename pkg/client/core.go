@@ -3,23 +3,35 @@ package client
 import (
 	"github.com/Aptomi/aptomi/pkg/api"
 	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/version"
+	"time"
 )
 
 // Core is the Core API client interface
 type Core interface {
 	Policy() Policy
+	Dependency() Dependency
 	Endpoints() Endpoints
 	Revision() Revision
+	GC() GC
 	Version() Version
 }
 
 // Policy is the interface for managing Policy
 type Policy interface {
 	Show(gen runtime.Generation) (*engine.PolicyData, error)
+	Diff(gen runtime.Generation, genBase runtime.Generation) (*api.PolicyDiff, error)
 	Apply([]runtime.Object) (*api.PolicyUpdateResult, error)
+	Plan([]runtime.Object) (*api.PolicyPlanResult, error)
 	Delete([]runtime.Object) (*api.PolicyUpdateResult, error)
+	Rollback(gen runtime.Generation) (*api.PolicyUpdateResult, error)
+}
+
+// Dependency is the interface for getting info about dependencies
+type Dependency interface {
+	Explain(ns string, name string) (*resolve.DependencyExplanation, error)
 }
 
 // Endpoints is the interface for getting info about endpoints
@@ -31,6 +43,16 @@ type Endpoints interface {
 type Revision interface {
 	Show(gen runtime.Generation) (*engine.Revision, error)
 	ShowByPolicy(policyGen runtime.Generation) (*engine.Revision, error)
+	Log(gen runtime.Generation, level string) (*engine.RevisionLog, error)
+	Approve(gen runtime.Generation) (*engine.Revision, error)
+	Reject(gen runtime.Generation) (*engine.Revision, error)
+}
+
+// GC is the interface for garbage collection of orphaned deployments
+type GC interface {
+	Show() (*engine.GarbageCollection, error)
+	Plan() (*engine.GarbageCollection, error)
+	Run(plannedAt time.Time) (*engine.GarbageCollection, error)
 }
 
 // Version is the interface for getting current server version
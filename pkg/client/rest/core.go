@@ -20,6 +20,10 @@ func (client *coreClient) Policy() client.Policy {
 	return &policyClient{client.cfg, client.httpClient}
 }
 
+func (client *coreClient) Dependency() client.Dependency {
+	return &dependencyClient{client.cfg, client.httpClient}
+}
+
 func (client *coreClient) Endpoints() client.Endpoints {
 	return &endpointsClient{client.cfg, client.httpClient}
 }
@@ -28,6 +32,10 @@ func (client *coreClient) Revision() client.Revision {
 	return &revisionClient{client.cfg, client.httpClient}
 }
 
+func (client *coreClient) GC() client.GC {
+	return &gcClient{client.cfg, client.httpClient}
+}
+
 func (client *coreClient) Version() client.Version {
 	return &versionClient{client.cfg, client.httpClient}
 }
@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+)
+
+type dependencyClient struct {
+	cfg        *config.Client
+	httpClient http.Client
+}
+
+func (client *dependencyClient) Explain(ns string, name string) (*resolve.DependencyExplanation, error) {
+	response, err := client.httpClient.GET(fmt.Sprintf("/policy/dependency/%s/%s/explain", ns, name), resolve.DependencyExplanationObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*resolve.DependencyExplanation), nil
+}
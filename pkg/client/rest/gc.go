@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"net/url"
+	"time"
+)
+
+type gcClient struct {
+	cfg        *config.Client
+	httpClient http.Client
+}
+
+func (client *gcClient) Show() (*engine.GarbageCollection, error) {
+	response, err := client.httpClient.GET("/gc", engine.GarbageCollectionObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*engine.GarbageCollection), nil
+}
+
+func (client *gcClient) Plan() (*engine.GarbageCollection, error) {
+	response, err := client.httpClient.POST("/gc/plan", engine.GarbageCollectionObject, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*engine.GarbageCollection), nil
+}
+
+func (client *gcClient) Run(plannedAt time.Time) (*engine.GarbageCollection, error) {
+	path := "/gc/run?plannedAt=" + url.QueryEscape(plannedAt.Format(time.RFC3339Nano))
+	response, err := client.httpClient.POST(path, engine.GarbageCollectionObject, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*engine.GarbageCollection), nil
+}
@@ -10,6 +10,9 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 )
 
 // Client is the interface for doing HTTP requests that operates using runtime objects
@@ -24,6 +27,8 @@ type httpClient struct {
 	contentType *codec.ContentTypeHandler
 	http        *http.Client
 	cfg         *config.Client
+	tokenLock   sync.Mutex
+	token       string
 }
 
 // NewClient returns implementation of
@@ -33,7 +38,7 @@ func NewClient(cfg *config.Client) Client {
 	}
 	contentTypeHandler := codec.NewContentTypeHandler(runtime.NewRegistry().Append(api.Objects...))
 
-	return &httpClient{contentTypeHandler, client, cfg}
+	return &httpClient{contentType: contentTypeHandler, http: client, cfg: cfg}
 }
 
 func (client *httpClient) GET(path string, expected *runtime.Info) (runtime.Object, error) {
@@ -88,10 +93,72 @@ func (client *httpClient) request(method string, path string, expected *runtime.
 		return nil, err
 	}
 
-	req.Header.Set("Username", client.cfg.Auth.Username)
+	token, err := client.getToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 	req.Header.Set("Content-Type", codec.Default)
+	req.Header.Set("Accept", codec.Default)
+	req.Header.Set("User-Agent", "aptomictl")
+
+	return client.do(req, expected)
+}
+
+// getToken returns auth token from the config or obtains it from the server using username and password (only once).
+// If neither token nor password is provided, request will be sent without authentication.
+func (client *httpClient) getToken() (string, error) {
+	if len(client.cfg.Auth.Token) > 0 {
+		return client.cfg.Auth.Token, nil
+	}
+
+	if len(client.cfg.Auth.Password) == 0 {
+		return "", nil
+	}
+
+	client.tokenLock.Lock()
+	defer client.tokenLock.Unlock()
+
+	if len(client.token) > 0 {
+		return client.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("username", client.cfg.Auth.Username)
+	form.Set("password", client.cfg.Auth.Password)
+
+	req, err := http.NewRequest(http.MethodPost, client.cfg.API.URL()+"/user/authenticate", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", codec.Default)
 	req.Header.Set("User-Agent", "aptomictl")
 
+	obj, err := client.do(req, nil)
+	if err != nil {
+		return "", fmt.Errorf("error while authenticating user %s: %s", client.cfg.Auth.Username, err)
+	}
+
+	if serverErr, ok := obj.(*api.ServerError); ok {
+		return "", fmt.Errorf("error while authenticating user %s: %s", client.cfg.Auth.Username, serverErr.Error)
+	}
+
+	authSuccess, ok := obj.(*api.AuthSuccess)
+	if !ok {
+		return "", fmt.Errorf("received object kind %s doesn't match expected %s", obj.GetKind(), api.AuthSuccessObject.Kind)
+	}
+
+	client.token = authSuccess.Token
+
+	return client.token, nil
+}
+
+func (client *httpClient) do(req *http.Request, expected *runtime.Info) (runtime.Object, error) {
 	resp, err := client.http.Do(req)
 	if err != nil {
 		return nil, err
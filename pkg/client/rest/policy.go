@@ -27,6 +27,19 @@ func (client *policyClient) Show(gen runtime.Generation) (*engine.PolicyData, er
 	return response.(*engine.PolicyData), nil
 }
 
+func (client *policyClient) Diff(gen runtime.Generation, genBase runtime.Generation) (*api.PolicyDiff, error) {
+	response, err := client.httpClient.GET(fmt.Sprintf("/policy/diff/gen/%d/genBase/%d", gen, genBase), api.PolicyDiffObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.PolicyDiff), nil
+}
+
 func (client *policyClient) Apply(updated []runtime.Object) (*api.PolicyUpdateResult, error) {
 	response, err := client.httpClient.POSTSlice("/policy", api.PolicyUpdateResultObject, updated)
 	if err != nil {
@@ -40,6 +53,19 @@ func (client *policyClient) Apply(updated []runtime.Object) (*api.PolicyUpdateRe
 	return response.(*api.PolicyUpdateResult), nil
 }
 
+func (client *policyClient) Plan(updated []runtime.Object) (*api.PolicyPlanResult, error) {
+	response, err := client.httpClient.POSTSlice("/policy/plan", api.PolicyPlanResultObject, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.PolicyPlanResult), nil
+}
+
 func (client *policyClient) Delete(updated []runtime.Object) (*api.PolicyUpdateResult, error) {
 	response, err := client.httpClient.DELETESlice("/policy", api.PolicyUpdateResultObject, updated)
 	if err != nil {
@@ -52,3 +78,16 @@ func (client *policyClient) Delete(updated []runtime.Object) (*api.PolicyUpdateR
 
 	return response.(*api.PolicyUpdateResult), nil
 }
+
+func (client *policyClient) Rollback(gen runtime.Generation) (*api.PolicyUpdateResult, error) {
+	response, err := client.httpClient.POST(fmt.Sprintf("/policy/rollback/gen/%d", gen), api.PolicyUpdateResultObject, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.PolicyUpdateResult), nil
+}
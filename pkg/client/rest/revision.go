@@ -6,6 +6,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/client/rest/http"
 	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"net/url"
 
 	"github.com/Aptomi/aptomi/pkg/config"
 )
@@ -40,3 +41,47 @@ func (client *revisionClient) ShowByPolicy(policyGen runtime.Generation) (*engin
 
 	return response.(*engine.Revision), nil
 }
+
+func (client *revisionClient) Log(gen runtime.Generation, level string) (*engine.RevisionLog, error) {
+	path := fmt.Sprintf("/revision/gen/%d/log", gen)
+	if len(level) > 0 {
+		path += "?level=" + url.QueryEscape(level)
+	}
+
+	response, err := client.httpClient.GET(path, engine.RevisionLogObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*engine.RevisionLog), nil
+}
+
+func (client *revisionClient) Approve(gen runtime.Generation) (*engine.Revision, error) {
+	response, err := client.httpClient.POST(fmt.Sprintf("/revision/gen/%d/approve", gen), engine.RevisionObject, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*engine.Revision), nil
+}
+
+func (client *revisionClient) Reject(gen runtime.Generation) (*engine.Revision, error) {
+	response, err := client.httpClient.POST(fmt.Sprintf("/revision/gen/%d/reject", gen), engine.RevisionObject, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*engine.Revision), nil
+}
@@ -23,7 +23,10 @@ func (c Client) IsDebug() bool {
 	return c.Debug
 }
 
-// Auth represents client auth configs
+// Auth represents client auth configs. If token isn't provided, client will authenticate using username and password
+// to obtain it.
 type Auth struct {
 	Username string `validate:"required"`
+	Password string `validate:"-"`
+	Token    string `validate:"-"`
 }
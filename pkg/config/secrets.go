@@ -0,0 +1,53 @@
+package config
+
+import "time"
+
+// Secrets represents configs for the secret loader. Only one source of secrets (directory, Vault or encrypted file)
+// could be specified
+type Secrets struct {
+	Dir           string         `validate:"omitempty,dir"`
+	Vault         *Vault         `validate:"omitempty"`
+	EncryptedFile *EncryptedFile `validate:"omitempty"`
+
+	// RefreshInterval is how often secrets are reloaded from the source, so rotated secrets are picked up
+	RefreshInterval time.Duration `validate:"-"`
+}
+
+// Vault represents configs for loading secrets from Vault-compatible KV store over HTTP
+type Vault struct {
+	Address string `validate:"required,url"`
+	Token   string `validate:"required"`
+
+	// Mount is a path where KV secrets engine is mounted (e.g. secret)
+	Mount string `validate:"required"`
+
+	// Path is a path within KV secrets engine, under which secrets of each user are stored as a separate secret named
+	// after the user (e.g. aptomi)
+	Path string `validate:"required"`
+
+	// KVVersion is a version of Vault KV secrets engine (1 or 2)
+	KVVersion int `validate:"omitempty,min=1,max=2"`
+}
+
+// EncryptedFile represents configs for loading secrets from a YAML file encrypted with AES-GCM
+type EncryptedFile struct {
+	File string `validate:"required,file"`
+
+	// Key is a base64-encoded AES key (16, 24 or 32 bytes long)
+	Key string `validate:"required"`
+}
+
+// SourceCount returns number of secret sources specified in the config
+func (s Secrets) SourceCount() int {
+	count := 0
+	if len(s.Dir) > 0 {
+		count++
+	}
+	if s.Vault != nil {
+		count++
+	}
+	if s.EncryptedFile != nil {
+		count++
+	}
+	return count
+}
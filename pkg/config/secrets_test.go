@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfigSecrets(t *testing.T) {
+	config := Secrets{}
+	assert.Equal(t, 0, config.SourceCount(), "There should be no secret sources in default config")
+
+	config = Secrets{
+		Dir:   "/tmp",
+		Vault: &Vault{Address: "http://127.0.0.1:8200", Token: "token", Mount: "secret", Path: "aptomi"},
+	}
+	assert.Equal(t, 2, config.SourceCount(), "All specified secret sources should be counted")
+}
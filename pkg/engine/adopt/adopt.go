@@ -0,0 +1,217 @@
+package adopt
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/actual"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// Adopt looks up component instances from the desired state, which are missing in the actual state, in the cloud.
+// Code component instances found in the cloud get adopted, i.e. copied into the actual state along with their
+// creation times and endpoints, so they don't get created again by the next enforcement. Only deploy plugins
+// implementing plugin.LiveStatePlugin are able to look up component instances, component instances deployed by
+// other plugins aren't adopted. Component instances, which plugin doesn't report as existing (e.g. deleted or failed
+// Helm releases), aren't adopted either, so they get created again by the next enforcement. If adopted component
+// instance differs from the desired state, it gets marked as drifted, so it gets updated by the next enforcement.
+//
+// Service and contract component instances, which don't have anything running in the cloud, get adopted once all
+// code component instances they point to are adopted.
+//
+// It returns keys of adopted component instances in the processing order.
+func Adopt(ctx context.Context, desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution, updater actual.StateUpdater, plugins plugin.Registry, eventLog *event.Log) ([]string, error) {
+	adopter := &adopter{
+		ctx:          ctx,
+		desiredState: desiredState,
+		actualState:  actualState,
+		plugins:      plugins,
+		eventLog:     eventLog,
+		isCode:       make(map[string]bool),
+		found:        make(map[string]bool),
+		adopted:      make(map[string]bool),
+	}
+
+	order := desiredState.GetComponentProcessingOrder()
+
+	// look up code component instances in the cloud first
+	for _, key := range order {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("adoption got interrupted: %s", ctx.Err())
+		}
+
+		instance := desiredState.ComponentInstanceMap[key]
+		found, err := adopter.lookup(desiredPolicy, instance)
+		if err != nil {
+			return nil, fmt.Errorf("error while looking up component instance '%s': %s", key, err)
+		}
+		if found {
+			adopter.found[key] = true
+		}
+	}
+
+	// then save everything what got adopted into the actual state
+	result := []string{}
+	for _, key := range order {
+		_, exists := actualState.ComponentInstanceMap[key]
+		if exists || len(desiredState.ComponentInstanceMap[key].DependencyKeys) <= 0 || !adopter.isAdopted(key) {
+			continue
+		}
+
+		instance := desiredState.ComponentInstanceMap[key]
+		actualState.ComponentInstanceMap[key] = instance
+		err := updater.Save(instance)
+		if err != nil {
+			return nil, fmt.Errorf("error while saving adopted component instance '%s': %s", key, err)
+		}
+
+		eventLog.WithFields(event.Fields{
+			"componentKey": key,
+		}).Infof("Component instance adopted: %s", key)
+		result = append(result, key)
+	}
+
+	return result, nil
+}
+
+type adopter struct {
+	ctx          context.Context
+	desiredState *resolve.PolicyResolution
+	actualState  *resolve.PolicyResolution
+	plugins      plugin.Registry
+	eventLog     *event.Log
+
+	// isCode contains keys of code component instances
+	isCode map[string]bool
+
+	// found contains keys of code component instances, which have been found in the cloud
+	found map[string]bool
+
+	// adopted contains results of isAdopted for service and contract component instances
+	adopted map[string]bool
+}
+
+// lookup looks up the given desired component instance in the cloud and fills in its creation times and endpoints.
+// It returns true if it's a code component instance missing in the actual state, which has been found in the cloud
+func (adopter *adopter) lookup(desiredPolicy *lang.Policy, instance *resolve.ComponentInstance) (bool, error) {
+	if len(instance.DependencyKeys) <= 0 || !instance.Metadata.Key.IsComponent() {
+		return false, nil
+	}
+
+	serviceObj, err := desiredPolicy.GetObject(lang.ServiceObject.Kind, instance.Metadata.Key.ServiceName, instance.Metadata.Key.Namespace)
+	if err != nil {
+		return false, err
+	}
+	component := serviceObj.(*lang.Service).GetComponentsMap()[instance.Metadata.Key.ComponentName]
+	if component == nil || component.Code == nil {
+		return false, nil
+	}
+	adopter.isCode[instance.GetKey()] = true
+
+	// component instance is already in the actual state, nothing to adopt
+	if _, exists := adopter.actualState.ComponentInstanceMap[instance.GetKey()]; exists {
+		return false, nil
+	}
+
+	deployPlugin, err := adopter.plugins.GetDeployPlugin(component.Code.Type)
+	if err != nil {
+		return false, err
+	}
+	liveStatePlugin, ok := deployPlugin.(plugin.LiveStatePlugin)
+	if !ok {
+		adopter.eventLog.WithFields(event.Fields{
+			"componentKey": instance.GetKey(),
+		}).Warningf("Component instance can't be looked up in the cloud, as plugin for code type '%s' doesn't support it: %s", component.Code.Type, instance.GetKey())
+		return false, nil
+	}
+
+	clusterName, ok := instance.CalculatedCodeParams[lang.LabelCluster].(string)
+	if !ok {
+		return false, fmt.Errorf("no cluster specified in code params")
+	}
+	clusterObj, err := desiredPolicy.GetObject(lang.ClusterObject.Kind, clusterName, runtime.SystemNS)
+	if err != nil {
+		return false, err
+	}
+	if clusterObj == nil {
+		return false, fmt.Errorf("can't find cluster in policy: %s", clusterName)
+	}
+	cluster := clusterObj.(*lang.Cluster)
+
+	live, err := liveStatePlugin.LiveState(adopter.ctx, cluster, instance.GetDeployName(), adopter.eventLog)
+	if err != nil {
+		return false, err
+	}
+	if !live.Exists {
+		return false, nil
+	}
+
+	// component instance running in the cloud may differ from the desired state, so it should be updated
+	expected, err := liveStatePlugin.ExpectedLiveState(instance.CalculatedCodeParams)
+	if err != nil {
+		return false, err
+	}
+	instance.Drift = live.Drift(expected)
+
+	endpoints, err := deployPlugin.Endpoints(adopter.ctx, cluster, instance.GetDeployName(), instance.CalculatedCodeParams, adopter.eventLog)
+	if err != nil {
+		return false, err
+	}
+	instance.Endpoints = endpoints
+
+	now := time.Now()
+	createdAt, updatedAt := live.CreatedAt, live.UpdatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+	if updatedAt.IsZero() {
+		updatedAt = now
+	}
+	instance.UpdateTimes(createdAt, updatedAt)
+
+	return true, nil
+}
+
+// isAdopted returns true if the given component instance gets adopted. Code component instances get adopted if they
+// have been found in the cloud, while all other component instances get adopted once everything they point to is
+// adopted or already exists in the actual state
+func (adopter *adopter) isAdopted(key string) bool {
+	if _, exists := adopter.actualState.ComponentInstanceMap[key]; exists || adopter.found[key] {
+		return true
+	}
+	if result, ok := adopter.adopted[key]; ok {
+		return result
+	}
+
+	// code component instances, which haven't been found, as well as cycles in the graph aren't adopted
+	adopter.adopted[key] = false
+	instance := adopter.desiredState.ComponentInstanceMap[key]
+	if instance == nil || adopter.isCode[key] {
+		return false
+	}
+	for keyOut := range instance.EdgesOut {
+		if !adopter.isAdopted(keyOut) {
+			return false
+		}
+	}
+
+	// times of service and contract component instances follow the ones of component instances they point to
+	for keyOut := range instance.EdgesOut {
+		instanceOut, exists := adopter.actualState.ComponentInstanceMap[keyOut]
+		if !exists {
+			instanceOut = adopter.desiredState.ComponentInstanceMap[keyOut]
+		}
+		instance.UpdateTimes(instanceOut.CreatedAt, instanceOut.UpdatedAt)
+	}
+	if instance.CreatedAt.IsZero() {
+		now := time.Now()
+		instance.UpdateTimes(now, now)
+	}
+
+	adopter.adopted[key] = true
+	return true
+}
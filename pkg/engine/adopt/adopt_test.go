@@ -0,0 +1,116 @@
+package adopt
+
+import (
+	"context"
+	"github.com/Aptomi/aptomi/pkg/engine/actual"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAdopt(t *testing.T) {
+	b, web, db := makePolicyBuilder()
+	desiredState := resolvePolicy(t, b)
+	actualState := resolve.NewPolicyResolution(false)
+	webKey, dbKey, serviceKey := "", "", ""
+	for key, instance := range desiredState.ComponentInstanceMap {
+		switch {
+		case instance.Metadata.Key.IsService():
+			serviceKey = key
+		case instance.Metadata.Key.ComponentName == web.Name:
+			webKey = key
+		case instance.Metadata.Key.ComponentName == db.Name:
+			dbKey = key
+		}
+	}
+
+	// only web is running in the cloud, so service instance can't be adopted until db gets created
+	createdAt := time.Now().Add(-time.Hour).Round(time.Second)
+	livePlugin := plugin.NewMockLiveStatePlugin()
+	webLive, _ := livePlugin.ExpectedLiveState(desiredState.ComponentInstanceMap[webKey].CalculatedCodeParams)
+	webLive.CreatedAt = createdAt
+	webLive.UpdatedAt = createdAt
+	livePlugin.Live[desiredState.ComponentInstanceMap[webKey].GetDeployName()] = webLive
+
+	// db has been deployed in the cloud before, but got deleted since then (e.g. Helm release with deleted status)
+	livePlugin.Live[desiredState.ComponentInstanceMap[dbKey].GetDeployName()] = &plugin.LiveState{Exists: false, Version: "2.0"}
+	registry := &plugin.MockRegistry{DeployPlugin: livePlugin, PostProcessPlugin: &plugin.MockPostProcessPlugin{}}
+
+	adopted, err := Adopt(context.Background(), b.Policy(), desiredState, actualState, actual.NewNoOpActionStateUpdater(), registry, event.NewLog("test-adopt", false))
+	assert.NoError(t, err, "Adoption should succeed")
+	assert.Equal(t, []string{webKey}, adopted, "Only web should be adopted")
+	assert.NotContains(t, actualState.ComponentInstanceMap, dbKey, "Deleted db should not be adopted")
+	if assert.Contains(t, actualState.ComponentInstanceMap, webKey, "Web should be added to actual state") {
+		instance := actualState.ComponentInstanceMap[webKey]
+		assert.Equal(t, createdAt, instance.CreatedAt, "Creation time should be taken from the cloud")
+		assert.Equal(t, map[string]string{"url": instance.GetDeployName()}, instance.Endpoints, "Endpoints should be taken from the cloud")
+		assert.Empty(t, instance.Drift, "Web should not drift")
+	}
+
+	// db is running in the cloud as well, but with a different version
+	desiredState = resolvePolicy(t, b)
+	dbLive, _ := livePlugin.ExpectedLiveState(desiredState.ComponentInstanceMap[dbKey].CalculatedCodeParams)
+	dbLive.Version = "1.5"
+	livePlugin.Live[desiredState.ComponentInstanceMap[dbKey].GetDeployName()] = dbLive
+
+	adopted, err = Adopt(context.Background(), b.Policy(), desiredState, actualState, actual.NewNoOpActionStateUpdater(), registry, event.NewLog("test-adopt", false))
+	assert.NoError(t, err, "Adoption should succeed")
+	assert.ElementsMatch(t, []string{dbKey, serviceKey}, adopted, "Db and service instance should be adopted")
+	assert.Equal(t, len(desiredState.ComponentInstanceMap), len(actualState.ComponentInstanceMap), "All component instances should be in actual state")
+	assert.Contains(t, actualState.ComponentInstanceMap[dbKey].Drift, "version", "Db should drift, so it gets updated")
+	assert.False(t, actualState.ComponentInstanceMap[serviceKey].CreatedAt.IsZero(), "Service instance should get creation time")
+
+	// everything is in actual state already, nothing to adopt
+	adopted, err = Adopt(context.Background(), b.Policy(), resolvePolicy(t, b), actualState, actual.NewNoOpActionStateUpdater(), registry, event.NewLog("test-adopt", false))
+	assert.NoError(t, err, "Adoption should succeed")
+	assert.Empty(t, adopted, "Nothing should be adopted")
+}
+
+func TestAdoptNotSupported(t *testing.T) {
+	b, _, _ := makePolicyBuilder()
+
+	// deploy plugins, which can't look up component instances in the cloud, don't adopt anything
+	actualState := resolve.NewPolicyResolution(false)
+	registry := &plugin.MockRegistry{DeployPlugin: &plugin.MockDeployPlugin{}, PostProcessPlugin: &plugin.MockPostProcessPlugin{}}
+	adopted, err := Adopt(context.Background(), b.Policy(), resolvePolicy(t, b), actualState, actual.NewNoOpActionStateUpdater(), registry, event.NewLog("test-adopt", false))
+	assert.NoError(t, err, "Adoption should succeed")
+	assert.Empty(t, adopted, "Nothing should be adopted")
+	assert.Empty(t, actualState.ComponentInstanceMap, "Actual state should stay empty")
+}
+
+/*
+	Helpers
+*/
+
+// makePolicyBuilder creates a policy with a service, which has "web" (version 1.0) and "db" (version 2.0) code
+// components, consumed by a single dependency
+func makePolicyBuilder() (*builder.PolicyBuilder, *lang.ServiceComponent, *lang.ServiceComponent) {
+	b := builder.NewPolicyBuilder()
+	service := b.AddService()
+	web := b.AddServiceComponent(service, b.CodeComponent(util.NestedParameterMap{lang.LabelCluster: "{{ .Labels.cluster }}", "version": "1.0"}, nil))
+	db := b.AddServiceComponent(service, b.CodeComponent(util.NestedParameterMap{lang.LabelCluster: "{{ .Labels.cluster }}", "version": "2.0"}, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+	clusterObj := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, clusterObj.Name)))
+	b.AddDependency(b.AddUser(), contract)
+	return b, web, db
+}
+
+func resolvePolicy(t *testing.T, b *builder.PolicyBuilder) *resolve.PolicyResolution {
+	t.Helper()
+	eventLog := event.NewLog("test-resolve", false)
+	resolver := resolve.NewPolicyResolver(b.Policy(), b.External(), eventLog)
+	result, err := resolver.ResolveAllDependencies()
+	if !assert.NoError(t, err, "Policy should be resolved without errors") {
+		hook := &event.HookConsole{}
+		eventLog.Save(hook)
+		t.FailNow()
+	}
+	return result
+}
@@ -0,0 +1,4 @@
+// Package adopt allows Aptomi to rebuild actual state from what is really running in the cloud, e.g. after the store
+// got lost or actual state got reset. Component instances found in the cloud get adopted into the actual state
+// instead of being created again.
+package adopt
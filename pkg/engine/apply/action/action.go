@@ -9,3 +9,10 @@ type Base interface {
 	runtime.Storable
 	Apply(*Context) error
 }
+
+// ComponentAction is an interface for actions which are performed on a particular component instance. Actions, which
+// don't implement it (e.g. global post-processing), are executed by the engine only after all component actions
+type ComponentAction interface {
+	Base
+	GetComponentKey() string
+}
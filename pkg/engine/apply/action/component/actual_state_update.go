@@ -7,7 +7,17 @@ import (
 	"time"
 )
 
-func updateActualStateFromDesired(componentKey string, context *action.Context, createNow bool, updateNow bool, createIfNotExists bool) error {
+func getComponentFromActualState(componentKey string, context *action.Context) *resolve.ComponentInstance {
+	context.ActualStateLock.Lock()
+	defer context.ActualStateLock.Unlock()
+
+	return context.ActualState.ComponentInstanceMap[componentKey]
+}
+
+func updateActualStateFromDesired(componentKey string, context *action.Context, createNow bool, updateNow bool, createIfNotExists bool, readiness *resolve.ComponentInstanceReadiness) error {
+	context.ActualStateLock.Lock()
+	defer context.ActualStateLock.Unlock()
+
 	// get instance from actual state
 	instanceActual := context.ActualState.ComponentInstanceMap[componentKey]
 
@@ -34,8 +44,12 @@ func updateActualStateFromDesired(componentKey string, context *action.Context,
 		panic(fmt.Sprintf("component instance not found in desired state: %s", componentKey))
 	}
 
-	// modify create/update times, copy it over to the actual state
+	// modify create/update times and readiness, copy it over to the actual state
 	instance.UpdateTimes(timeCreated, timeUpdated)
+	if readiness == nil && instanceActual != nil {
+		readiness = instanceActual.Readiness
+	}
+	instance.Readiness = readiness
 	context.ActualState.ComponentInstanceMap[componentKey] = instance
 
 	// save actual state
@@ -47,6 +61,9 @@ func updateActualStateFromDesired(componentKey string, context *action.Context,
 }
 
 func updateComponentInActualState(componentKey string, context *action.Context) error {
+	context.ActualStateLock.Lock()
+	defer context.ActualStateLock.Unlock()
+
 	instance := context.ActualState.ComponentInstanceMap[componentKey]
 	err := context.ActualStateUpdater.Save(instance)
 	if err != nil {
@@ -56,6 +73,9 @@ func updateComponentInActualState(componentKey string, context *action.Context)
 }
 
 func deleteComponentFromActualState(componentKey string, context *action.Context) error {
+	context.ActualStateLock.Lock()
+	defer context.ActualStateLock.Unlock()
+
 	// delete component from the actual state
 	delete(context.ActualState.ComponentInstanceMap, componentKey)
 	err := context.ActualStateUpdater.Delete(resolve.KeyForComponentKey(componentKey))
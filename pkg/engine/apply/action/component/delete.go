@@ -30,9 +30,15 @@ func NewDeleteAction(componentKey string) *DeleteAction {
 	}
 }
 
+// GetComponentKey returns key of the component instance this action is performed on
+func (a *DeleteAction) GetComponentKey() string {
+	return a.ComponentKey
+}
+
 // Apply applies the action
 func (a *DeleteAction) Apply(context *action.Context) error {
-	// delete from cloud
+	// delete from cloud (even if component instance failed to be created, as it could be created partially). Plugins
+	// treat deleting something what doesn't exist in the cloud as success
 	err := a.processDeployment(context)
 	if err != nil {
 		return fmt.Errorf("error while deleting component '%s': %s", a.ComponentKey, err)
@@ -43,7 +49,7 @@ func (a *DeleteAction) Apply(context *action.Context) error {
 }
 
 func (a *DeleteAction) processDeployment(context *action.Context) error {
-	instance := context.ActualState.ComponentInstanceMap[a.ComponentKey]
+	instance := getComponentFromActualState(a.ComponentKey, context)
 	serviceObj, err := context.DesiredPolicy.GetObject(lang.ServiceObject.Kind, instance.Metadata.Key.ServiceName, instance.Metadata.Key.Namespace)
 	if err != nil {
 		return err
@@ -62,7 +68,7 @@ func (a *DeleteAction) processDeployment(context *action.Context) error {
 	context.EventLog.WithFields(event.Fields{
 		"componentKey": instance.Metadata.Key,
 		"component":    component.Name,
-		"code":         instance.CalculatedCodeParams,
+		"code":         instance.GetCodeParamsMasked(),
 	}).Info("Destructing a running component instance: " + instance.GetKey())
 
 	clusterName, ok := instance.CalculatedCodeParams[lang.LabelCluster].(string)
@@ -83,5 +89,5 @@ func (a *DeleteAction) processDeployment(context *action.Context) error {
 		return err
 	}
 
-	return plugin.Destroy(clusterObj.(*lang.Cluster), instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+	return plugin.Destroy(context.Ctx, clusterObj.(*lang.Cluster), instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
 }
@@ -29,7 +29,12 @@ func NewAttachDependencyAction(componentKey string, dependencyID string) *Attach
 	}
 }
 
+// GetComponentKey returns key of the component instance this action is performed on
+func (a *AttachDependencyAction) GetComponentKey() string {
+	return a.ComponentKey
+}
+
 // Apply applies the action
 func (a *AttachDependencyAction) Apply(context *action.Context) error {
-	return updateActualStateFromDesired(a.ComponentKey, context, false, false, false)
+	return updateActualStateFromDesired(a.ComponentKey, context, false, false, false, nil)
 }
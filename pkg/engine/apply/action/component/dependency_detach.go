@@ -22,13 +22,19 @@ type DetachDependencyAction struct {
 // NewDetachDependencyAction creates new DetachDependencyAction
 func NewDetachDependencyAction(componentKey string, dependencyID string) *DetachDependencyAction {
 	return &DetachDependencyAction{
+		TypeKind:     DetachDependencyActionObject.GetTypeKind(),
 		Metadata:     action.NewMetadata(DetachDependencyActionObject.Kind, componentKey, dependencyID),
 		ComponentKey: componentKey,
 		DependencyID: dependencyID,
 	}
 }
 
+// GetComponentKey returns key of the component instance this action is performed on
+func (a *DetachDependencyAction) GetComponentKey() string {
+	return a.ComponentKey
+}
+
 // Apply applies the action
 func (a *DetachDependencyAction) Apply(context *action.Context) error {
-	return updateActualStateFromDesired(a.ComponentKey, context, false, false, false)
+	return updateActualStateFromDesired(a.ComponentKey, context, false, false, false, nil)
 }
@@ -30,11 +30,16 @@ func NewEndpointsAction(componentKey string) *EndpointsAction {
 	}
 }
 
+// GetComponentKey returns key of the component instance this action is performed on
+func (a *EndpointsAction) GetComponentKey() string {
+	return a.ComponentKey
+}
+
 // Apply applies the action
 func (a *EndpointsAction) Apply(context *action.Context) error {
 	// skip component for some reason doesn't exist in actual state
 	// this might happen if, for example, it the corresponding component got destroyed by a prior delete action
-	if context.ActualState.ComponentInstanceMap[a.ComponentKey] == nil {
+	if getComponentFromActualState(a.ComponentKey, context) == nil {
 		return nil
 	}
 
@@ -48,7 +53,7 @@ func (a *EndpointsAction) Apply(context *action.Context) error {
 }
 
 func (a *EndpointsAction) processEndpoints(context *action.Context) error {
-	instance := context.ActualState.ComponentInstanceMap[a.ComponentKey]
+	instance := getComponentFromActualState(a.ComponentKey, context)
 	serviceObj, err := context.DesiredPolicy.GetObject(lang.ServiceObject.Kind, instance.Metadata.Key.ServiceName, instance.Metadata.Key.Namespace)
 	if err != nil {
 		return err
@@ -68,7 +73,7 @@ func (a *EndpointsAction) processEndpoints(context *action.Context) error {
 	context.EventLog.WithFields(event.Fields{
 		"componentKey": instance.Metadata.Key,
 		"component":    component.Name,
-		"code":         instance.CalculatedCodeParams,
+		"code":         instance.GetCodeParamsMasked(),
 	}).Info("Getting endpoints for component instance: " + instance.GetKey())
 
 	clusterName, ok := instance.CalculatedCodeParams[lang.LabelCluster].(string)
@@ -89,7 +94,7 @@ func (a *EndpointsAction) processEndpoints(context *action.Context) error {
 		return err
 	}
 
-	endpoints, err := plugin.Endpoints(clusterObj.(*lang.Cluster), instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+	endpoints, err := plugin.Endpoints(context.Ctx, clusterObj.(*lang.Cluster), instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,61 @@
+package component
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"time"
+)
+
+// checkReadiness checks readiness of the component instance, which has just been created or updated in the cloud, if
+// deploy plugin supports readiness checks. If waiting for readiness is enabled, it keeps checking until component
+// instance becomes ready, timeout expires or action context is done, so that actions on dependent component instances
+// will not be started before that. It returns readiness to be recorded into the actual state or nil if deploy plugin
+// doesn't support readiness checks. Given component instance is shared with other actions, so it's not modified here
+func checkReadiness(instance *resolve.ComponentInstance, code *lang.Code, cluster *lang.Cluster, deployPlugin plugin.DeployPlugin, context *action.Context) *resolve.ComponentInstanceReadiness {
+	readinessPlugin, ok := deployPlugin.(plugin.ReadinessPlugin)
+	if !ok {
+		return nil
+	}
+
+	timeout := context.Readiness.Timeout
+	if code.ReadyTimeout > 0 {
+		timeout = code.ReadyTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	readiness := &resolve.ComponentInstanceReadiness{}
+	for {
+		ready, err := readinessPlugin.Status(context.Ctx, cluster, instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+		if err != nil {
+			context.EventLog.WithFields(event.Fields{
+				"componentKey": instance.Metadata.Key,
+			}).Warningf("Error while checking readiness of component instance '%s': %s", instance.GetKey(), err)
+		}
+		readiness.Ready = ready && err == nil
+		readiness.CheckedAt = time.Now()
+
+		if readiness.Ready || !context.Readiness.Wait {
+			break
+		}
+		if !readiness.CheckedAt.Before(deadline) {
+			readiness.TimedOut = true
+			context.EventLog.WithFields(event.Fields{
+				"componentKey": instance.Metadata.Key,
+				"timeout":      timeout,
+			}).Warningf("Component instance '%s' didn't become ready within %s, its dependents will be processed anyway", instance.GetKey(), timeout)
+			break
+		}
+
+		// stop waiting once action context is done
+		select {
+		case <-time.After(context.Readiness.CheckInterval):
+		case <-context.Ctx.Done():
+			return readiness
+		}
+	}
+
+	return readiness
+}
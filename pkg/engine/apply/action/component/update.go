@@ -3,6 +3,7 @@ package component
 import (
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
@@ -30,58 +31,68 @@ func NewUpdateAction(componentKey string) *UpdateAction {
 	}
 }
 
+// GetComponentKey returns key of the component instance this action is performed on
+func (a *UpdateAction) GetComponentKey() string {
+	return a.ComponentKey
+}
+
 // Apply applies the action
 func (a *UpdateAction) Apply(context *action.Context) error {
 	// update in the cloud
-	err := a.processDeployment(context)
+	readiness, err := a.processDeployment(context)
 	if err != nil {
 		return fmt.Errorf("error while updating component '%s': %s", a.ComponentKey, err)
 	}
 
 	// update actual state
-	return updateActualStateFromDesired(a.ComponentKey, context, false, true, false)
+	return updateActualStateFromDesired(a.ComponentKey, context, false, true, false, readiness)
 }
 
-func (a *UpdateAction) processDeployment(context *action.Context) error {
+func (a *UpdateAction) processDeployment(context *action.Context) (*resolve.ComponentInstanceReadiness, error) {
 	instance := context.DesiredState.ComponentInstanceMap[a.ComponentKey]
 	serviceObj, err := context.DesiredPolicy.GetObject(lang.ServiceObject.Kind, instance.Metadata.Key.ServiceName, instance.Metadata.Key.Namespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	component := serviceObj.(*lang.Service).GetComponentsMap()[instance.Metadata.Key.ComponentName]
 
 	if component == nil {
 		// This is a service instance. Do nothing
-		return nil
+		return nil, nil
 	}
 
 	if component.Code == nil {
-		return nil
+		return nil, nil
 	}
 
 	context.EventLog.WithFields(event.Fields{
 		"componentKey": instance.Metadata.Key,
 		"component":    component.Name,
-		"code":         instance.CalculatedCodeParams,
+		"code":         instance.GetCodeParamsMasked(),
 	}).Info("Updating a running component instance: " + instance.GetKey())
 
 	clusterName, ok := instance.CalculatedCodeParams[lang.LabelCluster].(string)
 	if !ok {
-		return fmt.Errorf("no cluster specified in code params, component instance: %v", a.ComponentKey)
+		return nil, fmt.Errorf("no cluster specified in code params, component instance: %v", a.ComponentKey)
 	}
 
 	clusterObj, err := context.DesiredPolicy.GetObject(lang.ClusterObject.Kind, clusterName, runtime.SystemNS)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if clusterObj == nil {
-		return fmt.Errorf("can't find cluster in policy: %s", clusterName)
+		return nil, fmt.Errorf("can't find cluster in policy: %s", clusterName)
 	}
 
 	plugin, err := context.Plugins.GetDeployPlugin(component.Code.Type)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	err = plugin.Update(context.Ctx, clusterObj.(*lang.Cluster), instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+	if err != nil {
+		return nil, err
 	}
 
-	return plugin.Update(clusterObj.(*lang.Cluster), instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+	return checkReadiness(instance, component.Code, clusterObj.(*lang.Cluster), plugin, context), nil
 }
@@ -1,38 +1,76 @@
 package action
 
 import (
+	"context"
 	"github.com/Aptomi/aptomi/pkg/engine/actual"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/external"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/plugin"
+	"sync"
+	"time"
 )
 
 // Context is a data struct that will be passed into all state update actions, giving actions access to desired
 // policy/state, and actual state and a way to updatae it, list of plugins, event log, etc
 type Context struct {
+	Ctx                context.Context // actions should stop and return an error once it's done (e.g. timeout expired)
 	DesiredPolicy      *lang.Policy
 	DesiredState       *resolve.PolicyResolution
 	ActualState        *resolve.PolicyResolution
+	ActualStateLock    *sync.Mutex // actions may run concurrently, so all access to actual state should be synchronized
 	ActualStateUpdater actual.StateUpdater
 	ExternalData       *external.Data
 	Plugins            plugin.Registry
 	EventLog           *event.Log
+	Readiness          Readiness
+}
+
+// Readiness defines whether actions should wait for component instances to become ready after they get created or
+// updated, before actions on their dependents could be started
+type Readiness struct {
+	// Wait enables waiting for component instances to become ready. If it's not enabled, readiness is checked only
+	// once and recorded in the actual state
+	Wait bool
+
+	// Timeout is a default time to wait for component instance to become ready (it could be overridden for a
+	// particular component via lang.Code.ReadyTimeout). Once it expires, dependents are processed anyway
+	Timeout time.Duration
+
+	// CheckInterval is an interval between readiness checks
+	CheckInterval time.Duration
 }
 
 // NewContext creates a new instance of Context
-func NewContext(desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution,
+func NewContext(ctx context.Context, desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution,
 	actualState *resolve.PolicyResolution, actualStateUpdater actual.StateUpdater, externalData *external.Data,
-	plugins plugin.Registry, eventLog *event.Log) *Context {
+	plugins plugin.Registry, eventLog *event.Log, readiness Readiness) *Context {
 
 	return &Context{
+		Ctx:                ctx,
 		DesiredPolicy:      desiredPolicy,
 		DesiredState:       desiredState,
 		ActualState:        actualState,
+		ActualStateLock:    &sync.Mutex{},
 		ActualStateUpdater: actualStateUpdater,
 		ExternalData:       externalData,
 		Plugins:            plugins,
 		EventLog:           eventLog,
+		Readiness:          readiness,
+	}
+}
+
+// WithTimeout returns a copy of the context for running a single action, which shares actual state (along with its
+// lock) with the original one, but gets its Ctx done once the given timeout expires. Zero timeout means no timeout.
+// Returned cancel function should be called once action is completed
+func (actionContext *Context) WithTimeout(timeout time.Duration) (*Context, context.CancelFunc) {
+	result := *actionContext
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		result.Ctx, cancel = context.WithTimeout(actionContext.Ctx, timeout)
+	} else {
+		result.Ctx, cancel = context.WithCancel(actionContext.Ctx)
 	}
+	return &result, cancel
 }
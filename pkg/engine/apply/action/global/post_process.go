@@ -30,7 +30,7 @@ func NewPostProcessAction() *PostProcessAction {
 // Apply runs all registered post-processing plugins
 func (a *PostProcessAction) Apply(context *action.Context) error {
 	for _, plugin := range context.Plugins.GetPostProcessingPlugins() {
-		err := plugin.Process(context.DesiredPolicy, context.DesiredState, context.ExternalData, context.EventLog)
+		err := plugin.Process(context.Ctx, context.DesiredPolicy, context.DesiredState, context.ExternalData, context.EventLog)
 		if err != nil {
 			return fmt.Errorf("error while running post processing action: %s", err)
 		}
@@ -3,6 +3,7 @@ package apply
 import (
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/engine/actual"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
 	"github.com/Aptomi/aptomi/pkg/engine/diff"
 	"github.com/Aptomi/aptomi/pkg/engine/progress"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
@@ -412,6 +413,11 @@ func RunEngine(t *testing.T, testName string, desiredPolicy *lang.Policy, extern
 		actions,
 		event.NewLog("test-apply", false),
 		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
 	)
 
 	actualState = applyAndCheck(t, applier, ResSuccess, 0, "Successfully resolved")
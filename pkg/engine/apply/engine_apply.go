@@ -1,16 +1,21 @@
 package apply
 
 import (
+	"context"
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/engine/actual"
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
 	"github.com/Aptomi/aptomi/pkg/engine/progress"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/external"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/util/retry"
 	"runtime/debug"
+	"sort"
+	"time"
 )
 
 // EngineApply executes actions to get from an actual state to desired state
@@ -31,22 +36,47 @@ type EngineApply struct {
 
 	// Progress indicator
 	progress progress.Indicator
+
+	// Max number of actions executed concurrently
+	maxConcurrentActions int
+
+	// Backoff for retrying component instances, which failed to be created/updated/deleted
+	retryBackoff retry.Backoff
+
+	// Whether and how long to wait for component instances to become ready before processing their dependents
+	readiness action.Readiness
+
+	// Max time a single action could take (0 means no limit)
+	actionTimeout time.Duration
+
+	// Fence is checked before executing every action, once it returns error no more actions get executed (nil means
+	// no fencing)
+	fence func() error
 }
 
 // NewEngineApply creates an instance of EngineApply
 // todo(slukjanov): make sure that plugins are created once per revision, b/c we need to cache only for single policy, when it changed some credentials could change as well
 // todo(slukjanov): run cleanup on all plugins after apply done for the revision
-func NewEngineApply(desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution, actualStateUpdater actual.StateUpdater, externalData *external.Data, plugins plugin.Registry, actions []action.Base, eventLog *event.Log, progress progress.Indicator) *EngineApply {
+func NewEngineApply(desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution, actualStateUpdater actual.StateUpdater, externalData *external.Data, plugins plugin.Registry, actions []action.Base, eventLog *event.Log, progress progress.Indicator, maxConcurrentActions int, retryBackoff retry.Backoff, readiness action.Readiness, actionTimeout time.Duration, fence func() error) *EngineApply {
+	if maxConcurrentActions < 1 {
+		maxConcurrentActions = 1
+	}
+
 	return &EngineApply{
-		desiredPolicy:      desiredPolicy,
-		desiredState:       desiredState,
-		actualState:        actualState,
-		actualStateUpdater: actualStateUpdater,
-		externalData:       externalData,
-		plugins:            plugins,
-		actions:            actions,
-		eventLog:           eventLog,
-		progress:           progress,
+		desiredPolicy:        desiredPolicy,
+		desiredState:         desiredState,
+		actualState:          actualState,
+		actualStateUpdater:   actualStateUpdater,
+		externalData:         externalData,
+		plugins:              plugins,
+		actions:              actions,
+		eventLog:             eventLog,
+		progress:             progress,
+		maxConcurrentActions: maxConcurrentActions,
+		retryBackoff:         retryBackoff,
+		readiness:            readiness,
+		actionTimeout:        actionTimeout,
+		fence:                fence,
 	}
 }
 
@@ -55,7 +85,18 @@ func NewEngineApply(desiredPolicy *lang.Policy, desiredState *resolve.PolicyReso
 // As actions get executed, they will instantiate/update/delete components according to the resolved
 // policy, as well as configure the underlying cloud components appropriately. In case of errors (e.g. cloud is not
 // available), actual state may not be equal to desired state after performing all the actions.
-func (apply *EngineApply) Apply() (*resolve.PolicyResolution, error) {
+//
+// Actions are executed concurrently (up to maxConcurrentActions at a time), following the graph of component instance
+// dependencies. If an action fails, all actions depending on it will not be executed. Failures of component instances
+// are recorded in the actual state, so they will be retried with backoff (see resolve.ComponentInstanceFailure). If
+// waiting for readiness is enabled, actions on component instances start only once component instances they depend on
+// become ready (or readiness timeout expires).
+//
+// Every action gets cancelled if it takes longer than actionTimeout. Once the given context is done (e.g. revision
+// timeout expired or server is shutting down), actions in progress get cancelled and the remaining ones are skipped.
+// The same happens once fence check fails (e.g. server isn't the leader anymore), in such case progress indicator
+// isn't finalized, as changes shouldn't be recorded anymore.
+func (apply *EngineApply) Apply(ctx context.Context) (*resolve.PolicyResolution, error) {
 	// error count while applying changes
 	foundErrors := false
 
@@ -63,7 +104,8 @@ func (apply *EngineApply) Apply() (*resolve.PolicyResolution, error) {
 	apply.progress.SetTotal(len(apply.actions))
 
 	// process all actions
-	context := action.NewContext(
+	actionContext := action.NewContext(
+		ctx,
 		apply.desiredPolicy,
 		apply.desiredState,
 		apply.actualState,
@@ -71,20 +113,105 @@ func (apply *EngineApply) Apply() (*resolve.PolicyResolution, error) {
 		apply.externalData,
 		apply.plugins,
 		apply.eventLog,
+		apply.readiness,
 	)
-	for _, act := range apply.actions {
+
+	graph := newActionGraph(apply.actions, apply.desiredPolicy, apply.desiredState, apply.actualState)
+	ready := graph.getReady()
+	done := make(chan *actionResult)
+	running := 0
+	completed := 0
+	var fenceErr error
+
+	// progress indicator and graph are updated only from this goroutine, while actions are executed by workers
+	complete := func(node *actionNode, err error, skipped bool) {
+		completed++
+		apply.trackActionFinished(node, err, skipped)
 		apply.progress.Advance()
-		err := apply.executeAction(act, context)
 		if err != nil {
-			err = fmt.Errorf("error while applying action '%s': %s", act, err)
+			err = fmt.Errorf("error while applying action '%s': %s", node.action, err)
 			apply.eventLog.LogError(err)
 			foundErrors = true
 		}
+		ready = append(ready, graph.markDone(node, err != nil)...)
+	}
+
+	for completed < len(graph.nodes) {
+		// keep the original order of actions among the ready ones
+		sort.Slice(ready, func(i, j int) bool {
+			return ready[i].idx < ready[j].idx
+		})
+
+		for len(ready) > 0 && (running < apply.maxConcurrentActions || ready[0].failed || ctx.Err() != nil || fenceErr != nil) {
+			node := ready[0]
+			ready = ready[1:]
+
+			// do not execute actions which depend on the failed ones
+			if node.failed {
+				complete(node, fmt.Errorf("skipped, as one or more actions it depends on failed"), true)
+				continue
+			}
+
+			// do not execute any actions once applying got interrupted
+			if ctx.Err() != nil {
+				complete(node, fmt.Errorf("skipped, as applying got interrupted: %s", ctx.Err()), true)
+				continue
+			}
+
+			// do not execute any actions once fence check failed
+			if fenceErr == nil && apply.fence != nil {
+				fenceErr = apply.fence()
+			}
+			if fenceErr != nil {
+				complete(node, fmt.Errorf("skipped, as fence check failed: %s", fenceErr), true)
+				continue
+			}
+
+			running++
+			apply.trackActionStarted(node)
+			go func(node *actionNode) {
+				done <- &actionResult{node, apply.executeAction(node.action, actionContext)}
+			}(node)
+		}
+
+		if running == 0 {
+			if completed < len(graph.nodes) {
+				// it should never happen, as actions always form a DAG
+				apply.eventLog.LogError(fmt.Errorf("%d actions can't be executed due to circular dependencies", len(graph.nodes)-completed))
+				foundErrors = true
+			}
+			break
+		}
+
+		result := <-done
+		running--
+		// failures caused by interruption aren't recorded, as they are not related to component instances
+		if result.err != nil && ctx.Err() == nil {
+			apply.recordFailure(result.node.action, actionContext, result.err)
+		}
+		complete(result.node, result.err, false)
+	}
+
+	// Return error without finalizing progress indicator if fence check failed, as progress indicator saves results
+	if fenceErr == nil && apply.fence != nil {
+		fenceErr = apply.fence()
+	}
+	if fenceErr != nil {
+		err := fmt.Errorf("applying got fenced: %s", fenceErr)
+		apply.eventLog.LogError(err)
+		return apply.actualState, err
 	}
 
 	// Finalize progress indicator
 	apply.progress.Done(!foundErrors)
 
+	// Return error if applying got interrupted before all actions were executed
+	if foundErrors && ctx.Err() != nil {
+		err := fmt.Errorf("applying got interrupted: %s", ctx.Err())
+		apply.eventLog.LogError(err)
+		return apply.actualState, err
+	}
+
 	// Return error if there's been at least one error
 	if foundErrors {
 		err := fmt.Errorf("one or more errors occurred while running actions")
@@ -96,7 +223,12 @@ func (apply *EngineApply) Apply() (*resolve.PolicyResolution, error) {
 	return apply.actualState, nil
 }
 
-func (apply *EngineApply) executeAction(action action.Base, context *action.Context) (errResult error) {
+type actionResult struct {
+	node *actionNode
+	err  error
+}
+
+func (apply *EngineApply) executeAction(action action.Base, actionContext *action.Context) (errResult error) {
 	// make sure we are converting panics into errors
 	defer func() {
 		if err := recover(); err != nil {
@@ -104,5 +236,68 @@ func (apply *EngineApply) executeAction(action action.Base, context *action.Cont
 		}
 	}()
 
-	return action.Apply(context)
+	// limit time action could take
+	actionContext, cancel := actionContext.WithTimeout(apply.actionTimeout)
+	defer cancel()
+
+	return action.Apply(actionContext)
+}
+
+// trackActionStarted reports that action started executing, if progress indicator tracks results of individual actions
+func (apply *EngineApply) trackActionStarted(node *actionNode) {
+	if tracker, ok := apply.progress.(progress.ActionTracker); ok {
+		tracker.ActionStarted(node.idx, time.Now())
+	}
+}
+
+// trackActionFinished reports that action finished executing or got skipped, if progress indicator tracks results of
+// individual actions
+func (apply *EngineApply) trackActionFinished(node *actionNode, err error, skipped bool) {
+	if tracker, ok := apply.progress.(progress.ActionTracker); ok {
+		tracker.ActionFinished(node.idx, time.Now(), err, skipped)
+	}
+}
+
+// recordFailure records failed attempt to create, update or delete component instance in the actual state, so it will
+// be retried with backoff instead of being retried on every enforcement cycle
+func (apply *EngineApply) recordFailure(act action.Base, context *action.Context, err error) {
+	var key string
+	switch a := act.(type) {
+	case *component.CreateAction:
+		key = a.ComponentKey
+	case *component.UpdateAction:
+		key = a.ComponentKey
+	case *component.DeleteAction:
+		key = a.ComponentKey
+	default:
+		return
+	}
+
+	context.ActualStateLock.Lock()
+	defer context.ActualStateLock.Unlock()
+
+	instanceDesired := context.DesiredState.ComponentInstanceMap[key]
+	instance := context.ActualState.ComponentInstanceMap[key]
+	if instance == nil {
+		if instanceDesired == nil {
+			return
+		}
+
+		// component instance failed to be created, so keep it in the actual state without dependencies (it will be
+		// created again once it's time to retry)
+		instance = instanceDesired.CopyWithoutDependencies()
+		context.ActualState.ComponentInstanceMap[key] = instance
+	}
+
+	// record failure along with the code params component instance failed with
+	codeParams := instance.CalculatedCodeParams
+	if instanceDesired != nil && len(instanceDesired.DependencyKeys) > 0 {
+		codeParams = instanceDesired.CalculatedCodeParams
+	}
+	instance.RecordFailure(err, codeParams, apply.retryBackoff, time.Now())
+
+	errSave := context.ActualStateUpdater.Save(instance)
+	if errSave != nil {
+		apply.eventLog.LogError(fmt.Errorf("error while saving failure of component instance '%s': %s", key, errSave))
+	}
 }
@@ -1,7 +1,11 @@
 package apply
 
 import (
+	"context"
+	"fmt"
 	"github.com/Aptomi/aptomi/pkg/engine/actual"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
 	"github.com/Aptomi/aptomi/pkg/engine/diff"
 	"github.com/Aptomi/aptomi/pkg/engine/progress"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
@@ -12,6 +16,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/plugin"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Aptomi/aptomi/pkg/util/retry"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -22,6 +27,12 @@ const (
 	ResError   = iota
 )
 
+const maxConcurrentActions = 8
+
+const actionTimeout = time.Minute
+
+var retryBackoff = retry.Backoff{Base: time.Minute, Max: time.Hour, MaxAttempts: 2}
+
 func TestApplyComponentCreateSuccess(t *testing.T) {
 	// resolve empty policy
 	empty := newTestData(t, builder.NewPolicyBuilder())
@@ -41,6 +52,11 @@ func TestApplyComponentCreateSuccess(t *testing.T) {
 		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
 		event.NewLog("test-apply", false),
 		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
 	)
 
 	// check actual state
@@ -80,6 +96,11 @@ func checkApplyComponentCreateFail(t *testing.T, failAsPanic bool) {
 		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
 		event.NewLog("test-apply", false),
 		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
 	)
 	// check actual state
 	assert.Equal(t, 0, len(actualState.ComponentInstanceMap), "Actual state should be empty")
@@ -87,8 +108,362 @@ func checkApplyComponentCreateFail(t *testing.T, failAsPanic bool) {
 	// check for errors
 	actualState = applyAndCheck(t, applier, ResError, 1, "failed by plugin mock for component")
 
-	// check that actual state got updated (service component exists, but no child components got deployed)
-	assert.Equal(t, 1, len(actualState.ComponentInstanceMap), "Actual state should be correctly updated by apply()")
+	// check that actual state got updated (no child components got deployed, service depends on them so it didn't get deployed as well)
+	// failed component should be recorded in actual state along with its failure, but without dependencies
+	if assert.Equal(t, 1, len(actualState.ComponentInstanceMap), "Actual state should be correctly updated by apply()") {
+		for _, instance := range actualState.ComponentInstanceMap {
+			assert.Empty(t, instance.DependencyKeys, "Failed component should not have dependencies in actual state")
+			if assert.NotNil(t, instance.Failure, "Failure should be recorded for component") {
+				assert.Equal(t, 1, instance.Failure.Attempts, "First failed attempt should be recorded")
+				assert.Contains(t, instance.Failure.LastError, "failed by plugin mock for component", "Last error should be recorded")
+				assert.False(t, instance.Failure.IsStuck(), "Component should be retried after the first failure")
+			}
+		}
+	}
+}
+
+func TestApplyComponentCreateFailureRetry(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	b := makePolicyBuilder()
+	desired := newTestData(t, b)
+	failComponent := desired.policy().GetObjectsByKind(lang.ServiceObject.Kind)[0].(*lang.Service).Components[0].Name
+
+	applyWithRegistry := func(desired *testData, registry plugin.Registry, expectedResult int) {
+		t.Helper()
+		applier := NewEngineApply(
+			desired.policy(),
+			desired.resolution(),
+			actualState,
+			actual.NewNoOpActionStateUpdater(),
+			desired.external(),
+			registry,
+			diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
+			event.NewLog("test-apply", false),
+			progress.NewNoop(),
+			maxConcurrentActions,
+			retryBackoff,
+			action.Readiness{},
+			actionTimeout,
+			nil,
+		)
+		actualState = applyAndCheck(t, applier, expectedResult, 1, "failed by plugin mock for component")
+	}
+	getFailure := func() *resolve.ComponentInstanceFailure {
+		t.Helper()
+		for _, instance := range actualState.ComponentInstanceMap {
+			if instance.Failure != nil {
+				return instance.Failure
+			}
+		}
+		t.Fatal("Failure should be recorded for component")
+		return nil
+	}
+
+	// first attempt fails
+	applyWithRegistry(desired, mockRegistryFailOnComponent(false, failComponent), ResError)
+	assert.Equal(t, 1, getFailure().Attempts, "First failed attempt should be recorded")
+
+	// component (and service depending on it) should not be touched until it's time to retry
+	assert.Empty(t, diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions, "Failed component should not be retried before backoff expires")
+
+	// once it's time to retry, second attempt fails and component should not be retried anymore
+	getFailure().NextRetryAt = time.Now().Add(-time.Second)
+	applyWithRegistry(desired, mockRegistryFailOnComponent(false, failComponent), ResError)
+	assert.Equal(t, 2, getFailure().Attempts, "Second failed attempt should be recorded")
+	assert.True(t, getFailure().IsStuck(), "Component should not be retried after max attempts")
+	assert.Empty(t, diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions, "Stuck component should not be retried")
+
+	// once code params changed, component should be retried and successfully created
+	b.Policy().GetObjectsByKind(lang.DependencyObject.Kind)[0].(*lang.Dependency).Labels["param"] = "value2"
+	changed := newTestData(t, b)
+	applyWithRegistry(changed, mockRegistryFailOnComponent(false), ResSuccess)
+	assert.Equal(t, 2, len(actualState.ComponentInstanceMap), "Component and service should be created")
+	for _, instance := range actualState.ComponentInstanceMap {
+		assert.Nil(t, instance.Failure, "Failure should be cleared after successful attempt")
+		assert.NotEmpty(t, instance.DependencyKeys, "Created instance should have dependencies")
+	}
+}
+
+func TestApplyComponentCreateFailureNotNeeded(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy and fail component creation
+	desired := newTestData(t, makePolicyBuilder())
+	failComponent := desired.policy().GetObjectsByKind(lang.ServiceObject.Kind)[0].(*lang.Service).Components[0].Name
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actualState,
+		actual.NewNoOpActionStateUpdater(),
+		desired.external(),
+		mockRegistryFailOnComponent(false, failComponent),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
+		event.NewLog("test-apply", false),
+		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
+	)
+	actualState = applyAndCheck(t, applier, ResError, 1, "failed by plugin mock for component")
+	assert.Equal(t, 1, len(actualState.ComponentInstanceMap), "Failed component should be recorded in actual state")
+
+	// once component isn't needed anymore, it should be destroyed (as it could be created partially) and removed from
+	// actual state
+	applier = NewEngineApply(
+		desired.policy(),
+		empty.resolution(),
+		actualState,
+		actual.NewNoOpActionStateUpdater(),
+		desired.external(),
+		mockRegistryFailOnComponent(false),
+		diff.NewPolicyResolutionDiff(empty.resolution(), actualState).Actions,
+		event.NewLog("test-apply", false),
+		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
+	)
+	actualState = applyAndCheck(t, applier, ResSuccess, 0, "")
+	assert.Equal(t, 0, len(actualState.ComponentInstanceMap), "Failed component should be removed from actual state")
+}
+
+func TestApplyComponentWaitForReadiness(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// create a service with two components, where the second one depends on the first one
+	b := builder.NewPolicyBuilder()
+	service := b.AddService()
+	codeParams := util.NestedParameterMap{"cluster": "{{ .Labels.cluster }}"}
+	first := b.AddServiceComponent(service, b.CodeComponent(codeParams, nil))
+	second := b.AddServiceComponent(service, b.CodeComponent(codeParams, nil))
+	b.AddComponentDependency(second, first)
+	contract := b.AddContract(service, b.CriteriaTrue())
+	clusterObj := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, clusterObj.Name)))
+	b.AddDependency(b.AddUser(), contract)
+	desired := newTestData(t, b)
+
+	// first component never becomes ready
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actualState,
+		actual.NewNoOpActionStateUpdater(),
+		desired.external(),
+		&plugin.MockRegistry{
+			DeployPlugin:      &plugin.MockDeployPluginFailComponents{NotReadyComponents: []string{first.Name}},
+			PostProcessPlugin: &plugin.MockPostProcessPlugin{},
+		},
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
+		event.NewLog("test-apply", false),
+		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{Wait: true, Timeout: 100 * time.Millisecond, CheckInterval: 10 * time.Millisecond},
+		actionTimeout,
+		nil,
+	)
+	actualState = applyAndCheck(t, applier, ResSuccess, 0, "")
+
+	var firstInstance, secondInstance *resolve.ComponentInstance
+	for _, instance := range actualState.ComponentInstanceMap {
+		switch instance.Metadata.Key.ComponentName {
+		case first.Name:
+			firstInstance = instance
+		case second.Name:
+			secondInstance = instance
+		}
+	}
+	if !assert.NotNil(t, firstInstance, "First component instance should be created") || !assert.NotNil(t, secondInstance, "Second component instance should be created") {
+		t.FailNow()
+	}
+
+	// readiness should be recorded for both component instances
+	assert.False(t, firstInstance.Readiness.Ready, "First component instance should not be ready")
+	assert.True(t, firstInstance.Readiness.TimedOut, "Waiting for first component instance should time out")
+	assert.True(t, secondInstance.Readiness.Ready, "Second component instance should be ready")
+
+	// second component instance should be created only after waiting for the first one timed out
+	assert.False(t, secondInstance.CreatedAt.Before(firstInstance.Readiness.CheckedAt), "Dependent component instance should be created after waiting for readiness")
+}
+
+func TestApplyActionTimeout(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	desired := newTestData(t, makePolicyBuilder())
+
+	// apply changes with plugin which hangs on every action
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actualState,
+		actual.NewNoOpActionStateUpdater(),
+		desired.external(),
+		&plugin.MockRegistry{
+			DeployPlugin:      &plugin.MockDeployPlugin{SleepTime: time.Hour},
+			PostProcessPlugin: &plugin.MockPostProcessPlugin{},
+		},
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
+		event.NewLog("test-apply", false),
+		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		50*time.Millisecond,
+		nil,
+	)
+
+	// component creation should time out and failure should be recorded
+	actualState = applyAndCheck(t, applier, ResError, 1, "deadline exceeded")
+	assert.Equal(t, 1, len(actualState.ComponentInstanceMap), "Timed out component should be recorded in actual state")
+	for _, instance := range actualState.ComponentInstanceMap {
+		assert.NotNil(t, instance.Failure, "Failure should be recorded for timed out component")
+	}
+}
+
+func TestApplyInterrupted(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	desired := newTestData(t, makePolicyBuilder())
+
+	// apply changes with plugin which hangs on every action
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actualState,
+		actual.NewNoOpActionStateUpdater(),
+		desired.external(),
+		&plugin.MockRegistry{
+			DeployPlugin:      &plugin.MockDeployPlugin{SleepTime: time.Hour},
+			PostProcessPlugin: &plugin.MockPostProcessPlugin{},
+		},
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
+		event.NewLog("test-apply", false),
+		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
+	)
+
+	// interrupt applying while component is being created
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	actualState, err := applier.Apply(ctx)
+
+	// applying should stop without recording failures
+	if assert.Error(t, err, "Apply should fail once interrupted") {
+		assert.Contains(t, err.Error(), "interrupted", "Apply should report interruption")
+	}
+	assert.Empty(t, actualState.ComponentInstanceMap, "Interrupted component should not be recorded in actual state")
+}
+
+func TestApplyFenced(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	desired := newTestData(t, makePolicyBuilder())
+
+	// fence check fails after the first action
+	fenceChecks := 0
+	fence := func() error {
+		fenceChecks++
+		if fenceChecks > 1 {
+			return fmt.Errorf("lease lost")
+		}
+		return nil
+	}
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actualState,
+		actual.NewNoOpActionStateUpdater(),
+		desired.external(),
+		mockRegistryFailOnComponent(false),
+		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
+		event.NewLog("test-apply", false),
+		progress.NewNoop(),
+		1,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		fence,
+	)
+	actualState, err := applier.Apply(context.Background())
+
+	// only the first action should be applied
+	if assert.Error(t, err, "Apply should fail once fence check fails") {
+		assert.Contains(t, err.Error(), "lease lost", "Apply should report fence check error")
+	}
+	assert.Equal(t, 1, len(actualState.ComponentInstanceMap), "Only the first component should be created")
+}
+
+func TestApplyTracksActionResults(t *testing.T) {
+	// resolve empty policy
+	empty := newTestData(t, builder.NewPolicyBuilder())
+	actualState := empty.resolution()
+
+	// resolve full policy
+	desired := newTestData(t, makePolicyBuilder())
+
+	// process all actions (and make component fail deployment)
+	actions := diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions
+	tracker := newTrackingProgress()
+	applier := NewEngineApply(
+		desired.policy(),
+		desired.resolution(),
+		actualState,
+		actual.NewNoOpActionStateUpdater(),
+		desired.external(),
+		mockRegistryFailOnComponent(false, desired.policy().GetObjectsByKind(lang.ServiceObject.Kind)[0].(*lang.Service).Components[0].Name),
+		actions,
+		event.NewLog("test-apply", false),
+		tracker,
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
+	)
+	applyAndCheck(t, applier, ResError, 1, "failed by plugin mock for component")
+
+	// every action should be either executed or skipped
+	assert.Equal(t, len(actions), len(tracker.finished), "All actions should be finished")
+	failed, skipped := 0, 0
+	for idx := range actions {
+		if tracker.skipped[idx] {
+			skipped++
+			assert.NotContains(t, tracker.started, idx, "Skipped action should not be started")
+			continue
+		}
+		assert.Contains(t, tracker.started, idx, "Executed action should be started")
+		if tracker.finished[idx] != nil {
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed, "Failed component creation should be tracked")
+	assert.True(t, skipped > 0, "Actions depending on the failed one should be tracked as skipped")
 }
 
 func TestDiffHasUpdatedComponentsAndCheckTimes(t *testing.T) {
@@ -114,6 +489,11 @@ func TestDiffHasUpdatedComponentsAndCheckTimes(t *testing.T) {
 		diff.NewPolicyResolutionDiff(desired.resolution(), actualState).Actions,
 		event.NewLog("test-apply", false),
 		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
 	)
 
 	// Check that policy apply finished with expected results
@@ -156,6 +536,11 @@ func TestDiffHasUpdatedComponentsAndCheckTimes(t *testing.T) {
 		diff.NewPolicyResolutionDiff(desiredNext.resolution(), actualState).Actions,
 		event.NewLog("test-apply", false),
 		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
 	)
 
 	// Check that policy apply finished with expected results
@@ -192,6 +577,11 @@ func TestDiffHasUpdatedComponentsAndCheckTimes(t *testing.T) {
 		diff.NewPolicyResolutionDiff(desiredNextAfterUpdate.resolution(), actualState).Actions,
 		event.NewLog("test-apply", false),
 		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
 	)
 
 	// Check that policy apply finished with expected results
@@ -230,6 +620,11 @@ func TestDeletePolicyObjectsWhileComponentInstancesAreStilRunningFails(t *testin
 		diff.NewPolicyResolutionDiff(generated.resolution(), actualState).Actions,
 		event.NewLog("test-apply", false),
 		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
 	)
 
 	// Check that policy apply finished with expected results
@@ -250,6 +645,11 @@ func TestDeletePolicyObjectsWhileComponentInstancesAreStilRunningFails(t *testin
 		diff.NewPolicyResolutionDiff(reset.resolution(), actualState).Actions,
 		event.NewLog("test-apply", false),
 		progress.NewNoop(),
+		maxConcurrentActions,
+		retryBackoff,
+		action.Readiness{},
+		actionTimeout,
+		nil,
 	)
 
 	// delete/detach, delete/detach, endpoints/endpoints - 6 actions failed in total
@@ -262,6 +662,64 @@ func TestDeletePolicyObjectsWhileComponentInstancesAreStilRunningFails(t *testin
 */
 
 // Utility data structure for creating & resolving policy via builder in unit tests
+func TestActionGraphSiblingDeletes(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service with "web" depending on "db", as well as independent "cache"
+	service := b.AddService()
+	db := b.CodeComponent(nil, nil)
+	db.Name = "db"
+	web := b.CodeComponent(nil, nil)
+	web.Name = "web"
+	cache := b.CodeComponent(nil, nil)
+	cache.Name = "cache"
+	b.AddServiceComponent(service, db)
+	b.AddServiceComponent(service, web)
+	b.AddServiceComponent(service, cache)
+	b.AddComponentDependency(web, db)
+	contract := b.AddContract(service, b.CriteriaTrue())
+
+	clusterObj := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, clusterObj.Name)))
+	dependency := b.AddDependency(b.AddUser(), contract)
+
+	actualState := resolvePolicy(t, b)
+	keys := make(map[string]string)
+	now := time.Now()
+	for key, instance := range actualState.ComponentInstanceMap {
+		keys[instance.Metadata.Key.ComponentName] = key
+		instance.CreatedAt = now
+	}
+
+	// db got created after web depending on it (e.g. it got re-created)
+	actualState.ComponentInstanceMap[keys["db"]].CreatedAt = now.Add(time.Hour)
+
+	// service is still present in the desired policy, while all of its component instances are going to be deleted
+	desiredPolicy := b.Policy()
+	desiredPolicy.RemoveObject(dependency)
+	desiredState := resolvePolicy(t, b)
+	actions := diff.NewPolicyResolutionDiffWithPolicy(desiredPolicy, desiredState, actualState, nil).Actions
+	graph := newActionGraph(actions, desiredPolicy, desiredState, actualState)
+
+	// execute actions step by step, executing all ready actions in parallel on every step
+	deletedAt := make(map[string]int)
+	ready := graph.getReady()
+	for step := 0; len(ready) > 0; step++ {
+		next := []*actionNode{}
+		for _, node := range ready {
+			if deleteAction, ok := node.action.(*component.DeleteAction); ok {
+				deletedAt[deleteAction.ComponentKey] = step
+			}
+			next = append(next, graph.markDone(node, false)...)
+		}
+		ready = next
+	}
+
+	assert.Len(t, deletedAt, len(actualState.ComponentInstanceMap), "All component instances should be deleted")
+	assert.True(t, deletedAt[keys["web"]] < deletedAt[keys["db"]], "Web should be deleted before db it depends on, even though db is newer")
+	assert.Equal(t, deletedAt[keys["web"]], deletedAt[keys["cache"]], "Siblings without dependencies between them should be deleted in parallel")
+}
+
 type testData struct {
 	t        *testing.T
 	pBuilder *builder.PolicyBuilder
@@ -330,7 +788,7 @@ func resolvePolicy(t *testing.T, b *builder.PolicyBuilder) *resolve.PolicyResolu
 
 func applyAndCheck(t *testing.T, apply *EngineApply, expectedResult int, errorCnt int, expectedMessage string) *resolve.PolicyResolution {
 	t.Helper()
-	actualState, err := apply.Apply()
+	actualState, err := apply.Apply(context.Background())
 
 	if !assert.Equal(t, expectedResult != ResError, err == nil, "Apply status (success vs. error)") {
 		// print log into stdout and exit
@@ -374,6 +832,32 @@ func getInstanceInternal(t *testing.T, key string, resolution *resolve.PolicyRes
 	return instance
 }
 
+// trackingProgress is a progress indicator, which records results of individual actions
+type trackingProgress struct {
+	*progress.Noop
+	started  map[int]time.Time
+	finished map[int]error
+	skipped  map[int]bool
+}
+
+func newTrackingProgress() *trackingProgress {
+	return &trackingProgress{
+		Noop:     progress.NewNoop(),
+		started:  make(map[int]time.Time),
+		finished: make(map[int]error),
+		skipped:  make(map[int]bool),
+	}
+}
+
+func (tracker *trackingProgress) ActionStarted(idx int, startedAt time.Time) {
+	tracker.started[idx] = startedAt
+}
+
+func (tracker *trackingProgress) ActionFinished(idx int, finishedAt time.Time, err error, skipped bool) {
+	tracker.finished[idx] = err
+	tracker.skipped[idx] = skipped
+}
+
 func mockRegistryFailOnComponent(failAsPanic bool, failComponents ...string) plugin.Registry {
 	return &plugin.MockRegistry{
 		DeployPlugin: &plugin.MockDeployPluginFailComponents{
@@ -0,0 +1,207 @@
+package apply
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+)
+
+// actionNode is a node in the graph of actions. Action can be executed only when all actions it depends on are done
+type actionNode struct {
+	// action to be executed
+	action action.Base
+
+	// idx is an index of action in the original list of actions, it's used to preserve the original order when
+	// multiple actions are ready to be executed
+	idx int
+
+	// blockers is a number of actions this action is still waiting for
+	blockers int
+
+	// dependents is a list of actions waiting for this action
+	dependents []*actionNode
+
+	// global is true for actions not bound to a particular component instance (e.g. post-processing). They are
+	// executed after all component actions even if some of them failed
+	global bool
+
+	// failed is true if action itself or one of the actions it depends on failed
+	failed bool
+}
+
+// actionGraph is a DAG of actions, built from the component instance dependency graph in desired and actual state.
+// Actions on a component instance wait for actions on all component instances it depends on, while delete actions
+// wait for actions on all component instances depending on the one being deleted.
+type actionGraph struct {
+	nodes []*actionNode
+}
+
+func newActionGraph(actions []action.Base, desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) *actionGraph {
+	graph := &actionGraph{}
+
+	// group all component actions by component instance key, chaining actions on the same component instance
+	keyOrder := []string{}
+	nodesByKey := make(map[string][]*actionNode)
+	globalNodes := []*actionNode{}
+	for idx, act := range actions {
+		node := &actionNode{action: act, idx: idx}
+		graph.nodes = append(graph.nodes, node)
+
+		componentAction, ok := act.(action.ComponentAction)
+		if !ok {
+			node.global = true
+			globalNodes = append(globalNodes, node)
+			continue
+		}
+
+		key := componentAction.GetComponentKey()
+		if prevNodes, exist := nodesByKey[key]; exist {
+			prevNodes[len(prevNodes)-1].addDependent(node)
+		} else {
+			keyOrder = append(keyOrder, key)
+		}
+		nodesByKey[key] = append(nodesByKey[key], node)
+	}
+
+	// connect actions on different component instances
+	for _, key := range keyOrder {
+		first := nodesByKey[key][0]
+		for _, depKey := range getComponentDependencies(key, desiredPolicy, desiredState, actualState) {
+			if depNodes, exist := nodesByKey[depKey]; exist && depKey != key {
+				depNodes[len(depNodes)-1].addDependent(first)
+			}
+		}
+	}
+
+	// component instances within the same service instance have no graph edges between them in actual state, so
+	// deletes of siblings are ordered separately
+	addSiblingDeleteDependencies(graph, desiredPolicy, actualState)
+
+	// global actions should be executed after all component actions (and one after another)
+	var prevGlobal *actionNode
+	for _, globalNode := range globalNodes {
+		for _, key := range keyOrder {
+			keyNodes := nodesByKey[key]
+			keyNodes[len(keyNodes)-1].addDependent(globalNode)
+		}
+		if prevGlobal != nil {
+			prevGlobal.addDependent(globalNode)
+		}
+		prevGlobal = globalNode
+	}
+
+	return graph
+}
+
+// getComponentDependencies returns keys of component instances, actions on which should be completed before
+// actions on the given component instance could be started
+func getComponentDependencies(key string, desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, actualState *resolve.PolicyResolution) []string {
+	// component instance is present in desired state, so it should wait for everything it depends on
+	if instance, ok := desiredState.ComponentInstanceMap[key]; ok {
+		result := []string{}
+		for dstKey := range instance.EdgesOut {
+			result = append(result, dstKey)
+		}
+		siblingKeys, _ := instance.Metadata.Key.GetSiblingDependencies(desiredPolicy)
+		return append(result, siblingKeys...)
+	}
+
+	// component instance is going to be deleted, so it should wait for everything that depended on it to be deleted first
+	if instance, ok := actualState.ComponentInstanceMap[key]; ok {
+		result := []string{}
+		for srcKey := range instance.EdgesIn {
+			result = append(result, srcKey)
+		}
+		return result
+	}
+
+	return nil
+}
+
+// addSiblingDeleteDependencies makes deletes of component instances within the same service instance wait for deletes
+// of their dependents. If service and all its components being deleted are still present in the desired policy, then
+// the order is derived from component dependencies (reversed, as dependents are deleted first). Otherwise deletes of
+// siblings are executed one after another in the order they were listed in (i.e. by creation time, newest first)
+func addSiblingDeleteDependencies(graph *actionGraph, desiredPolicy *lang.Policy, actualState *resolve.PolicyResolution) {
+	serviceOrder := []string{}
+	deletesByService := make(map[string][]*actionNode)
+	deleteByKey := make(map[string]*actionNode)
+	for _, node := range graph.nodes {
+		deleteAction, ok := node.action.(*component.DeleteAction)
+		if !ok {
+			continue
+		}
+		instance, ok := actualState.ComponentInstanceMap[deleteAction.ComponentKey]
+		if !ok || !instance.Metadata.Key.IsComponent() {
+			continue
+		}
+		serviceKey := instance.Metadata.Key.GetParentServiceKey().GetKey()
+		if _, exist := deletesByService[serviceKey]; !exist {
+			serviceOrder = append(serviceOrder, serviceKey)
+		}
+		deletesByService[serviceKey] = append(deletesByService[serviceKey], node)
+		deleteByKey[deleteAction.ComponentKey] = node
+	}
+
+	for _, serviceKey := range serviceOrder {
+		nodes := deletesByService[serviceKey]
+		siblingKeys := make(map[*actionNode][]string)
+		known := true
+		for _, node := range nodes {
+			instance := actualState.ComponentInstanceMap[node.action.(*component.DeleteAction).ComponentKey]
+			siblingKeys[node], known = instance.Metadata.Key.GetSiblingDependencies(desiredPolicy)
+			if !known {
+				break
+			}
+		}
+
+		if !known {
+			for idx := 1; idx < len(nodes); idx++ {
+				nodes[idx-1].addDependent(nodes[idx])
+			}
+			continue
+		}
+
+		for _, node := range nodes {
+			for _, siblingKey := range siblingKeys[node] {
+				if siblingNode, exist := deleteByKey[siblingKey]; exist && siblingNode != node {
+					node.addDependent(siblingNode)
+				}
+			}
+		}
+	}
+}
+
+func (node *actionNode) addDependent(dependent *actionNode) {
+	node.dependents = append(node.dependents, dependent)
+	dependent.blockers++
+}
+
+// getReady returns actions which don't wait for any other actions
+func (graph *actionGraph) getReady() []*actionNode {
+	result := []*actionNode{}
+	for _, node := range graph.nodes {
+		if node.blockers == 0 {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// markDone marks action as done and returns dependent actions, which became ready to be executed
+func (graph *actionGraph) markDone(node *actionNode, failed bool) []*actionNode {
+	node.failed = node.failed || failed
+
+	result := []*actionNode{}
+	for _, dependent := range node.dependents {
+		if node.failed && !dependent.global {
+			dependent.failed = true
+		}
+		dependent.blockers--
+		if dependent.blockers == 0 {
+			result = append(result, dependent)
+		}
+	}
+	return result
+}
@@ -0,0 +1,33 @@
+package diff
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
+	"sort"
+)
+
+// ChangedNamespaces returns a sorted list of namespaces with component instances, which are going to be created,
+// updated or deleted by the diff
+func (diff *PolicyResolutionDiff) ChangedNamespaces() []string {
+	namespaces := make(map[string]bool)
+	for _, act := range diff.Actions {
+		switch act.(type) {
+		case *component.CreateAction, *component.UpdateAction, *component.DeleteAction:
+			key := act.(action.ComponentAction).GetComponentKey()
+			instance := diff.Next.ComponentInstanceMap[key]
+			if instance == nil {
+				instance = diff.Prev.ComponentInstanceMap[key]
+			}
+			if instance != nil {
+				namespaces[instance.Metadata.Key.Namespace] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(namespaces))
+	for namespace := range namespaces {
+		result = append(result, namespace)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,30 @@
+package diff
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiffChangedNamespaces(t *testing.T) {
+	b := makePolicyBuilder()
+	resolvedEmpty := resolvePolicy(t, builder.NewPolicyBuilder())
+
+	// no changes
+	diff := NewPolicyResolutionDiff(resolvedEmpty, resolvedEmpty)
+	assert.Empty(t, diff.ChangedNamespaces(), "No namespaces should be changed")
+
+	// add dependency
+	d1 := b.AddDependency(b.AddUser(), b.Policy().GetObjectsByKind(lang.ContractObject.Kind)[0].(*lang.Contract))
+	d1.Labels["param"] = "value1"
+	resolvedNext := resolvePolicy(t, b)
+
+	// component instances get created
+	diff = NewPolicyResolutionDiff(resolvedNext, resolvedEmpty)
+	assert.Equal(t, []string{"main"}, diff.ChangedNamespaces(), "Namespace of created component instances should be changed")
+
+	// component instances get deleted
+	diff = NewPolicyResolutionDiff(resolvedEmpty, resolvedNext)
+	assert.Equal(t, []string{"main"}, diff.ChangedNamespaces(), "Namespace of deleted component instances should be changed")
+}
@@ -0,0 +1,47 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
+)
+
+// DeleteLimits defines how destructive a single diff is allowed to be before it requires an explicit approval. Zero
+// value of a limit means that it's disabled
+type DeleteLimits struct {
+	// MaxDeleteActions is the max number of component instances, which could be deleted
+	MaxDeleteActions int
+
+	// MaxDeletePercent is the max percentage of existing component instances, which could be deleted
+	MaxDeletePercent int
+}
+
+// CountDeleteActions returns the number of component instances, which are going to be deleted by the diff
+func (diff *PolicyResolutionDiff) CountDeleteActions() int {
+	result := 0
+	for _, act := range diff.Actions {
+		if _, ok := act.(*component.DeleteAction); ok {
+			result++
+		}
+	}
+	return result
+}
+
+// CheckDeleteLimits returns an error describing which limit got exceeded, if the diff deletes too many component
+// instances. It returns nil if diff is within the limits
+func (diff *PolicyResolutionDiff) CheckDeleteLimits(limits DeleteLimits) error {
+	deletes := diff.CountDeleteActions()
+	if deletes <= 0 {
+		return nil
+	}
+
+	if limits.MaxDeleteActions > 0 && deletes > limits.MaxDeleteActions {
+		return fmt.Errorf("%d component instances would be deleted, while max allowed is %d", deletes, limits.MaxDeleteActions)
+	}
+
+	total := len(diff.Prev.ComponentInstanceMap)
+	if limits.MaxDeletePercent > 0 && total > 0 && deletes*100 > limits.MaxDeletePercent*total {
+		return fmt.Errorf("%d out of %d component instances would be deleted, while max allowed is %d%%", deletes, total, limits.MaxDeletePercent)
+	}
+
+	return nil
+}
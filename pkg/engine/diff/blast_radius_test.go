@@ -0,0 +1,29 @@
+package diff
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiffDeleteLimits(t *testing.T) {
+	b := makePolicyBuilder()
+	d1 := b.AddDependency(b.AddUser(), b.Policy().GetObjectsByKind(lang.ContractObject.Kind)[0].(*lang.Contract))
+	d1.Labels["param"] = "value1"
+	resolvedPrev := resolvePolicy(t, b)
+
+	// creating component instances is never limited
+	diff := NewPolicyResolutionDiff(resolvedPrev, resolvePolicy(t, builder.NewPolicyBuilder()))
+	assert.Equal(t, 0, diff.CountDeleteActions(), "No component instances should be deleted")
+	assert.NoError(t, diff.CheckDeleteLimits(DeleteLimits{MaxDeleteActions: 1, MaxDeletePercent: 1}), "Diff without deletes should be within limits")
+
+	// deleting everything
+	diff = NewPolicyResolutionDiff(resolvePolicy(t, builder.NewPolicyBuilder()), resolvedPrev)
+	assert.Equal(t, 2, diff.CountDeleteActions(), "All component instances should be deleted")
+
+	assert.NoError(t, diff.CheckDeleteLimits(DeleteLimits{}), "Disabled limits should never be exceeded")
+	assert.NoError(t, diff.CheckDeleteLimits(DeleteLimits{MaxDeleteActions: 2, MaxDeletePercent: 100}), "Diff should be within limits")
+	assert.Error(t, diff.CheckDeleteLimits(DeleteLimits{MaxDeleteActions: 1}), "Max delete actions limit should be exceeded")
+	assert.Error(t, diff.CheckDeleteLimits(DeleteLimits{MaxDeletePercent: 50}), "Max delete percent limit should be exceeded")
+}
@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"sort"
+	"time"
+)
+
+// deletionOrder returns keys of component instances, which are not present in the desired state anymore, in the order
+// they should be deleted in. It's the reverse of the order they were processed in: component instance gets deleted
+// only after all component instances pointing to it via graph edges in the previous state (e.g. service instance for
+// its components, contract component for the service instance it consumes) are deleted.
+//
+// Component instances within the same service instance don't have graph edges between them. If their service is still
+// present in the desired policy, then dependents are deleted before component instances they depend on, same as for
+// graph edges. Otherwise they are ordered by creation time, as they get created in topological order of their
+// dependencies. So, the ones created later (dependents) are deleted first.
+func (diff *PolicyResolutionDiff) deletionOrder(keys map[string]bool) []string {
+	remaining := make(map[string]bool)
+	for key := range keys {
+		remaining[key] = true
+	}
+	siblingEdgesIn := diff.siblingEdgesIn(keys)
+
+	result := []string{}
+	for len(remaining) > 0 {
+		// pick component instances, which nothing remaining points to
+		layer := []string{}
+		for key := range remaining {
+			if !diff.hasRemainingEdgesIn(key, remaining) && !hasRemaining(siblingEdgesIn[key], remaining) {
+				layer = append(layer, key)
+			}
+		}
+
+		// it should never happen, as edges always form a DAG. but if it does, just delete what's left
+		if len(layer) <= 0 {
+			for key := range remaining {
+				layer = append(layer, key)
+			}
+		}
+
+		sort.Slice(layer, func(i, j int) bool {
+			createdI, createdJ := diff.createdAt(layer[i]), diff.createdAt(layer[j])
+			if !createdI.Equal(createdJ) {
+				return createdI.After(createdJ)
+			}
+			return layer[i] < layer[j]
+		})
+
+		for _, key := range layer {
+			result = append(result, key)
+			delete(remaining, key)
+		}
+	}
+
+	return result
+}
+
+// hasRemainingEdgesIn returns true if any of the remaining component instances points to the given one in the
+// previous state
+func (diff *PolicyResolutionDiff) hasRemainingEdgesIn(key string, remaining map[string]bool) bool {
+	instance := diff.Prev.ComponentInstanceMap[key]
+	if instance == nil {
+		return false
+	}
+	for srcKey := range instance.EdgesIn {
+		if srcKey != key && remaining[srcKey] {
+			return true
+		}
+	}
+	return false
+}
+
+// siblingEdgesIn returns, for every given component instance, the given component instances within the same service
+// instance, which depend on it according to the desired policy. Component instances, which aren't present in the
+// desired policy anymore, are skipped, as their dependencies are unknown
+func (diff *PolicyResolutionDiff) siblingEdgesIn(keys map[string]bool) map[string]map[string]bool {
+	result := make(map[string]map[string]bool)
+	for key := range keys {
+		instance := diff.Prev.ComponentInstanceMap[key]
+		if instance == nil {
+			continue
+		}
+		siblingKeys, _ := instance.Metadata.Key.GetSiblingDependencies(diff.desiredPolicy)
+		for _, siblingKey := range siblingKeys {
+			if siblingKey == key || !keys[siblingKey] {
+				continue
+			}
+			if result[siblingKey] == nil {
+				result[siblingKey] = make(map[string]bool)
+			}
+			result[siblingKey][key] = true
+		}
+	}
+	return result
+}
+
+// hasRemaining returns true if any of the given keys is still remaining
+func hasRemaining(keys map[string]bool, remaining map[string]bool) bool {
+	for key := range keys {
+		if remaining[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// createdAt returns creation time of the component instance in the previous state
+func (diff *PolicyResolutionDiff) createdAt(key string) time.Time {
+	instance := diff.Prev.ComponentInstanceMap[key]
+	if instance == nil {
+		return time.Time{}
+	}
+	return instance.CreatedAt
+}
@@ -0,0 +1,229 @@
+package diff
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDiffDeleteOrder(t *testing.T) {
+	b, app, backend, _ := makeDeleteOrderPolicyBuilder()
+	resolvedPrev := resolvePolicy(t, b)
+	setCreationTimes(resolvedPrev)
+	resolvedEmpty := resolvePolicy(t, builder.NewPolicyBuilder())
+
+	// map iteration order is random, so check it multiple times
+	for i := 0; i < 20; i++ {
+		diff := NewPolicyResolutionDiff(resolvedEmpty, resolvedPrev)
+		deleted := getActionIndexes(diff, component.DeleteActionObject.Kind)
+		assert.Equal(t, len(resolvedPrev.ComponentInstanceMap), len(deleted), "All component instances should be deleted")
+
+		// component instances should be deleted only after everything that points to them
+		verifyDeleteOrder(t, diff.Prev, deleted)
+
+		// dependents should be deleted before component instances they depend on (even though key order is different)
+		web := findInstanceKey(t, resolvedPrev, app.Name, "web")
+		db := findInstanceKey(t, resolvedPrev, app.Name, "db")
+		backendComponent := findInstanceKey(t, resolvedPrev, app.Name, "backend")
+		backendService := findInstanceKey(t, resolvedPrev, backend.Name, "")
+		store := findInstanceKey(t, resolvedPrev, backend.Name, "store")
+		assert.True(t, deleted[web] < deleted[db], "Web should be deleted before db")
+		assert.True(t, deleted[web] < deleted[backendComponent], "Web should be deleted before backend")
+		assert.True(t, deleted[backendComponent] < deleted[backendService], "Backend contract component should be deleted before backend service")
+		assert.True(t, deleted[backendService] < deleted[store], "Backend service should be deleted before its components")
+	}
+}
+
+func TestDiffMixedCreateUpdateDeleteOrder(t *testing.T) {
+	b, app, _, dependency := makeDeleteOrderPolicyBuilder()
+	resolvedPrev := resolvePolicy(t, b)
+	setCreationTimes(resolvedPrev)
+
+	// update web, add queue which web depends on, remove worker and backend
+	components := app.GetComponentsMap()
+	queue := b.CodeComponent(nil, nil)
+	queue.Name = "queue"
+	web := *components["web"]
+	web.Dependencies = []string{"db", "queue"}
+	appNext := &lang.Service{
+		TypeKind:   app.TypeKind,
+		Metadata:   app.Metadata,
+		Components: []*lang.ServiceComponent{components["db"], queue, &web},
+	}
+	policy := b.Policy()
+	policy.RemoveObject(app)
+	assert.NoError(t, policy.AddObject(appNext), "Service should be replaced")
+	dependency.Labels["param"] = "value2"
+	resolvedNext := resolvePolicy(t, b)
+
+	for i := 0; i < 20; i++ {
+		diff := NewPolicyResolutionDiff(resolvedNext, resolvedPrev)
+		created := getActionIndexes(diff, component.CreateActionObject.Kind)
+		updated := getActionIndexes(diff, component.UpdateActionObject.Kind)
+		deleted := getActionIndexes(diff, component.DeleteActionObject.Kind)
+
+		// creates and updates should follow dependency order
+		queueKey := findInstanceKey(t, resolvedNext, app.Name, "queue")
+		webKey := findInstanceKey(t, resolvedNext, app.Name, "web")
+		if assert.Contains(t, created, queueKey, "Queue should be created") && assert.Contains(t, updated, webKey, "Web should be updated") {
+			assert.True(t, created[queueKey] < updated[webKey], "Queue should be created before web gets updated")
+		}
+
+		// deletes should follow reverse dependency order
+		assert.Equal(t, 4, len(deleted), "Worker, backend and its service instance should be deleted")
+		verifyDeleteOrder(t, diff.Prev, deleted)
+
+		// deletes should go after creates and updates
+		for _, deleteIdx := range deleted {
+			for _, idx := range created {
+				assert.True(t, idx < deleteIdx, "Deletes should go after creates")
+			}
+			for _, idx := range updated {
+				assert.True(t, idx < deleteIdx, "Deletes should go after updates")
+			}
+		}
+	}
+}
+
+func TestDiffDeleteOrderSiblingsWithoutCreationTimes(t *testing.T) {
+	b, _, _, _ := makeDeleteOrderPolicyBuilder()
+	resolvedPrev := resolvePolicy(t, b)
+	resolvedEmpty := resolvePolicy(t, builder.NewPolicyBuilder())
+
+	// without creation times order should still be deterministic and respect graph edges
+	first := NewPolicyResolutionDiff(resolvedEmpty, resolvedPrev)
+	verifyDeleteOrder(t, first.Prev, getActionIndexes(first, component.DeleteActionObject.Kind))
+	for i := 0; i < 20; i++ {
+		diff := NewPolicyResolutionDiff(resolvedEmpty, resolvedPrev)
+		assert.Equal(t, getActionIndexes(first, component.DeleteActionObject.Kind), getActionIndexes(diff, component.DeleteActionObject.Kind), "Delete order should be deterministic")
+	}
+}
+
+func TestDiffDeleteOrderSiblingsByDependencies(t *testing.T) {
+	b, app, _, dependency := makeDeleteOrderPolicyBuilder()
+	resolvedPrev := resolvePolicy(t, b)
+
+	// db got created after web and worker depending on it (e.g. it got re-created)
+	setCreationTimes(resolvedPrev)
+	db := findInstanceKey(t, resolvedPrev, app.Name, "db")
+	web := findInstanceKey(t, resolvedPrev, app.Name, "web")
+	worker := findInstanceKey(t, resolvedPrev, app.Name, "worker")
+	resolvedPrev.ComponentInstanceMap[db].CreatedAt = time.Now().Add(time.Hour)
+
+	// service is still present in the desired policy, while all of its component instances are going to be deleted
+	policy := b.Policy()
+	policy.RemoveObject(dependency)
+	resolvedNext := resolvePolicy(t, b)
+
+	for i := 0; i < 20; i++ {
+		diff := NewPolicyResolutionDiffWithPolicy(policy, resolvedNext, resolvedPrev, nil)
+		deleted := getActionIndexes(diff, component.DeleteActionObject.Kind)
+		assert.Equal(t, len(resolvedPrev.ComponentInstanceMap), len(deleted), "All component instances should be deleted")
+		verifyDeleteOrder(t, diff.Prev, deleted)
+
+		// dependents should be deleted first according to the policy, regardless of creation times
+		assert.True(t, deleted[web] < deleted[db], "Web should be deleted before db")
+		assert.True(t, deleted[worker] < deleted[db], "Worker should be deleted before db")
+	}
+
+	// without policy deletes of siblings fall back to creation times
+	diff := NewPolicyResolutionDiff(resolvedNext, resolvedPrev)
+	deleted := getActionIndexes(diff, component.DeleteActionObject.Kind)
+	assert.True(t, deleted[db] < deleted[web], "Db should be deleted first, as it was created last")
+}
+
+/*
+	Helpers
+*/
+
+// makeDeleteOrderPolicyBuilder creates a policy with two services. App service has "web" component depending on
+// "db" and "backend" (contract component pointing to backend service), as well as "worker" depending on "db"
+func makeDeleteOrderPolicyBuilder() (*builder.PolicyBuilder, *lang.Service, *lang.Service, *lang.Dependency) {
+	b := builder.NewPolicyBuilder()
+
+	backend := b.AddService()
+	store := b.CodeComponent(nil, nil)
+	store.Name = "store"
+	b.AddServiceComponent(backend, store)
+	backendContract := b.AddContract(backend, b.CriteriaTrue())
+
+	app := b.AddService()
+	db := b.CodeComponent(nil, nil)
+	db.Name = "db"
+	backendComponent := b.ContractComponent(backendContract)
+	backendComponent.Name = "backend"
+	web := b.CodeComponent(util.NestedParameterMap{"param": "{{ .Labels.param }}"}, nil)
+	web.Name = "web"
+	worker := b.CodeComponent(nil, nil)
+	worker.Name = "worker"
+	b.AddServiceComponent(app, db)
+	b.AddServiceComponent(app, backendComponent)
+	b.AddServiceComponent(app, web)
+	b.AddServiceComponent(app, worker)
+	b.AddComponentDependency(web, db)
+	b.AddComponentDependency(web, backendComponent)
+	b.AddComponentDependency(worker, db)
+	appContract := b.AddContract(app, b.CriteriaTrue())
+
+	clusterObj := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, clusterObj.Name)))
+
+	dependency := b.AddDependency(b.AddUser(), appContract)
+	dependency.Labels["param"] = "value1"
+
+	return b, app, backend, dependency
+}
+
+// setCreationTimes sets creation times of component instances as if they were created in the processing order
+func setCreationTimes(state *resolve.PolicyResolution) {
+	now := time.Now()
+	for idx, key := range state.GetComponentProcessingOrder() {
+		state.ComponentInstanceMap[key].CreatedAt = now.Add(time.Duration(idx) * time.Second)
+	}
+}
+
+// getActionIndexes returns a map from component key to the index of the component action of a given kind in the diff
+func getActionIndexes(diff *PolicyResolutionDiff, kind string) map[string]int {
+	result := make(map[string]int)
+	for idx, act := range diff.Actions {
+		componentAction, ok := act.(action.ComponentAction)
+		if ok && act.GetKind() == kind {
+			result[componentAction.GetComponentKey()] = idx
+		}
+	}
+	return result
+}
+
+// findInstanceKey returns key of a component instance by service and component name (empty for service instance)
+func findInstanceKey(t *testing.T, state *resolve.PolicyResolution, serviceName string, componentName string) string {
+	t.Helper()
+	for key, instance := range state.ComponentInstanceMap {
+		cik := instance.Metadata.Key
+		if cik.ServiceName != serviceName {
+			continue
+		}
+		if (componentName == "" && cik.IsService()) || (componentName != "" && cik.ComponentName == componentName) {
+			return key
+		}
+	}
+	t.Fatalf("Component instance %s/%s not found", serviceName, componentName)
+	return ""
+}
+
+// verifyDeleteOrder checks that every component instance is deleted after all deleted component instances pointing to it
+func verifyDeleteOrder(t *testing.T, prev *resolve.PolicyResolution, deleted map[string]int) {
+	t.Helper()
+	for key, idx := range deleted {
+		for srcKey := range prev.ComponentInstanceMap[key].EdgesIn {
+			if srcIdx, ok := deleted[srcKey]; ok {
+				assert.True(t, srcIdx < idx, "Component instance %s should be deleted after %s", key, srcKey)
+			}
+		}
+	}
+}
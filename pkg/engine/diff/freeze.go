@@ -0,0 +1,87 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"sort"
+)
+
+// frozenInstances returns component instances, which are in scope of the currently active freeze windows, along with
+// the name of the freeze window
+func (diff *PolicyResolutionDiff) frozenInstances(keys map[string]bool) map[string]string {
+	result := make(map[string]string)
+
+	active := []*lang.FreezeWindow{}
+	for _, window := range diff.freezeWindows {
+		if window.IsActive(diff.now) {
+			active = append(active, window)
+		}
+	}
+	if len(active) <= 0 {
+		return result
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Name < active[j].Name
+	})
+
+	for key := range keys {
+		instance := diff.Next.ComponentInstanceMap[key]
+		if instance == nil {
+			instance = diff.Prev.ComponentInstanceMap[key]
+		}
+
+		var labels map[string]string
+		if instance.CalculatedLabels != nil {
+			labels = instance.CalculatedLabels.Labels
+		}
+
+		for _, window := range active {
+			if window.InScope(instance.Metadata.Key.Namespace, instance.Metadata.Key.ClusterName, labels) {
+				result[key] = window.Name
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// deferredBy returns an explanation why changes of component instance should be deferred, if it's in scope of an
+// active freeze window or it depends on a component instance, which is
+func (diff *PolicyResolutionDiff) deferredBy(instanceKey string, frozen map[string]string, frozenKeys map[string]bool) (string, bool) {
+	if window, ok := frozen[instanceKey]; ok {
+		return fmt.Sprintf("in scope of freeze window '%s'", window), true
+	}
+	if diff.dependsOn(instanceKey, frozenKeys, make(map[string]bool)) {
+		return "depends on component instance in scope of a freeze window", true
+	}
+	return "", false
+}
+
+// instanceChanged returns true if component instance needs to be changed to get from an actual state to the desired state
+func instanceChanged(prevInstance *resolve.ComponentInstance, nextInstance *resolve.ComponentInstance) bool {
+	var depKeysPrev, depKeysNext map[string]bool
+	if prevInstance != nil {
+		depKeysPrev = prevInstance.DependencyKeys
+	}
+	if nextInstance != nil {
+		depKeysNext = nextInstance.DependencyKeys
+	}
+
+	// component, which failed to be created and isn't needed anymore, still needs to be deleted
+	if prevInstance != nil && prevInstance.Failure != nil && len(depKeysNext) <= 0 {
+		return true
+	}
+
+	if len(depKeysPrev) != len(depKeysNext) {
+		return true
+	}
+	for dependencyID := range depKeysPrev {
+		if !depKeysNext[dependencyID] {
+			return true
+		}
+	}
+
+	return len(depKeysPrev) > 0 && !prevInstance.CalculatedCodeParams.DeepEqual(nextInstance.CalculatedCodeParams)
+}
@@ -0,0 +1,59 @@
+package diff
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDiffFreezeWindows(t *testing.T) {
+	b := makePolicyBuilder()
+	resolvedEmpty := resolvePolicy(t, builder.NewPolicyBuilder())
+
+	// add dependency
+	d1 := b.AddDependency(b.AddUser(), b.Policy().GetObjectsByKind(lang.ContractObject.Kind)[0].(*lang.Contract))
+	d1.Labels["param"] = "value1"
+	resolvedNext := resolvePolicy(t, b)
+
+	now := time.Now()
+	makeWindow := func(from time.Time, to time.Time, namespaces ...string) *lang.FreezeWindow {
+		return &lang.FreezeWindow{
+			TypeKind:   lang.FreezeWindowObject.GetTypeKind(),
+			Metadata:   lang.Metadata{Namespace: runtime.SystemNS, Name: "freeze"},
+			From:       from.Format(time.RFC3339),
+			To:         to.Format(time.RFC3339),
+			Namespaces: namespaces,
+		}
+	}
+
+	// freeze window, which is over, doesn't defer anything
+	diff := NewPolicyResolutionDiffWithFreezeWindows(resolvedNext, resolvedEmpty, []*lang.FreezeWindow{makeWindow(now.Add(-2*time.Hour), now.Add(-time.Hour))})
+	verifyDiff(t, diff, 2, 0, 0, 2, 0, 2, 1)
+	assert.Empty(t, diff.Deferred, "No changes should be deferred")
+
+	// freeze window in another namespace doesn't defer anything
+	diff = NewPolicyResolutionDiffWithFreezeWindows(resolvedNext, resolvedEmpty, []*lang.FreezeWindow{makeWindow(now.Add(-time.Hour), now.Add(time.Hour), "other")})
+	verifyDiff(t, diff, 2, 0, 0, 2, 0, 2, 1)
+	assert.Empty(t, diff.Deferred, "No changes should be deferred")
+
+	// active freeze window defers creation and deletion of component instances
+	window := makeWindow(now.Add(-time.Hour), now.Add(time.Hour), "main")
+	diff = NewPolicyResolutionDiffWithFreezeWindows(resolvedNext, resolvedEmpty, []*lang.FreezeWindow{window})
+	verifyDiff(t, diff, 0, 0, 0, 0, 0, 0, 0)
+	assert.Len(t, diff.Deferred, 2, "Creation of component instances should be deferred")
+	for _, reason := range diff.Deferred {
+		assert.Contains(t, reason, "freeze window 'freeze'", "Deferred changes should be explained")
+	}
+
+	diff = NewPolicyResolutionDiffWithFreezeWindows(resolvedEmpty, resolvedNext, []*lang.FreezeWindow{window})
+	verifyDiff(t, diff, 0, 0, 0, 0, 0, 0, 0)
+	assert.Len(t, diff.Deferred, 2, "Deletion of component instances should be deferred")
+
+	// unchanged component instances aren't reported as deferred
+	diff = NewPolicyResolutionDiffWithFreezeWindows(resolvedNext, resolvedNext, []*lang.FreezeWindow{window})
+	verifyDiff(t, diff, 0, 0, 0, 0, 0, 0, 0)
+	assert.Empty(t, diff.Deferred, "No changes should be deferred")
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action/component"
 	"github.com/Aptomi/aptomi/pkg/engine/apply/action/global"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"time"
 )
 
 // PolicyResolutionDiff represents a difference between two policy resolution data structs (actual and desired states)
@@ -17,22 +19,95 @@ type PolicyResolutionDiff struct {
 
 	// Actions is a generated, ordered list of actions that need to be executed in order to get from an actual state to the desired state
 	Actions []action.Base
+
+	// Deferred contains component instances (by key), which changes got deferred by active freeze windows, along with
+	// the explanation why
+	Deferred map[string]string
+
+	// desiredPolicy is the policy desired state got resolved from, it's used to order deletes of sibling component
+	// instances by their dependencies. It's optional
+	desiredPolicy *lang.Policy
+
+	// freezeWindows is a list of freeze windows, which may defer changes of component instances
+	freezeWindows []*lang.FreezeWindow
+
+	// now is the moment of time diff is calculated at, it's used to check whether failed components should be retried
+	now time.Time
 }
 
 // NewPolicyResolutionDiff calculates difference between two given policy resolution structs (actual and desired states).
 // It iterates over all component instances and figures out which component instances have to be instantiated (new
 // consumers appeared and they didn't exist before), which component instances have to be updated (e.g. parameters changed), which component
 // instances have to be destroyed (that have no consumers left), and so on.
+//
+// Component instances, which failed to be changed recently, are retried with exponential backoff. Once they failed too
+// many times, they are left untouched until their code params change (see resolve.ComponentInstanceFailure).
 func NewPolicyResolutionDiff(next *resolve.PolicyResolution, prev *resolve.PolicyResolution) *PolicyResolutionDiff {
+	return NewPolicyResolutionDiffWithFreezeWindows(next, prev, nil)
+}
+
+// NewPolicyResolutionDiffWithFreezeWindows calculates difference between two given policy resolution structs the same
+// way as NewPolicyResolutionDiff, but component instances in scope of the currently active freeze windows (as well as
+// component instances depending on them) are left untouched. Their changes are deferred until freeze windows are over
+// (see PolicyResolutionDiff.Deferred).
+func NewPolicyResolutionDiffWithFreezeWindows(next *resolve.PolicyResolution, prev *resolve.PolicyResolution, freezeWindows []*lang.FreezeWindow) *PolicyResolutionDiff {
+	return NewPolicyResolutionDiffWithPolicy(nil, next, prev, freezeWindows)
+}
+
+// NewPolicyResolutionDiffWithPolicy calculates difference between two given policy resolution structs the same way as
+// NewPolicyResolutionDiffWithFreezeWindows, but also takes the desired policy, which desired state got resolved from.
+// Policy is used to order deletes of component instances within the same service instance by their dependencies, while
+// otherwise they are ordered by creation time (the ones created later are deleted first).
+func NewPolicyResolutionDiffWithPolicy(desiredPolicy *lang.Policy, next *resolve.PolicyResolution, prev *resolve.PolicyResolution, freezeWindows []*lang.FreezeWindow) *PolicyResolutionDiff {
 	result := &PolicyResolutionDiff{
-		Prev:    prev,
-		Next:    next,
-		Actions: []action.Base{},
+		Prev:          prev,
+		Next:          next,
+		Actions:       []action.Base{},
+		Deferred:      make(map[string]string),
+		desiredPolicy: desiredPolicy,
+		freezeWindows: freezeWindows,
+		now:           time.Now(),
 	}
 	result.compareAndProduceActions()
 	return result
 }
 
+// canRetry returns true if changes could be applied to the component instance, which may have failed before
+func canRetry(prevInstance *resolve.ComponentInstance, nextInstance *resolve.ComponentInstance, now time.Time) bool {
+	if prevInstance == nil || prevInstance.Failure == nil {
+		return true
+	}
+
+	// component, which failed to be created and isn't needed anymore, could be removed right away (it's not in the cloud)
+	if len(prevInstance.DependencyKeys) <= 0 && (nextInstance == nil || len(nextInstance.DependencyKeys) <= 0) {
+		return true
+	}
+
+	// compare with the code params the component instance is going to have (or already has, if it's being deleted)
+	codeParams := prevInstance.CalculatedCodeParams
+	if nextInstance != nil && len(nextInstance.DependencyKeys) > 0 {
+		codeParams = nextInstance.CalculatedCodeParams
+	}
+	return prevInstance.Failure.CanRetry(codeParams, now)
+}
+
+// dependsOn returns true if component instance depends (directly or transitively) on any of the given component
+// instances in the desired state
+func (diff *PolicyResolutionDiff) dependsOn(instanceKey string, keys map[string]bool, seen map[string]bool) bool {
+	instance := diff.Next.ComponentInstanceMap[instanceKey]
+	if len(keys) == 0 || instance == nil || seen[instanceKey] {
+		return false
+	}
+	seen[instanceKey] = true
+
+	for keyOut := range instance.EdgesOut {
+		if keys[keyOut] || diff.dependsOn(keyOut, keys, seen) {
+			return true
+		}
+	}
+	return false
+}
+
 func appendUpdateAction(actions []action.Base, updateActions map[string]bool, updateAction *component.UpdateAction) []action.Base {
 	if !updateActions[updateAction.GetName()] {
 		updateActions[updateAction.GetName()] = true
@@ -59,6 +134,21 @@ func (diff *PolicyResolutionDiff) compareAndProduceActions() { // nolint: gocycl
 		allKeys[key] = true
 	}
 
+	// find components, which recently failed and should not be retried yet
+	notRetried := make(map[string]bool)
+	for instanceKey := range allKeys {
+		if !canRetry(diff.Prev.ComponentInstanceMap[instanceKey], diff.Next.ComponentInstanceMap[instanceKey], diff.now) {
+			notRetried[instanceKey] = true
+		}
+	}
+
+	// find components, which are in scope of active freeze windows
+	frozen := diff.frozenInstances(allKeys)
+	frozenKeys := make(map[string]bool)
+	for instanceKey := range frozen {
+		frozenKeys[instanceKey] = true
+	}
+
 	// go over all the keys and see which one appear and which one disappear
 	for instanceKey := range allKeys {
 		prevInstance := diff.Prev.ComponentInstanceMap[instanceKey]
@@ -74,6 +164,20 @@ func (diff *PolicyResolutionDiff) compareAndProduceActions() { // nolint: gocycl
 			depKeysNext = nextInstance.DependencyKeys
 		}
 
+		// do not touch a component, which recently failed, until it's time to retry or its code params changed. the
+		// same applies to all components depending on it
+		if notRetried[instanceKey] || diff.dependsOn(instanceKey, notRetried, make(map[string]bool)) {
+			continue
+		}
+
+		// do not touch a component during a freeze window, the same applies to all components depending on it
+		if reason, deferred := diff.deferredBy(instanceKey, frozen, frozenKeys); deferred {
+			if instanceChanged(prevInstance, nextInstance) {
+				diff.Deferred[instanceKey] = reason
+			}
+			continue
+		}
+
 		componentChanged := false
 
 		// see if a component needs to be instantiated
@@ -82,8 +186,8 @@ func (diff *PolicyResolutionDiff) compareAndProduceActions() { // nolint: gocycl
 			actions[instanceKey] = append(actions[instanceKey], component.NewCreateAction(instanceKey))
 		}
 
-		// see if a component needs to be destructed
-		if len(depKeysPrev) > 0 && len(depKeysNext) <= 0 {
+		// see if a component needs to be destructed (including the one which failed to be created and isn't needed anymore)
+		if (len(depKeysPrev) > 0 || (prevInstance != nil && prevInstance.Failure != nil)) && len(depKeysNext) <= 0 {
 			actions[instanceKey] = append(actions[instanceKey], component.NewDeleteAction(instanceKey))
 		}
 
@@ -102,6 +206,10 @@ func (diff *PolicyResolutionDiff) compareAndProduceActions() { // nolint: gocycl
 					serviceKey := nextInstance.Metadata.Key.GetParentServiceKey().GetKey()
 					actions[serviceKey] = appendUpdateAction(actions[serviceKey], updateActions, component.NewUpdateAction(serviceKey))
 				}
+			} else if len(prevInstance.Drift) > 0 {
+				// component drifted in the cloud, so it should be updated to get back to the actual state
+				componentChanged = true
+				actions[instanceKey] = appendUpdateAction(actions[instanceKey], updateActions, component.NewUpdateAction(instanceKey))
 			}
 		}
 
@@ -135,9 +243,14 @@ func (diff *PolicyResolutionDiff) compareAndProduceActions() { // nolint: gocycl
 		}
 	}
 
-	// if there are actions left (deleted components, not present in desired state), process them explicitly
-	for key, actionList := range actions {
-		diff.Actions = append(diff.Actions, actionList...)
+	// if there are actions left (deleted components, not present in desired state), process them explicitly in the
+	// reverse order, so dependents get deleted before component instances they depend on
+	leftKeys := make(map[string]bool)
+	for key := range actions {
+		leftKeys[key] = true
+	}
+	for _, key := range diff.deletionOrder(leftKeys) {
+		diff.Actions = append(diff.Actions, actions[key]...)
 		delete(actions, key)
 	}
 
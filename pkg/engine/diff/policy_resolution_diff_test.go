@@ -8,9 +8,12 @@ import (
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Aptomi/aptomi/pkg/util/retry"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestDiffEmpty(t *testing.T) {
@@ -89,6 +92,102 @@ func TestDiffComponentDelete(t *testing.T) {
 	verifyDiff(t, diffAgain, 0, 2, 0, 0, 2, 2, 1)
 }
 
+func TestDiffPreserveFailedDependencies(t *testing.T) {
+	b := makePolicyBuilder()
+	contract := b.Policy().GetObjectsByKind(lang.ContractObject.Kind)[0].(*lang.Contract)
+
+	// add dependency
+	d1 := b.AddDependency(b.AddUser(), contract)
+	d1.Labels["param"] = "value1"
+	resolvedPrev := resolvePolicy(t, b)
+
+	// make dependency fail by pointing it to a service with a cycle
+	serviceCycle := b.AddService()
+	contractCycle := b.AddContract(serviceCycle, b.CriteriaTrue())
+	b.AddServiceComponent(serviceCycle, b.ContractComponent(contractCycle))
+	d1.Contract = contractCycle.Namespace + "/" + contractCycle.Name
+
+	// without preserving failed dependencies, components of the failed dependency would be deleted
+	resolvedNext := resolvePolicyPartially(t, b)
+	assert.Contains(t, resolvedNext.GetDependencyErrors(), runtime.KeyForStorable(d1), "Dependency should fail")
+	diff := NewPolicyResolutionDiff(resolvedNext, resolvedPrev)
+	verifyDiff(t, diff, 0, 2, 0, 0, 2, 2, 1)
+
+	// once failed dependencies are preserved, its components should be left untouched
+	resolvedNext = resolvePolicyPartially(t, b)
+	resolvedNext.PreserveFailedDependencies(resolvedPrev)
+	diff = NewPolicyResolutionDiff(resolvedNext, resolvedPrev)
+	verifyDiff(t, diff, 0, 0, 0, 0, 0, 0, 0)
+
+	// other dependencies should still be processed
+	d2 := b.AddDependency(b.AddUser(), contract)
+	d2.Labels["param"] = "value1"
+	resolvedNext = resolvePolicyPartially(t, b)
+	resolvedNext.PreserveFailedDependencies(resolvedPrev)
+	diff = NewPolicyResolutionDiff(resolvedNext, resolvedPrev)
+	verifyDiff(t, diff, 0, 0, 0, 2, 0, 2, 1)
+}
+
+func TestDiffComponentUpdateFailure(t *testing.T) {
+	b := makePolicyBuilder()
+	contract := b.Policy().GetObjectsByKind(lang.ContractObject.Kind)[0].(*lang.Contract)
+
+	// add dependency
+	d1 := b.AddDependency(b.AddUser(), contract)
+	d1.Labels["param"] = "value1"
+	resolvedPrev := resolvePolicy(t, b)
+
+	// update dependency
+	d1.Labels["param"] = "value2"
+	resolvedNext := resolvePolicy(t, b)
+	verifyDiff(t, NewPolicyResolutionDiff(resolvedNext, resolvedPrev), 0, 0, 2, 0, 0, 1, 1)
+
+	// record failed update of the component
+	var failed *resolve.ComponentInstance
+	for key, instance := range resolvedPrev.ComponentInstanceMap {
+		if instance.Metadata.Key.IsComponent() {
+			failed = instance
+			failed.RecordFailure(fmt.Errorf("update failed"), resolvedNext.ComponentInstanceMap[key].CalculatedCodeParams, retry.Backoff{Base: time.Hour}, time.Now())
+		}
+	}
+
+	// component (and service depending on it) should not be updated until it's time to retry
+	verifyDiff(t, NewPolicyResolutionDiff(resolvedNext, resolvedPrev), 0, 0, 0, 0, 0, 0, 0)
+
+	// once it's time to retry, component should be updated
+	failed.Failure.NextRetryAt = time.Now().Add(-time.Second)
+	verifyDiff(t, NewPolicyResolutionDiff(resolvedNext, resolvedPrev), 0, 0, 2, 0, 0, 1, 1)
+
+	// component, which failed too many times, should not be updated
+	failed.Failure.NextRetryAt = time.Time{}
+	verifyDiff(t, NewPolicyResolutionDiff(resolvedNext, resolvedPrev), 0, 0, 0, 0, 0, 0, 0)
+
+	// until its code params change
+	d1.Labels["param"] = "value3"
+	resolvedNext = resolvePolicy(t, b)
+	verifyDiff(t, NewPolicyResolutionDiff(resolvedNext, resolvedPrev), 0, 0, 2, 0, 0, 1, 1)
+}
+
+func TestDiffComponentDrift(t *testing.T) {
+	b := makePolicyBuilder()
+	contract := b.Policy().GetObjectsByKind(lang.ContractObject.Kind)[0].(*lang.Contract)
+	d1 := b.AddDependency(b.AddUser(), contract)
+	d1.Labels["param"] = "value1"
+	resolvedPrev := resolvePolicy(t, b)
+	resolvedNext := resolvePolicy(t, b)
+
+	// nothing changed
+	verifyDiff(t, NewPolicyResolutionDiff(resolvedNext, resolvedPrev), 0, 0, 0, 0, 0, 0, 0)
+
+	// component drifted in the cloud, so it should be updated even though its params are the same
+	for _, instance := range resolvedPrev.ComponentInstanceMap {
+		if instance.Metadata.Key.IsComponent() {
+			instance.Drift = "not found in the cloud"
+		}
+	}
+	verifyDiff(t, NewPolicyResolutionDiff(resolvedNext, resolvedPrev), 0, 0, 1, 0, 0, 1, 1)
+}
+
 /*
 	Helpers
 */
@@ -126,6 +225,19 @@ func resolvePolicy(t *testing.T, builder *builder.PolicyBuilder) *resolve.Policy
 	return result
 }
 
+func resolvePolicyPartially(t *testing.T, builder *builder.PolicyBuilder) *resolve.PolicyResolution {
+	t.Helper()
+	eventLog := event.NewLog("test-resolve", false)
+	resolver := resolve.NewPolicyResolver(builder.Policy(), builder.External(), eventLog)
+	result, err := resolver.ResolveAllDependenciesPartially()
+	if !assert.NoError(t, err, "Policy should be partially resolved without errors") {
+		hook := &event.HookConsole{}
+		eventLog.Save(hook)
+		t.FailNow()
+	}
+	return result
+}
+
 func verifyDiff(t *testing.T, diff *PolicyResolutionDiff, componentInstantiate int, componentDestruct int, componentUpdate int, componentAttachDependency int, componentDetachDependency int, componentEndpoints int, clusters int) {
 	t.Helper()
 	cnt := struct {
@@ -0,0 +1,3 @@
+// Package drift allows Aptomi to detect drift between actual state (what Aptomi believes it deployed) and what is
+// really running in the cloud, e.g. when Helm release got deleted or upgraded by hand bypassing Aptomi.
+package drift
@@ -0,0 +1,102 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"sort"
+)
+
+// Detect checks all component instances deployed according to the actual state against their live state in the
+// cloud. Only deploy plugins implementing plugin.LiveStatePlugin are able to report live state, component instances
+// deployed by other plugins are skipped, as well as the ones which recently failed (they are going to be retried
+// anyway). It returns drifted component instances (by key) along with the explanation of what has drifted.
+//
+// Errors while checking a particular component instance are logged and don't stop checking other component
+// instances. Checking stops once the given context is done.
+func Detect(ctx context.Context, policy *lang.Policy, actualState *resolve.PolicyResolution, plugins plugin.Registry, eventLog *event.Log) map[string]string {
+	// go over instances in a sorted order, so the check is always done in the same order
+	keys := []string{}
+	for key := range actualState.ComponentInstanceMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]string)
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			eventLog.LogError(fmt.Errorf("drift check got interrupted: %s", ctx.Err()))
+			break
+		}
+
+		drift, err := detectInstance(ctx, policy, actualState.ComponentInstanceMap[key], plugins, eventLog)
+		if err != nil {
+			eventLog.LogError(fmt.Errorf("error while checking drift of component instance '%s': %s", key, err))
+			continue
+		}
+		if len(drift) > 0 {
+			eventLog.WithFields(event.Fields{
+				"componentKey": key,
+			}).Warningf("Component instance '%s' has drifted: %s", key, drift)
+			result[key] = drift
+		}
+	}
+
+	return result
+}
+
+// detectInstance returns the explanation of how the live state of component instance differs from the actual state.
+// It returns an empty string if there is no drift or it can't be detected for the component instance
+func detectInstance(ctx context.Context, policy *lang.Policy, instance *resolve.ComponentInstance, plugins plugin.Registry, eventLog *event.Log) (string, error) {
+	// component instance isn't supposed to be deployed or its state isn't known for sure
+	if len(instance.DependencyKeys) <= 0 || instance.Failure != nil || !instance.Metadata.Key.IsComponent() {
+		return "", nil
+	}
+
+	serviceObj, err := policy.GetObject(lang.ServiceObject.Kind, instance.Metadata.Key.ServiceName, instance.Metadata.Key.Namespace)
+	if err != nil || serviceObj == nil {
+		// service has been removed from the policy, so component instance is going to be deleted anyway
+		return "", nil
+	}
+	component := serviceObj.(*lang.Service).GetComponentsMap()[instance.Metadata.Key.ComponentName]
+	if component == nil || component.Code == nil {
+		return "", nil
+	}
+
+	deployPlugin, err := plugins.GetDeployPlugin(component.Code.Type)
+	if err != nil {
+		return "", err
+	}
+	liveStatePlugin, ok := deployPlugin.(plugin.LiveStatePlugin)
+	if !ok {
+		return "", nil
+	}
+
+	clusterName, ok := instance.CalculatedCodeParams[lang.LabelCluster].(string)
+	if !ok {
+		return "", fmt.Errorf("no cluster specified in code params")
+	}
+	clusterObj, err := policy.GetObject(lang.ClusterObject.Kind, clusterName, runtime.SystemNS)
+	if err != nil {
+		return "", err
+	}
+	if clusterObj == nil {
+		return "", fmt.Errorf("can't find cluster in policy: %s", clusterName)
+	}
+
+	expected, err := liveStatePlugin.ExpectedLiveState(instance.CalculatedCodeParams)
+	if err != nil {
+		return "", err
+	}
+
+	live, err := liveStatePlugin.LiveState(ctx, clusterObj.(*lang.Cluster), instance.GetDeployName(), eventLog)
+	if err != nil {
+		return "", err
+	}
+
+	return live.Drift(expected), nil
+}
@@ -0,0 +1,93 @@
+package drift
+
+import (
+	"context"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+	service := b.AddService()
+	web := b.AddServiceComponent(service, b.CodeComponent(util.NestedParameterMap{lang.LabelCluster: "{{ .Labels.cluster }}", "version": "1.0", "param": "value"}, nil))
+	db := b.AddServiceComponent(service, b.CodeComponent(util.NestedParameterMap{lang.LabelCluster: "{{ .Labels.cluster }}", "version": "2.0"}, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+	clusterObj := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, clusterObj.Name)))
+	b.AddDependency(b.AddUser(), contract)
+	actualState := resolvePolicy(t, b)
+
+	// nothing drifted
+	livePlugin := plugin.NewMockLiveStatePlugin()
+	for _, instance := range actualState.ComponentInstanceMap {
+		if instance.Metadata.Key.IsComponent() {
+			expected, err := livePlugin.ExpectedLiveState(instance.CalculatedCodeParams)
+			assert.NoError(t, err, "Expected live state should be calculated")
+			livePlugin.Live[instance.GetDeployName()] = expected
+		}
+	}
+	registry := &plugin.MockRegistry{DeployPlugin: livePlugin, PostProcessPlugin: &plugin.MockPostProcessPlugin{}}
+	assert.Empty(t, Detect(context.Background(), b.Policy(), actualState, registry, event.NewLog("test-drift", false)), "Nothing should drift")
+
+	// web got deleted by hand, while db got a different version
+	webKey, dbKey := "", ""
+	for key, instance := range actualState.ComponentInstanceMap {
+		switch instance.Metadata.Key.ComponentName {
+		case web.Name:
+			webKey = key
+			livePlugin.Live[instance.GetDeployName()] = &plugin.LiveState{Exists: false}
+		case db.Name:
+			dbKey = key
+			livePlugin.Live[instance.GetDeployName()].Version = "2.1"
+		}
+	}
+	drifted := Detect(context.Background(), b.Policy(), actualState, registry, event.NewLog("test-drift", false))
+	assert.Equal(t, 2, len(drifted), "Both component instances should drift")
+	assert.Contains(t, drifted[webKey], "not found", "Deleted component instance should drift")
+	assert.Contains(t, drifted[dbKey], "version is 2.1", "Component instance with different version should drift")
+
+	// params of web got changed by hand
+	for _, instance := range actualState.ComponentInstanceMap {
+		if instance.Metadata.Key.ComponentName == web.Name {
+			params := instance.CalculatedCodeParams.MakeCopy()
+			params["param"] = "changed"
+			live, _ := livePlugin.ExpectedLiveState(params)
+			livePlugin.Live[instance.GetDeployName()] = live
+		}
+	}
+	drifted = Detect(context.Background(), b.Policy(), actualState, registry, event.NewLog("test-drift", false))
+	assert.Contains(t, drifted[webKey], "params", "Component instance with changed params should drift")
+
+	// failed component instances and plugin errors are not reported as drift
+	actualState.ComponentInstanceMap[webKey].Failure = &resolve.ComponentInstanceFailure{Attempts: 1}
+	livePlugin.Fail = db.Name
+	assert.Empty(t, Detect(context.Background(), b.Policy(), actualState, registry, event.NewLog("test-drift", false)), "Failed component instances and errors should not be reported as drift")
+
+	// deploy plugins, which don't support live state, are skipped
+	registry.DeployPlugin = &plugin.MockDeployPlugin{}
+	actualState.ComponentInstanceMap[webKey].Failure = nil
+	assert.Empty(t, Detect(context.Background(), b.Policy(), actualState, registry, event.NewLog("test-drift", false)), "Plugins not supporting live state should be skipped")
+}
+
+/*
+	Helpers
+*/
+
+func resolvePolicy(t *testing.T, b *builder.PolicyBuilder) *resolve.PolicyResolution {
+	t.Helper()
+	eventLog := event.NewLog("test-resolve", false)
+	resolver := resolve.NewPolicyResolver(b.Policy(), b.External(), eventLog)
+	result, err := resolver.ResolveAllDependencies()
+	if !assert.NoError(t, err, "Policy should be resolved without errors") {
+		hook := &event.HookConsole{}
+		eventLog.Save(hook)
+		t.FailNow()
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// DriftReportObject is Info for DriftReport
+var DriftReportObject = &runtime.Info{
+	Kind:        "drift-report",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &DriftReport{} },
+}
+
+// DriftReportName is the name of the DriftReport object (there is only one drift report, which gets overwritten by
+// every drift check)
+const DriftReportName = "latest"
+
+// DriftReportKey is the key for the DriftReport object
+var DriftReportKey = runtime.KeyFromParts(runtime.SystemNS, DriftReportObject.Kind, DriftReportName)
+
+// DriftReport represents results of the latest drift check, which compares component instances in the actual state
+// with what is really running in the cloud
+type DriftReport struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// CheckedAt is when the drift check was done
+	CheckedAt time.Time
+
+	// Drifted contains component instances (by key), which have drifted in the cloud, along with the explanation of
+	// what has drifted
+	Drifted map[string]string `yaml:",omitempty"`
+
+	// Corrected is true if drifted component instances got scheduled to be updated by the next enforcement
+	Corrected bool
+}
+
+// GetName returns DriftReport name
+func (report *DriftReport) GetName() string {
+	return DriftReportName
+}
+
+// GetNamespace returns DriftReport namespace
+func (report *DriftReport) GetNamespace() string {
+	return runtime.SystemNS
+}
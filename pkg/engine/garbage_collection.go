@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine/gc"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// GarbageCollectionObject is Info for GarbageCollection
+var GarbageCollectionObject = &runtime.Info{
+	Kind:        "garbage-collection",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &GarbageCollection{} },
+}
+
+// GarbageCollectionName is the name of the GarbageCollection object (there is only one garbage collection, which gets
+// overwritten by every new plan)
+const GarbageCollectionName = "latest"
+
+// GarbageCollectionKey is the key for the GarbageCollection object
+var GarbageCollectionKey = runtime.KeyFromParts(runtime.SystemNS, GarbageCollectionObject.Kind, GarbageCollectionName)
+
+// GarbageCollectionStatus represents status of the garbage collection
+type GarbageCollectionStatus string
+
+const (
+	// GarbageCollectionStatusPlanRequested represents garbage collection, which orphans haven't been found for yet
+	GarbageCollectionStatusPlanRequested GarbageCollectionStatus = "plan requested"
+	// GarbageCollectionStatusPlanned represents garbage collection, which orphans have been found for, waiting for
+	// confirmation to delete them
+	GarbageCollectionStatusPlanned GarbageCollectionStatus = "planned"
+	// GarbageCollectionStatusRunRequested represents garbage collection, which has been confirmed, but orphans haven't
+	// been deleted yet
+	GarbageCollectionStatusRunRequested GarbageCollectionStatus = "run requested"
+	// GarbageCollectionStatusDone represents garbage collection, which orphans have been deleted for
+	GarbageCollectionStatusDone GarbageCollectionStatus = "done"
+	// GarbageCollectionStatusError represents garbage collection, which has failed
+	GarbageCollectionStatusError GarbageCollectionStatus = "error"
+)
+
+// GarbageCollection represents the latest garbage collection of deployments left in the cloud, which aren't
+// referenced by any component instance. Orphans are found first (plan) and get deleted only once it's confirmed
+// by a user (run)
+type GarbageCollection struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Status is the status of the garbage collection
+	Status GarbageCollectionStatus
+
+	// PlanRequestedBy is the name of the user, who requested to find orphans
+	PlanRequestedBy string
+
+	// PlannedAt is when orphans were found
+	PlannedAt time.Time
+
+	// Orphans contains deployments, which have been found in the cloud, but aren't referenced by any component instance
+	Orphans []*gc.Orphan `yaml:",omitempty"`
+
+	// RunRequestedBy is the name of the user, who confirmed deletion of orphans
+	RunRequestedBy string `yaml:",omitempty"`
+
+	// FinishedAt is when orphans were deleted or garbage collection failed
+	FinishedAt time.Time
+
+	// Deleted contains orphans, which have been deleted
+	Deleted []*gc.Orphan `yaml:",omitempty"`
+
+	// Error is the error garbage collection failed with
+	Error string `yaml:",omitempty"`
+}
+
+// GetName returns GarbageCollection name
+func (collection *GarbageCollection) GetName() string {
+	return GarbageCollectionName
+}
+
+// GetNamespace returns GarbageCollection namespace
+func (collection *GarbageCollection) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// IsInProgress returns true if the garbage collection has been requested, but hasn't been done yet
+func (collection *GarbageCollection) IsInProgress() bool {
+	return collection.Status == GarbageCollectionStatusPlanRequested || collection.Status == GarbageCollectionStatusRunRequested
+}
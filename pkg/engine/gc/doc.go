@@ -0,0 +1,3 @@
+// Package gc allows Aptomi to find and delete deployments left in the cloud, which aren't referenced by any component
+// instance (e.g. Helm releases left behind by failed deletes or actual state reset).
+package gc
@@ -0,0 +1,136 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"sort"
+)
+
+// Orphan represents deployment in the cloud, which isn't referenced by any component instance
+type Orphan struct {
+	// Cluster is the name of the cluster deployment is running in
+	Cluster string
+
+	// Name is the name of the deployment (e.g. Helm release name)
+	Name string
+}
+
+// Find returns orphaned deployments in all clusters from the policy. Only deploy plugins implementing plugin.GCPlugin
+// are able to find orphans. Component instances from both actual and desired state are considered to be referenced,
+// so deployments about to be adopted or updated are never reported as orphans.
+func Find(ctx context.Context, policy *lang.Policy, actualState *resolve.PolicyResolution, desiredState *resolve.PolicyResolution, plugins plugin.Registry, eventLog *event.Log) ([]*Orphan, error) {
+	deployNames := getDeployNames(actualState, desiredState)
+
+	result := []*Orphan{}
+	for _, cluster := range getClusters(policy) {
+		for _, gcPlugin := range getGCPlugins(plugins) {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("garbage collection got interrupted: %s", ctx.Err())
+			}
+
+			names, err := gcPlugin.Orphans(ctx, cluster, deployNames[cluster.Name], eventLog)
+			if err != nil {
+				return nil, fmt.Errorf("error while looking for orphans in cluster '%s': %s", cluster.Name, err)
+			}
+			for _, name := range names {
+				result = append(result, &Orphan{Cluster: cluster.Name, Name: name})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Delete deletes the given orphaned deployments, which are still orphaned (i.e. haven't been adopted or created
+// again since they were found). It returns deleted orphans. Deleting stops on the first error.
+func Delete(ctx context.Context, policy *lang.Policy, actualState *resolve.PolicyResolution, desiredState *resolve.PolicyResolution, orphans []*Orphan, plugins plugin.Registry, eventLog *event.Log) ([]*Orphan, error) {
+	requested := make(map[string]map[string]bool)
+	for _, orphan := range orphans {
+		if requested[orphan.Cluster] == nil {
+			requested[orphan.Cluster] = make(map[string]bool)
+		}
+		requested[orphan.Cluster][orphan.Name] = true
+	}
+
+	deployNames := getDeployNames(actualState, desiredState)
+
+	result := []*Orphan{}
+	for _, cluster := range getClusters(policy) {
+		if len(requested[cluster.Name]) <= 0 {
+			continue
+		}
+
+		for _, gcPlugin := range getGCPlugins(plugins) {
+			if ctx.Err() != nil {
+				return result, fmt.Errorf("garbage collection got interrupted: %s", ctx.Err())
+			}
+
+			// orphans get looked up again, so only deployments which are still orphaned get deleted
+			names, err := gcPlugin.Orphans(ctx, cluster, deployNames[cluster.Name], eventLog)
+			if err != nil {
+				return result, fmt.Errorf("error while looking for orphans in cluster '%s': %s", cluster.Name, err)
+			}
+
+			for _, name := range names {
+				if !requested[cluster.Name][name] {
+					continue
+				}
+
+				eventLog.WithFields(event.Fields{
+					"cluster": cluster.Name,
+					"name":    name,
+				}).Infof("Deleting orphaned deployment '%s' in cluster '%s'", name, cluster.Name)
+
+				err = gcPlugin.DestroyOrphan(ctx, cluster, name, eventLog)
+				if err != nil {
+					return result, fmt.Errorf("error while deleting orphaned deployment '%s' in cluster '%s': %s", name, cluster.Name, err)
+				}
+				result = append(result, &Orphan{Cluster: cluster.Name, Name: name})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// getDeployNames returns deploy names of all component instances from the given states, grouped by cluster name
+func getDeployNames(states ...*resolve.PolicyResolution) map[string][]string {
+	result := make(map[string][]string)
+	for _, state := range states {
+		for _, instance := range state.ComponentInstanceMap {
+			clusterName, ok := instance.CalculatedCodeParams[lang.LabelCluster].(string)
+			if !ok {
+				continue
+			}
+			result[clusterName] = append(result[clusterName], instance.GetDeployName())
+		}
+	}
+	return result
+}
+
+// getClusters returns all clusters from the policy sorted by name
+func getClusters(policy *lang.Policy) []*lang.Cluster {
+	result := []*lang.Cluster{}
+	for _, obj := range policy.GetObjectsByKind(lang.ClusterObject.Kind) {
+		result = append(result, obj.(*lang.Cluster))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// getGCPlugins returns all deploy plugins, which are able to find orphans
+func getGCPlugins(plugins plugin.Registry) []plugin.GCPlugin {
+	result := []plugin.GCPlugin{}
+	for _, deployPlugin := range plugins.GetDeployPlugins() {
+		if gcPlugin, ok := deployPlugin.(plugin.GCPlugin); ok {
+			result = append(result, gcPlugin)
+		}
+	}
+	return result
+}
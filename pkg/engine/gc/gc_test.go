@@ -0,0 +1,110 @@
+package gc
+
+import (
+	"context"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+)
+
+func TestFindAndDelete(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+	service := b.AddService()
+	b.AddServiceComponent(service, b.CodeComponent(util.NestedParameterMap{lang.LabelCluster: "{{ .Labels.cluster }}"}, nil))
+	b.AddServiceComponent(service, b.CodeComponent(util.NestedParameterMap{lang.LabelCluster: "{{ .Labels.cluster }}"}, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+	clusterObj := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, clusterObj.Name)))
+	b.AddDependency(b.AddUser(), contract)
+	desiredState := resolvePolicy(t, b)
+
+	// everything from the desired state and a couple of orphans are deployed
+	orphanKey := &resolve.ComponentInstanceKey{ComponentName: "orphan"}
+	gcPlugin := &mockGCPlugin{deployed: map[string]bool{"another": true, orphanKey.GetDeployName(): true}}
+	var deployNames []string
+	for _, instance := range desiredState.ComponentInstanceMap {
+		if instance.Metadata.Key.IsComponent() {
+			gcPlugin.deployed[instance.GetDeployName()] = true
+			deployNames = append(deployNames, instance.GetDeployName())
+		}
+	}
+	registry := &plugin.MockRegistry{DeployPlugin: gcPlugin, PostProcessPlugin: &plugin.MockPostProcessPlugin{}}
+
+	// deployments from desired state aren't orphans, even though actual state is empty
+	actualState := resolve.NewPolicyResolution(false)
+	orphans, err := Find(context.Background(), b.Policy(), actualState, desiredState, registry, event.NewLog("test-gc", false))
+	assert.NoError(t, err, "Orphans should be found")
+	assert.Equal(t, []*Orphan{{Cluster: clusterObj.Name, Name: orphanKey.GetDeployName()}, {Cluster: clusterObj.Name, Name: "another"}}, orphans, "Only orphans should be found")
+
+	// deployments which got referenced since plan aren't deleted, as well as the ones not confirmed by a user
+	actualState.ComponentInstanceMap[orphanKey.GetKey()] = &resolve.ComponentInstance{
+		Metadata:             &resolve.ComponentInstanceMetadata{Key: orphanKey},
+		CalculatedCodeParams: util.NestedParameterMap{lang.LabelCluster: clusterObj.Name},
+	}
+	gcPlugin.deployed["not-confirmed"] = true
+	deleted, err := Delete(context.Background(), b.Policy(), actualState, desiredState, orphans, registry, event.NewLog("test-gc", false))
+	assert.NoError(t, err, "Orphans should be deleted")
+	assert.Equal(t, []*Orphan{{Cluster: clusterObj.Name, Name: "another"}}, deleted, "Only orphans, which are still orphaned, should be deleted")
+	assert.False(t, gcPlugin.deployed["another"], "Orphan should be deleted")
+	assert.True(t, gcPlugin.deployed["not-confirmed"], "Orphan not confirmed by a user should not be deleted")
+	assert.True(t, gcPlugin.deployed[orphanKey.GetDeployName()], "Orphan referenced since plan should not be deleted")
+	for _, deployName := range deployNames {
+		assert.True(t, gcPlugin.deployed[deployName], "Referenced deployment should not be deleted")
+	}
+
+	// deploy plugins, which can't find orphans, are skipped
+	registry.DeployPlugin = &plugin.MockDeployPlugin{}
+	orphans, err = Find(context.Background(), b.Policy(), actualState, desiredState, registry, event.NewLog("test-gc", false))
+	assert.NoError(t, err, "Orphans should be found")
+	assert.Empty(t, orphans, "No orphans should be found")
+}
+
+/*
+	Helpers
+*/
+
+func resolvePolicy(t *testing.T, b *builder.PolicyBuilder) *resolve.PolicyResolution {
+	t.Helper()
+	eventLog := event.NewLog("test-resolve", false)
+	resolver := resolve.NewPolicyResolver(b.Policy(), b.External(), eventLog)
+	result, err := resolver.ResolveAllDependencies()
+	if !assert.NoError(t, err, "Policy should be resolved without errors") {
+		hook := &event.HookConsole{}
+		eventLog.Save(hook)
+		t.FailNow()
+	}
+	return result
+}
+
+// mockGCPlugin is a deploy plugin, which keeps track of deployments (by name, same as deploy name)
+type mockGCPlugin struct {
+	plugin.MockDeployPlugin
+	deployed map[string]bool
+}
+
+func (p *mockGCPlugin) Orphans(ctx context.Context, cluster *lang.Cluster, deployNames []string, eventLog *event.Log) ([]string, error) {
+	known := make(map[string]bool)
+	for _, deployName := range deployNames {
+		known[deployName] = true
+	}
+
+	result := []string{}
+	for name := range p.deployed {
+		if !known[name] {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (p *mockGCPlugin) DestroyOrphan(ctx context.Context, cluster *lang.Cluster, name string, eventLog *event.Log) error {
+	delete(p.deployed, name)
+	return nil
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// LeaseObject is Info for Lease
+var LeaseObject = &runtime.Info{
+	Kind:        "lease",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &Lease{} },
+}
+
+// LeaderLeaseName is the name of the lease held by the Aptomi server replica, which is currently enforcing policy
+const LeaderLeaseName = "leader"
+
+// Lease represents a lease held by one of the Aptomi server replicas, which share the same store. It's valid until
+// it expires and it has to be periodically renewed by its holder. Once lease expired, it could be taken over by
+// any other replica
+type Lease struct {
+	runtime.TypeKind `yaml:",inline"`
+	Metadata         LeaseMetadata
+
+	// Holder is an ID of the Aptomi server replica holding the lease
+	Holder string
+
+	// AcquiredAt is when the lease was acquired by the current holder
+	AcquiredAt time.Time
+
+	// RenewedAt is when the lease was renewed by the current holder last time
+	RenewedAt time.Time
+
+	// ExpiresAt is when the lease expires, unless renewed by the current holder
+	ExpiresAt time.Time
+
+	// Token is a fencing token, which gets incremented every time lease gets acquired by a new holder (or by the same
+	// holder once it expired). Holder could make changes only while the token in the store is the same as the one it
+	// got on acquisition, so changes of a holder, which lost the lease without noticing, are rejected
+	Token uint64
+}
+
+// LeaseMetadata is the metadata for Lease object
+type LeaseMetadata struct {
+	Name string
+}
+
+// GetName returns Lease name
+func (lease *Lease) GetName() string {
+	return lease.Metadata.Name
+}
+
+// GetNamespace returns Lease namespace
+func (lease *Lease) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// IsExpired returns true if lease is expired at the given moment of time
+func (lease *Lease) IsExpired(now time.Time) bool {
+	return !now.Before(lease.ExpiresAt)
+}
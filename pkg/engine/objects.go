@@ -23,6 +23,12 @@ var (
 	Objects = runtime.AppendAll([]*runtime.Info{
 		PolicyDataObject,
 		RevisionObject,
+		RevisionLogObject,
+		LeaseObject,
+		DriftReportObject,
+		StateRebuildObject,
+		GarbageCollectionObject,
+		PolicyChangesObject,
 		resolve.ComponentInstanceObject,
 	}, ActionObjects)
 )
@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// PolicyChangesObject is Info for PolicyChanges
+var PolicyChangesObject = &runtime.Info{
+	Kind:        "policy-changes",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &PolicyChanges{} },
+}
+
+// PolicyChangesName is the name of the PolicyChanges object (there is only one, which gets overwritten on every change)
+const PolicyChangesName = "latest"
+
+// PolicyChangesKey is the key for the PolicyChanges object
+var PolicyChangesKey = runtime.KeyFromParts(runtime.SystemNS, PolicyChangesObject.Kind, PolicyChangesName)
+
+// PolicyChanges represents the latest change, which should be enforced right away (e.g. policy update or approval of
+// revision). It's kept in the store, so enforcer gets notified about changes made through any server replica
+type PolicyChanges struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Counter gets incremented on every change
+	Counter uint64
+
+	// ChangedAt is when the latest change has been made
+	ChangedAt time.Time
+}
+
+// GetName returns PolicyChanges name
+func (changes *PolicyChanges) GetName() string {
+	return PolicyChangesName
+}
+
+// GetNamespace returns PolicyChanges namespace
+func (changes *PolicyChanges) GetNamespace() string {
+	return runtime.SystemNS
+}
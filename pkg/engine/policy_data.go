@@ -84,6 +84,56 @@ func (policyData *PolicyData) Remove(obj lang.Base) bool { // nolint: interfacer
 	return exist
 }
 
+// ForEach calls provided function for each object in PolicyData
+func (policyData *PolicyData) ForEach(fn func(ns string, kind string, name string, gen runtime.Generation)) {
+	for ns, byNs := range policyData.Objects {
+		for kind, byKind := range byNs {
+			for name, gen := range byKind {
+				fn(ns, kind, name, gen)
+			}
+		}
+	}
+}
+
+// GetObjectGeneration returns generation of the object included into PolicyData or false if there is no such object
+func (policyData *PolicyData) GetObjectGeneration(ns string, kind string, name string) (runtime.Generation, bool) {
+	gen, exist := policyData.Objects[ns][kind][name]
+	return gen, exist
+}
+
+// CopyObjects returns a deep copy of the objects map of PolicyData
+func (policyData *PolicyData) CopyObjects() map[string]map[string]map[string]runtime.Generation {
+	result := make(map[string]map[string]map[string]runtime.Generation)
+	policyData.ForEach(func(ns string, kind string, name string, gen runtime.Generation) {
+		if _, exist := result[ns]; !exist {
+			result[ns] = make(map[string]map[string]runtime.Generation)
+		}
+		if _, exist := result[ns][kind]; !exist {
+			result[ns][kind] = make(map[string]runtime.Generation)
+		}
+		result[ns][kind][name] = gen
+	})
+	return result
+}
+
+// Equals returns true if both PolicyData objects contain the same generations of the same objects
+func (policyData *PolicyData) Equals(other *PolicyData) bool {
+	equal := policyData.count() == other.count()
+	policyData.ForEach(func(ns string, kind string, name string, gen runtime.Generation) {
+		otherGen, exist := other.GetObjectGeneration(ns, kind, name)
+		equal = equal && exist && gen == otherGen
+	})
+	return equal
+}
+
+func (policyData *PolicyData) count() int {
+	result := 0
+	policyData.ForEach(func(ns string, kind string, name string, gen runtime.Generation) {
+		result++
+	})
+	return result
+}
+
 // GetDefaultColumns returns default set of columns to be displayed
 func (policyData *PolicyData) GetDefaultColumns() []string {
 	return []string{"Policy Version"}
@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPolicyDataEquals(t *testing.T) {
+	policyData := &PolicyData{
+		Objects: map[string]map[string]map[string]runtime.Generation{
+			"main": {
+				"service":  {"a": 1, "b": 2},
+				"contract": {"c": 1},
+			},
+		},
+	}
+
+	copied := &PolicyData{Objects: policyData.CopyObjects()}
+	assert.True(t, policyData.Equals(copied), "Copy of PolicyData should be equal to the original one")
+
+	copied.Objects["main"]["service"]["a"] = 2
+	assert.False(t, policyData.Equals(copied), "PolicyData with different object generation should not be equal")
+	assert.Equal(t, runtime.Generation(1), policyData.Objects["main"]["service"]["a"], "Original PolicyData should not be modified by changes in the copy")
+
+	copied = &PolicyData{Objects: policyData.CopyObjects()}
+	delete(copied.Objects["main"]["contract"], "c")
+	assert.False(t, policyData.Equals(copied), "PolicyData with removed object should not be equal")
+	assert.False(t, copied.Equals(policyData), "PolicyData with added object should not be equal")
+
+	gen, exist := policyData.GetObjectGeneration("main", "service", "b")
+	assert.True(t, exist, "Object should exist in PolicyData")
+	assert.Equal(t, runtime.Generation(2), gen, "Correct object generation should be returned")
+
+	_, exist = policyData.GetObjectGeneration("main", "service", "missing")
+	assert.False(t, exist, "Missing object should not exist in PolicyData")
+}
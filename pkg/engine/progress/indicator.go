@@ -1,6 +1,9 @@
 package progress
 
-import "math"
+import (
+	"math"
+	"time"
+)
 
 // Indicator is an interface which represents progress bar indicator
 type Indicator interface {
@@ -20,6 +23,16 @@ type Indicator interface {
 	GetCompletionPercent() int
 }
 
+// ActionTracker is an optional interface, which progress indicator could implement to track results of individual
+// actions (identified by their index in the list of actions being applied) in addition to the overall progress
+type ActionTracker interface {
+	// ActionStarted should be called once action starts executing
+	ActionStarted(idx int, startedAt time.Time)
+
+	// ActionFinished should be called once action finishes executing or gets skipped. Error is nil if action succeeded
+	ActionFinished(idx int, finishedAt time.Time, err error, skipped bool)
+}
+
 type progressCount struct {
 	current  int
 	total    int
@@ -0,0 +1,3 @@
+// Package readiness allows Aptomi to keep readiness of deployed component instances in the actual state up to date,
+// as component instances could become ready (or stop being ready) long after they got created or updated.
+package readiness
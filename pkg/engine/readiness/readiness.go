@@ -0,0 +1,105 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/actual"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"sort"
+	"time"
+)
+
+// Refresh checks readiness of all component instances deployed according to the actual state and records it into the
+// actual state. Only deploy plugins implementing plugin.ReadinessPlugin are able to report readiness, component
+// instances deployed by other plugins are skipped, as well as the ones which recently failed. Component instances get
+// saved only if their readiness has changed, so refreshing doesn't result in writes if nothing changed. It returns
+// keys of component instances with changed readiness.
+//
+// Errors while checking a particular component instance are logged and don't stop checking other component
+// instances. Checking stops once the given context is done.
+func Refresh(ctx context.Context, policy *lang.Policy, actualState *resolve.PolicyResolution, updater actual.StateUpdater, plugins plugin.Registry, eventLog *event.Log) ([]string, error) {
+	// go over instances in a sorted order, so the check is always done in the same order
+	keys := []string{}
+	for key := range actualState.ComponentInstanceMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := []string{}
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			eventLog.LogError(fmt.Errorf("readiness check got interrupted: %s", ctx.Err()))
+			break
+		}
+
+		instance := actualState.ComponentInstanceMap[key]
+		ready, checked, err := checkInstance(ctx, policy, instance, plugins, eventLog)
+		if err != nil {
+			eventLog.LogError(fmt.Errorf("error while checking readiness of component instance '%s': %s", key, err))
+			continue
+		}
+		if !checked || (instance.Readiness != nil && instance.Readiness.Ready == ready) {
+			continue
+		}
+
+		instance.Readiness = &resolve.ComponentInstanceReadiness{Ready: ready, CheckedAt: time.Now()}
+		err = updater.Save(instance)
+		if err != nil {
+			return nil, fmt.Errorf("error while saving readiness of component instance '%s': %s", key, err)
+		}
+		result = append(result, key)
+	}
+
+	return result, nil
+}
+
+// checkInstance returns whether component instance is ready. The second returned value is false if readiness can't be
+// checked for the component instance
+func checkInstance(ctx context.Context, policy *lang.Policy, instance *resolve.ComponentInstance, plugins plugin.Registry, eventLog *event.Log) (bool, bool, error) {
+	// component instance isn't supposed to be deployed or its state isn't known for sure
+	if len(instance.DependencyKeys) <= 0 || instance.Failure != nil || !instance.Metadata.Key.IsComponent() {
+		return false, false, nil
+	}
+
+	serviceObj, err := policy.GetObject(lang.ServiceObject.Kind, instance.Metadata.Key.ServiceName, instance.Metadata.Key.Namespace)
+	if err != nil || serviceObj == nil {
+		// service has been removed from the policy, so component instance is going to be deleted anyway
+		return false, false, nil
+	}
+	component := serviceObj.(*lang.Service).GetComponentsMap()[instance.Metadata.Key.ComponentName]
+	if component == nil || component.Code == nil {
+		return false, false, nil
+	}
+
+	deployPlugin, err := plugins.GetDeployPlugin(component.Code.Type)
+	if err != nil {
+		return false, false, err
+	}
+	readinessPlugin, ok := deployPlugin.(plugin.ReadinessPlugin)
+	if !ok {
+		return false, false, nil
+	}
+
+	clusterName, ok := instance.CalculatedCodeParams[lang.LabelCluster].(string)
+	if !ok {
+		return false, false, fmt.Errorf("no cluster specified in code params")
+	}
+	clusterObj, err := policy.GetObject(lang.ClusterObject.Kind, clusterName, runtime.SystemNS)
+	if err != nil {
+		return false, false, err
+	}
+	if clusterObj == nil {
+		return false, false, fmt.Errorf("can't find cluster in policy: %s", clusterName)
+	}
+
+	ready, err := readinessPlugin.Status(ctx, clusterObj.(*lang.Cluster), instance.GetDeployName(), instance.CalculatedCodeParams, eventLog)
+	if err != nil {
+		return false, false, err
+	}
+
+	return ready, true, nil
+}
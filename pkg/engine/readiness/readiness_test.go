@@ -0,0 +1,76 @@
+package readiness
+
+import (
+	"context"
+	"github.com/Aptomi/aptomi/pkg/engine/actual"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/lang/builder"
+	"github.com/Aptomi/aptomi/pkg/plugin"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRefresh(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+	service := b.AddService()
+	web := b.AddServiceComponent(service, b.CodeComponent(util.NestedParameterMap{lang.LabelCluster: "{{ .Labels.cluster }}"}, nil))
+	db := b.AddServiceComponent(service, b.CodeComponent(util.NestedParameterMap{lang.LabelCluster: "{{ .Labels.cluster }}"}, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+	clusterObj := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, clusterObj.Name)))
+	b.AddDependency(b.AddUser(), contract)
+	actualState := resolvePolicy(t, b)
+
+	webKey, dbKey := "", ""
+	for key, instance := range actualState.ComponentInstanceMap {
+		switch instance.Metadata.Key.ComponentName {
+		case web.Name:
+			webKey = key
+		case db.Name:
+			dbKey = key
+		}
+	}
+
+	// web isn't ready yet, while db is
+	readinessPlugin := &plugin.MockDeployPluginFailComponents{NotReadyComponents: []string{web.Name}}
+	registry := &plugin.MockRegistry{DeployPlugin: readinessPlugin, PostProcessPlugin: &plugin.MockPostProcessPlugin{}}
+	changed, err := Refresh(context.Background(), b.Policy(), actualState, actual.NewNoOpActionStateUpdater(), registry, event.NewLog("test-readiness", false))
+	assert.NoError(t, err, "Readiness should be refreshed")
+	assert.ElementsMatch(t, []string{webKey, dbKey}, changed, "Readiness of both component instances should be recorded")
+	assert.False(t, actualState.ComponentInstanceMap[webKey].Readiness.Ready, "Web should not be ready")
+	assert.True(t, actualState.ComponentInstanceMap[dbKey].Readiness.Ready, "Db should be ready")
+
+	// web became ready later on
+	readinessPlugin.NotReadyComponents = nil
+	changed, err = Refresh(context.Background(), b.Policy(), actualState, actual.NewNoOpActionStateUpdater(), registry, event.NewLog("test-readiness", false))
+	assert.NoError(t, err, "Readiness should be refreshed")
+	assert.Equal(t, []string{webKey}, changed, "Only changed readiness should be recorded")
+	assert.True(t, actualState.ComponentInstanceMap[webKey].Readiness.Ready, "Web should become ready")
+
+	// failed component instances are skipped
+	readinessPlugin.NotReadyComponents = []string{web.Name}
+	actualState.ComponentInstanceMap[webKey].Failure = &resolve.ComponentInstanceFailure{Attempts: 1}
+	changed, err = Refresh(context.Background(), b.Policy(), actualState, actual.NewNoOpActionStateUpdater(), registry, event.NewLog("test-readiness", false))
+	assert.NoError(t, err, "Readiness should be refreshed")
+	assert.Empty(t, changed, "Failed component instances should be skipped")
+}
+
+/*
+	Helpers
+*/
+
+func resolvePolicy(t *testing.T, b *builder.PolicyBuilder) *resolve.PolicyResolution {
+	t.Helper()
+	eventLog := event.NewLog("test-resolve", false)
+	resolver := resolve.NewPolicyResolver(b.Policy(), b.External(), eventLog)
+	result, err := resolver.ResolveAllDependencies()
+	if !assert.NoError(t, err, "Policy should be resolved without errors") {
+		hook := &event.HookConsole{}
+		eventLog.Save(hook)
+		t.FailNow()
+	}
+	return result
+}
@@ -57,9 +57,17 @@ type ComponentInstance struct {
 	// CalculatedDiscovery is a set of calculated discovery parameters for the component (non-conflicting over all uses of this component)
 	CalculatedDiscovery util.NestedParameterMap
 
+	// CalculatedDiscoveryMasked is a copy of CalculatedDiscovery with values of all user secrets masked. It's only
+	// set when discovery params refer to user secrets and it should be used whenever discovery params are shown to the user
+	CalculatedDiscoveryMasked util.NestedParameterMap `yaml:",omitempty"`
+
 	// CalculatedCodeParams is a set of calculated code parameters for the component (non-conflicting over all uses of this component)
 	CalculatedCodeParams util.NestedParameterMap
 
+	// CalculatedCodeParamsMasked is a copy of CalculatedCodeParams with values of all user secrets masked. It's only
+	// set when code params refer to user secrets and it should be used whenever code params are shown to the user
+	CalculatedCodeParamsMasked util.NestedParameterMap `yaml:",omitempty"`
+
 	// EdgesIn is a set of incoming graph edges ('key' -> true) into this component instance. Storing for observability and reporting, so we can reconstruct the graph
 	EdgesIn map[string]bool
 
@@ -81,6 +89,18 @@ type ComponentInstance struct {
 
 	// Endpoints represents all URLs that could be used to access deployed service
 	Endpoints map[string]string
+
+	// Readiness represents readiness of the component instance in the cloud. It's not set if deploy plugin doesn't
+	// support readiness checks
+	Readiness *ComponentInstanceReadiness `yaml:",omitempty"`
+
+	// Failure represents failed attempts to apply changes to this component instance. It's not set if the last
+	// attempt succeeded
+	Failure *ComponentInstanceFailure `yaml:",omitempty"`
+
+	// Drift explains how the component instance running in the cloud differs from the actual state. It's only set by
+	// drift detection when drift correction is enabled, so component instance gets updated on the next enforcement
+	Drift string `yaml:",omitempty"`
 }
 
 // Creates a new component instance
@@ -131,38 +151,82 @@ func (instance *ComponentInstance) addRuleInformation(result *lang.RuleActionRes
 	instance.DataForPlugins[AllowIngres] = strconv.FormatBool(!result.RejectIngress)
 }
 
-func (instance *ComponentInstance) addCodeParams(codeParams util.NestedParameterMap) error {
+// GetCodeParamsMasked returns code parameters of the component instance, which are safe to be shown to the user
+// (values of all user secrets are masked)
+func (instance *ComponentInstance) GetCodeParamsMasked() util.NestedParameterMap {
+	if instance.CalculatedCodeParamsMasked != nil {
+		return instance.CalculatedCodeParamsMasked
+	}
+	return instance.CalculatedCodeParams
+}
+
+func (instance *ComponentInstance) addCodeParams(codeParams util.NestedParameterMap, codeParamsMasked util.NestedParameterMap) error {
+	err := instance.checkCodeParams(codeParams, codeParamsMasked)
+	if err != nil {
+		return err
+	}
 	if len(instance.CalculatedCodeParams) == 0 {
 		// Record code parameters
 		instance.CalculatedCodeParams = codeParams
-	} else if !instance.CalculatedCodeParams.DeepEqual(codeParams) {
-		// Same component instance, different code parameters
+		instance.CalculatedCodeParamsMasked = codeParamsMasked
+	}
+	return nil
+}
+
+func (instance *ComponentInstance) checkCodeParams(codeParams util.NestedParameterMap, codeParamsMasked util.NestedParameterMap) error {
+	if len(instance.CalculatedCodeParams) > 0 && !instance.CalculatedCodeParams.DeepEqual(codeParams) {
+		// Same component instance, different code parameters (make sure secrets don't get into error details)
+		if codeParamsMasked == nil {
+			codeParamsMasked = codeParams
+		}
 		return errors.NewErrorWithDetails(
 			fmt.Sprintf("Invalid policy. Conflicting code parameters for component instance: %s", instance.GetKey()),
 			errors.Details{
 				"instance":             instance.Metadata.Key,
-				"code_params_existing": instance.CalculatedCodeParams,
-				"code_params_new":      codeParams,
-				"diff":                 instance.CalculatedCodeParams.Diff(codeParams),
+				"code_params_existing": instance.GetCodeParamsMasked(),
+				"code_params_new":      codeParamsMasked,
+				"diff":                 instance.GetCodeParamsMasked().Diff(codeParamsMasked),
 			},
 		)
 	}
 	return nil
 }
 
-func (instance *ComponentInstance) addDiscoveryParams(discoveryParams util.NestedParameterMap) error {
+// GetDiscoveryMasked returns discovery parameters of the component instance, which are safe to be shown to the user
+// (values of all user secrets are masked)
+func (instance *ComponentInstance) GetDiscoveryMasked() util.NestedParameterMap {
+	if instance.CalculatedDiscoveryMasked != nil {
+		return instance.CalculatedDiscoveryMasked
+	}
+	return instance.CalculatedDiscovery
+}
+
+func (instance *ComponentInstance) addDiscoveryParams(discoveryParams util.NestedParameterMap, discoveryParamsMasked util.NestedParameterMap) error {
+	err := instance.checkDiscoveryParams(discoveryParams, discoveryParamsMasked)
+	if err != nil {
+		return err
+	}
 	if len(instance.CalculatedDiscovery) == 0 {
 		// Record discovery parameters
 		instance.CalculatedDiscovery = discoveryParams
-	} else if !instance.CalculatedDiscovery.DeepEqual(discoveryParams) {
-		// Same component instance, different discovery parameters
+		instance.CalculatedDiscoveryMasked = discoveryParamsMasked
+	}
+	return nil
+}
+
+func (instance *ComponentInstance) checkDiscoveryParams(discoveryParams util.NestedParameterMap, discoveryParamsMasked util.NestedParameterMap) error {
+	if len(instance.CalculatedDiscovery) > 0 && !instance.CalculatedDiscovery.DeepEqual(discoveryParams) {
+		// Same component instance, different discovery parameters (make sure secrets don't get into error details)
+		if discoveryParamsMasked == nil {
+			discoveryParamsMasked = discoveryParams
+		}
 		return errors.NewErrorWithDetails(
 			fmt.Sprintf("Invalid policy. Conflicting discovery parameters for component instance: %s", instance.GetKey()),
 			errors.Details{
 				"instance":                  instance.Metadata.Key,
-				"discovery_params_existing": instance.CalculatedDiscovery,
-				"discovery_params_new":      discoveryParams,
-				"diff":                      instance.CalculatedDiscovery.Diff(discoveryParams),
+				"discovery_params_existing": instance.GetDiscoveryMasked(),
+				"discovery_params_new":      discoveryParamsMasked,
+				"diff":                      instance.GetDiscoveryMasked().Diff(discoveryParamsMasked),
 			},
 		)
 	}
@@ -202,12 +266,12 @@ func (instance *ComponentInstance) appendData(ops *ComponentInstance) error {
 	instance.addLabels(ops.CalculatedLabels)
 
 	// Combine code params and discovery params for
-	var err = instance.addDiscoveryParams(ops.CalculatedDiscovery)
+	var err = instance.addDiscoveryParams(ops.CalculatedDiscovery, ops.CalculatedDiscoveryMasked)
 	if err != nil {
 		return err
 	}
 
-	err = instance.addCodeParams(ops.CalculatedCodeParams)
+	err = instance.addCodeParams(ops.CalculatedCodeParams, ops.CalculatedCodeParamsMasked)
 	if err != nil {
 		return err
 	}
@@ -228,6 +292,40 @@ func (instance *ComponentInstance) appendData(ops *ComponentInstance) error {
 	return nil
 }
 
+// checkAppendData checks whether data could be appended without conflicts (see appendData)
+func (instance *ComponentInstance) checkAppendData(ops *ComponentInstance) error {
+	err := instance.checkDiscoveryParams(ops.CalculatedDiscovery, ops.CalculatedDiscoveryMasked)
+	if err != nil {
+		return err
+	}
+	return instance.checkCodeParams(ops.CalculatedCodeParams, ops.CalculatedCodeParamsMasked)
+}
+
+// CopyWithoutDependencies returns a copy of component instance with all its data, except dependency keys
+func (instance *ComponentInstance) CopyWithoutDependencies() *ComponentInstance {
+	result := newComponentInstance(instance.Metadata.Key)
+	if instance.CalculatedLabels != nil {
+		result.addLabels(instance.CalculatedLabels)
+	}
+	result.CalculatedDiscovery = instance.CalculatedDiscovery
+	result.CalculatedDiscoveryMasked = instance.CalculatedDiscoveryMasked
+	result.CalculatedCodeParams = instance.CalculatedCodeParams
+	result.CalculatedCodeParamsMasked = instance.CalculatedCodeParamsMasked
+	for key := range instance.EdgesIn {
+		result.addEdgeIn(key)
+	}
+	for key := range instance.EdgesOut {
+		result.addEdgeOut(key)
+	}
+	for k, v := range instance.DataForPlugins {
+		result.DataForPlugins[k] = v
+	}
+	result.UpdateTimes(instance.CreatedAt, instance.UpdatedAt)
+	result.Endpoints = instance.Endpoints
+	result.Readiness = instance.Readiness
+	return result
+}
+
 // KeyForComponentKey returns object key by provided component instance key
 func KeyForComponentKey(componentKey string) string {
 	return runtime.KeyFromParts(runtime.SystemNS, ComponentInstanceObject.Kind, componentKey)
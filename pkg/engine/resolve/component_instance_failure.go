@@ -0,0 +1,65 @@
+package resolve
+
+import (
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Aptomi/aptomi/pkg/util/retry"
+	"time"
+)
+
+// ComponentInstanceFailure represents failed attempts to apply changes to the component instance in the cloud. It's
+// used to retry failed changes with exponential backoff and to stop retrying once the component failed too many times,
+// until its code params change
+type ComponentInstanceFailure struct {
+	// LastError is an error of the last failed attempt
+	LastError string
+
+	// Attempts is a number of failed attempts in a row
+	Attempts int
+
+	// LastAttemptAt is when the last failed attempt happened
+	LastAttemptAt time.Time
+
+	// NextRetryAt is when the next attempt could be made. It's not set if the component instance failed too many
+	// times and it will not be retried until its code params change
+	NextRetryAt time.Time `yaml:",omitempty"`
+
+	// CodeParamsHash is a hash of the code params the last attempt failed with (params themselves are not stored, as
+	// they may contain user secrets)
+	CodeParamsHash string
+}
+
+// IsStuck returns true if the component instance failed too many times and it will not be retried until its code
+// params change
+func (failure *ComponentInstanceFailure) IsStuck() bool {
+	return failure.NextRetryAt.IsZero()
+}
+
+// CanRetry returns true if changes with the given code params could be applied to the component instance at the given
+// moment of time. Changes are always allowed if code params are different from the ones the last attempt failed with
+func (failure *ComponentInstanceFailure) CanRetry(codeParams util.NestedParameterMap, now time.Time) bool {
+	if failure.CodeParamsHash != util.HashObject(codeParams) {
+		return true
+	}
+	return !failure.IsStuck() && !now.Before(failure.NextRetryAt)
+}
+
+// RecordFailure records failed attempt to apply changes with the given code params to the component instance and
+// schedules the next attempt according to the given backoff
+func (instance *ComponentInstance) RecordFailure(err error, codeParams util.NestedParameterMap, backoff retry.Backoff, now time.Time) {
+	codeParamsHash := util.HashObject(codeParams)
+
+	attempts := 1
+	if instance.Failure != nil && instance.Failure.CodeParamsHash == codeParamsHash {
+		attempts = instance.Failure.Attempts + 1
+	}
+
+	instance.Failure = &ComponentInstanceFailure{
+		LastError:      err.Error(),
+		Attempts:       attempts,
+		LastAttemptAt:  now,
+		CodeParamsHash: codeParamsHash,
+	}
+	if !backoff.Exhausted(attempts) {
+		instance.Failure.NextRetryAt = now.Add(backoff.Delay(attempts))
+	}
+}
@@ -0,0 +1,33 @@
+package resolve
+
+import (
+	"time"
+)
+
+// ComponentInstanceReadiness represents readiness of the component instance deployed in the cloud, as reported by
+// deploy plugin after the component instance got created or updated. It's refreshed by enforcer afterwards
+type ComponentInstanceReadiness struct {
+	// Ready is true if component instance is ready (e.g. all its pods are up and running)
+	Ready bool
+
+	// CheckedAt is when readiness was checked for the last time (readiness is only saved once it changes)
+	CheckedAt time.Time
+
+	// TimedOut is true if component instance didn't become ready within the timeout, so its dependents were processed
+	// without waiting for it any longer
+	TimedOut bool `yaml:",omitempty"`
+}
+
+// String returns human-readable representation of component instance readiness
+func (readiness *ComponentInstanceReadiness) String() string {
+	if readiness == nil {
+		return "Unknown"
+	}
+	if readiness.Ready {
+		return "Ready"
+	}
+	if readiness.TimedOut {
+		return "Not Ready (timed out)"
+	}
+	return "Not Ready"
+}
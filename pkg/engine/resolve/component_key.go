@@ -85,6 +85,34 @@ func (cik *ComponentInstanceKey) GetParentServiceKey() *ComponentInstanceKey {
 	return serviceCik
 }
 
+// GetSiblingDependencies returns keys of component instances within the same service instance, which the component
+// instance depends on according to the given policy. It returns false if the component is not present in the policy,
+// so its dependencies are unknown
+func (cik *ComponentInstanceKey) GetSiblingDependencies(policy *lang.Policy) ([]string, bool) {
+	if !cik.IsComponent() || policy == nil {
+		return nil, false
+	}
+
+	serviceObj, err := policy.GetObject(lang.ServiceObject.Kind, cik.ServiceName, cik.Namespace)
+	if err != nil || serviceObj == nil {
+		return nil, false
+	}
+
+	component := serviceObj.(*lang.Service).GetComponentsMap()[cik.ComponentName]
+	if component == nil {
+		return nil, false
+	}
+
+	result := []string{}
+	for _, dependency := range component.Dependencies {
+		siblingKey := cik.MakeCopy()
+		siblingKey.ComponentName = dependency
+		result = append(result, siblingKey.GetKey())
+	}
+
+	return result, true
+}
+
 // GetKey returns a string key
 func (cik ComponentInstanceKey) GetKey() string {
 	if cik.key == "" {
@@ -0,0 +1,198 @@
+package resolve
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"sort"
+	"strings"
+)
+
+// DependencyExplanationObject is an informational data structure with Kind and Constructor for DependencyExplanation
+var DependencyExplanationObject = &runtime.Info{
+	Kind:        "dependency-explanation",
+	Constructor: func() runtime.Object { return &DependencyExplanation{} },
+}
+
+// DependencyExplanation is a structured trace of how policy resolver processed a single dependency. It explains why
+// a dependency resolved the way it did: which contexts were tested, which rules were applied, which allocation keys
+// and component instances were produced
+type DependencyExplanation struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Dependency is a key of the dependency being explained
+	Dependency string
+
+	// User is a name of the user who requested the dependency
+	User string
+
+	// Resolved is true if dependency has been successfully resolved
+	Resolved bool
+
+	// ServiceKey is a key of the top-level service instance, which dependency got resolved into
+	ServiceKey string `yaml:",omitempty"`
+
+	// Error is set if dependency can't be resolved due to an error
+	Error string `yaml:",omitempty"`
+
+	// Contracts contain trace for every contract processed during resolution (top-level contract first, followed by
+	// the contracts which service components depend on, in the order of processing)
+	Contracts []*ContractExplanation
+}
+
+// ContractExplanation is a trace of how a single contract got processed during dependency resolution
+type ContractExplanation struct {
+	// Depth is a depth in the resolution tree, with contract from the dependency being on depth 0
+	Depth int
+
+	// Contract is a namespace/name of the contract
+	Contract string
+
+	// Labels are the labels contract processing started with
+	Labels map[string]string
+
+	// Contexts contain result of testing context criteria, in the order of testing
+	Contexts []*ContextExplanation
+
+	// Context is the name of the matched context
+	Context string `yaml:",omitempty"`
+
+	// Service is the name of the service allocated by the matched context
+	Service string `yaml:",omitempty"`
+
+	// AllocationKeys are the resolved allocation keys of the matched context
+	AllocationKeys []string `yaml:",omitempty"`
+
+	// Rules contain result of testing rules, in the order of processing
+	Rules []*RuleExplanation
+
+	// ServiceKey is a key of the resulting service instance
+	ServiceKey string `yaml:",omitempty"`
+
+	// ComponentKeys are the keys of successfully resolved component instances
+	ComponentKeys []string `yaml:",omitempty"`
+
+	// Error is set if contract processing stopped due to an error
+	Error string `yaml:",omitempty"`
+}
+
+// ContextExplanation is a result of testing criteria of a single context
+type ContextExplanation struct {
+	Name     string
+	Criteria *lang.Criteria `yaml:",omitempty"`
+	Matched  bool
+}
+
+// RuleExplanation is a result of testing and applying a single rule
+type RuleExplanation struct {
+	Namespace string
+	Name      string
+	Matched   bool
+
+	// RejectDependency is true if rule rejected the dependency
+	RejectDependency bool `yaml:",omitempty"`
+
+	// LabelChanges are the changes made to labels by the rule actions
+	LabelChanges []*LabelChange `yaml:",omitempty"`
+}
+
+// LabelChange represents change of a single label. Before is empty for added labels and After is empty for
+// removed labels
+type LabelChange struct {
+	Name   string
+	Before string `yaml:",omitempty"`
+	After  string `yaml:",omitempty"`
+}
+
+// String returns human-readable representation of the label change
+func (change *LabelChange) String() string {
+	if len(change.Before) == 0 {
+		return fmt.Sprintf("+%s=%s", change.Name, change.After)
+	}
+	if len(change.After) == 0 {
+		return fmt.Sprintf("-%s", change.Name)
+	}
+	return fmt.Sprintf("%s: %s -> %s", change.Name, change.Before, change.After)
+}
+
+// newDependencyExplanation creates a new empty explanation for the given dependency
+func newDependencyExplanation(d *lang.Dependency) *DependencyExplanation {
+	return &DependencyExplanation{
+		TypeKind:   DependencyExplanationObject.GetTypeKind(),
+		Dependency: runtime.KeyForStorable(d),
+		User:       d.User,
+		Contracts:  []*ContractExplanation{},
+	}
+}
+
+// diffLabels returns sorted list of changes between two sets of labels
+func diffLabels(before map[string]string, after map[string]string) []*LabelChange {
+	result := []*LabelChange{}
+	for name, value := range after {
+		if valueBefore, exist := before[name]; !exist || valueBefore != value {
+			result = append(result, &LabelChange{Name: name, Before: valueBefore, After: value})
+		}
+	}
+	for name, value := range before {
+		if _, exist := after[name]; !exist {
+			result = append(result, &LabelChange{Name: name, Before: value})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (explanation *DependencyExplanation) GetDefaultColumns() []string {
+	return []string{"Dependency", "Resolved", "Trace"}
+}
+
+// AsColumns returns DependencyExplanation representation as columns
+func (explanation *DependencyExplanation) AsColumns() map[string]string {
+	trace := []string{}
+	for _, contract := range explanation.Contracts {
+		indent := strings.Repeat("  ", contract.Depth)
+		trace = append(trace, fmt.Sprintf("%scontract %s", indent, contract.Contract))
+		for _, context := range contract.Contexts {
+			trace = append(trace, fmt.Sprintf("%s  context %s: matched = %t", indent, context.Name, context.Matched))
+		}
+		if len(contract.Service) > 0 {
+			trace = append(trace, fmt.Sprintf("%s  service %s (allocation keys: %s)", indent, contract.Service, contract.AllocationKeys))
+		}
+		for _, rule := range contract.Rules {
+			if !rule.Matched {
+				continue
+			}
+			line := fmt.Sprintf("%s  rule %s/%s applied", indent, rule.Namespace, rule.Name)
+			if rule.RejectDependency {
+				line += ", dependency rejected"
+			}
+			for _, change := range rule.LabelChanges {
+				line += ", " + change.String()
+			}
+			trace = append(trace, line)
+		}
+		for _, key := range contract.ComponentKeys {
+			trace = append(trace, fmt.Sprintf("%s  component instance %s", indent, key))
+		}
+		if len(contract.ServiceKey) > 0 {
+			trace = append(trace, fmt.Sprintf("%s  service instance %s", indent, contract.ServiceKey))
+		}
+		if len(contract.Error) > 0 {
+			trace = append(trace, fmt.Sprintf("%s  error: %s", indent, contract.Error))
+		}
+	}
+
+	resolved := fmt.Sprintf("%t", explanation.Resolved)
+	if len(explanation.Error) > 0 {
+		resolved = "error: " + explanation.Error
+	}
+
+	return map[string]string{
+		"Dependency": explanation.Dependency,
+		"Resolved":   resolved,
+		"Trace":      strings.Join(trace, "\n"),
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
+	"sort"
 )
 
 // PolicyResolution contains resolution data for the policy. It essentially represents the desired state calculated
@@ -22,6 +23,9 @@ type PolicyResolution struct {
 	// Resolved dependencies: dependencyID -> serviceKey
 	dependencyInstanceMap map[string]string
 
+	// Dependencies, which failed to resolve due to errors: dependencyID -> error
+	dependencyErrors map[string]string
+
 	// Resolved component processing order in which components/services have to be processed
 	componentProcessingOrderHas map[string]bool
 	componentProcessingOrder    []string
@@ -34,6 +38,7 @@ func NewPolicyResolution(isDesired bool) *PolicyResolution {
 		isDesired:                   isDesired,
 		ComponentInstanceMap:        make(map[string]*ComponentInstance),
 		dependencyInstanceMap:       make(map[string]string),
+		dependencyErrors:            make(map[string]string),
 		componentProcessingOrderHas: make(map[string]bool),
 		componentProcessingOrder:    []string{},
 	}
@@ -65,14 +70,16 @@ func (resolution *PolicyResolution) recordProcessingOrder(cik *ComponentInstance
 	}
 }
 
-// RecordCodeParams stores calculated code params for component instance
-func (resolution *PolicyResolution) RecordCodeParams(cik *ComponentInstanceKey, codeParams util.NestedParameterMap) error {
-	return resolution.GetComponentInstanceEntry(cik).addCodeParams(codeParams)
+// RecordCodeParams stores calculated code params for component instance. Masked code params (with all user
+// secrets masked) should be passed if they are different from code params, otherwise nil
+func (resolution *PolicyResolution) RecordCodeParams(cik *ComponentInstanceKey, codeParams util.NestedParameterMap, codeParamsMasked util.NestedParameterMap) error {
+	return resolution.GetComponentInstanceEntry(cik).addCodeParams(codeParams, codeParamsMasked)
 }
 
-// RecordDiscoveryParams stores calculated discovery params for component instance
-func (resolution *PolicyResolution) RecordDiscoveryParams(cik *ComponentInstanceKey, discoveryParams util.NestedParameterMap) error {
-	return resolution.GetComponentInstanceEntry(cik).addDiscoveryParams(discoveryParams)
+// RecordDiscoveryParams stores calculated discovery params for component instance. Masked discovery params (with all
+// user secrets masked) should be nil if discovery params don't refer to user secrets
+func (resolution *PolicyResolution) RecordDiscoveryParams(cik *ComponentInstanceKey, discoveryParams util.NestedParameterMap, discoveryParamsMasked util.NestedParameterMap) error {
+	return resolution.GetComponentInstanceEntry(cik).addDiscoveryParams(discoveryParams, discoveryParamsMasked)
 }
 
 // RecordLabels stores calculated labels for component instance
@@ -89,15 +96,27 @@ func (resolution *PolicyResolution) StoreEdge(src *ComponentInstanceKey, dst *Co
 	}
 }
 
-// AppendData appends data to the current PolicyResolution record by aggregating data over component instances
+// AppendData appends data to the current PolicyResolution record by aggregating data over component instances.
+// If data can't be appended due to conflicts, error is returned and the current PolicyResolution is left unchanged
 func (resolution *PolicyResolution) AppendData(ops *PolicyResolution) error {
+	// check for conflicts first, so data doesn't get appended partially
+	for key, instance := range ops.ComponentInstanceMap {
+		if existing, ok := resolution.ComponentInstanceMap[key]; ok {
+			err := existing.checkAppendData(instance)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	for _, instance := range ops.ComponentInstanceMap {
 		err := resolution.GetComponentInstanceEntry(instance.Metadata.Key).appendData(instance)
 		if err != nil {
 			return err
 		}
 	}
-	for key := range ops.ComponentInstanceMap {
+	// preserve processing order of appended data, as map iteration order is random
+	for _, key := range ops.componentProcessingOrder {
 		resolution.recordProcessingOrder(ops.ComponentInstanceMap[key].Metadata.Key)
 	}
 	return nil
@@ -126,6 +145,64 @@ func (resolution *PolicyResolution) SetDependencyInstanceMap(dMap map[string]str
 	resolution.dependencyInstanceMap = dMap
 }
 
+// GetDependencyErrors returns map for dependencies, which failed to resolve due to errors: dependencyID -> error.
+// It may only be non-empty if policy has been resolved partially (see PolicyResolver.ResolveAllDependenciesPartially)
+func (resolution *PolicyResolution) GetDependencyErrors() map[string]string {
+	if !resolution.isDesired {
+		panic("attempting to get dependency errors for actual state")
+	}
+	return resolution.dependencyErrors
+}
+
+// Records an error for dependency, which failed to resolve
+func (resolution *PolicyResolution) recordDependencyError(dependencyID string, err error) {
+	resolution.dependencyErrors[dependencyID] = err.Error()
+}
+
+// PreserveFailedDependencies takes component instances used by failed dependencies from the actual state and
+// puts them into the current PolicyResolution (desired state), so they will be left untouched when desired state
+// gets applied. Otherwise, a single failed dependency would result in deletion of all its previously deployed
+// component instances
+func (resolution *PolicyResolution) PreserveFailedDependencies(actualState *PolicyResolution) {
+	if len(resolution.dependencyErrors) <= 0 {
+		return
+	}
+
+	// go over instances in a sorted order, so the processing order is always the same
+	keys := []string{}
+	for key := range actualState.ComponentInstanceMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		instanceActual := actualState.ComponentInstanceMap[key]
+
+		// see if instance is used by any of failed dependencies
+		failedDependencyKeys := []string{}
+		for dependencyKey := range instanceActual.DependencyKeys {
+			if _, failed := resolution.dependencyErrors[dependencyKey]; failed {
+				failedDependencyKeys = append(failedDependencyKeys, dependencyKey)
+			}
+		}
+		if len(failedDependencyKeys) <= 0 {
+			continue
+		}
+
+		// if instance isn't present in desired state, copy it from actual state as is (but only with failed dependencies)
+		instance, exists := resolution.ComponentInstanceMap[key]
+		if !exists {
+			instance = instanceActual.CopyWithoutDependencies()
+			resolution.ComponentInstanceMap[key] = instance
+		}
+
+		for _, dependencyKey := range failedDependencyKeys {
+			instance.addDependency(dependencyKey)
+		}
+		resolution.recordProcessingOrder(instance.Metadata.Key)
+	}
+}
+
 // Validate checks that the state is valid, meaning that all objects references are valid. It takes all the instances
 // and verifies that all services exist, all clusters exist, etc
 func (resolution *PolicyResolution) Validate(policy *lang.Policy) error {
@@ -73,6 +73,18 @@ func NewPolicyResolver(policy *lang.Policy, externalData *external.Data, eventLo
 // which component have to be allocated and with which parameters. Once PolicyResolution (desired state) is calculated,
 // it can be rendered by the engine diff/apply by deploying/configuring required components/containers in the cloud.
 func (resolver *PolicyResolver) ResolveAllDependencies() (*PolicyResolution, error) {
+	return resolver.resolveAllDependencies(false)
+}
+
+// ResolveAllDependenciesPartially works the same way as ResolveAllDependencies, but it doesn't fail policy resolution
+// when some of the dependencies can't be resolved due to errors. Such dependencies get recorded in PolicyResolution
+// along with their errors (see PolicyResolution.GetDependencyErrors), while all remaining dependencies get resolved
+// as usual. It allows to keep enforcing policy for everyone else, when a single dependency is broken
+func (resolver *PolicyResolver) ResolveAllDependenciesPartially() (*PolicyResolution, error) {
+	return resolver.resolveAllDependencies(true)
+}
+
+func (resolver *PolicyResolver) resolveAllDependencies(partial bool) (*PolicyResolution, error) {
 	// Run policy validation before resolution, just in case
 	err := resolver.policy.Validate()
 	if err != nil {
@@ -89,8 +101,8 @@ func (resolver *PolicyResolver) ResolveAllDependencies() (*PolicyResolution, err
 		// resolve dependency via applying policy
 		semaphore <- 1
 		go func(d *lang.Dependency) {
-			node, resolveErr := resolver.resolveDependency(d)
-			errs <- resolver.combineData(node, resolveErr)
+			node, resolveErr := resolver.resolveDependency(d, nil)
+			errs <- resolver.combineData(d, node, resolveErr)
 			<-semaphore
 		}(d.(*lang.Dependency))
 	}
@@ -104,8 +116,8 @@ func (resolver *PolicyResolver) ResolveAllDependencies() (*PolicyResolution, err
 		}
 	}
 
-	// See if there were any errors
-	if errFound > 0 {
+	// See if there were any errors (in partial mode they are already recorded in the resolution)
+	if errFound > 0 && !partial {
 		return nil, fmt.Errorf("errors occurred during policy resolution: %d", errFound)
 	}
 
@@ -126,10 +138,10 @@ func (resolver *PolicyResolver) ResolveAllDependencies() (*PolicyResolution, err
 	return resolver.resolution, nil
 }
 
-// Resolves a single dependency
-func (resolver *PolicyResolver) resolveDependency(d *lang.Dependency) (node *resolutionNode, resolveErr error) {
+// Resolves a single dependency. If explanation is not nil, structured trace of the resolution will be recorded into it
+func (resolver *PolicyResolver) resolveDependency(d *lang.Dependency, explanation *DependencyExplanation) (node *resolutionNode, resolveErr error) {
 	// create new resolution node
-	node = resolver.newResolutionNode()
+	node = resolver.newResolutionNode(explanation)
 
 	// make sure we are converting panics into errors
 	defer func() {
@@ -147,8 +159,35 @@ func (resolver *PolicyResolver) resolveDependency(d *lang.Dependency) (node *res
 	return node, resolveErr
 }
 
-// Combines resolution data into the overall state of the world
-func (resolver *PolicyResolver) combineData(node *resolutionNode, resolutionErr error) error {
+// ExplainDependency resolves a single dependency and returns structured trace of its resolution, explaining why
+// the dependency resolved the way it did (contexts tested, rules applied, allocation keys and resulting instances).
+// Errors which occurred during resolution of the dependency are recorded in the explanation, while returned error
+// indicates that the dependency couldn't be explained at all (e.g. policy is invalid)
+func (resolver *PolicyResolver) ExplainDependency(d *lang.Dependency) (*DependencyExplanation, error) {
+	// Run policy validation before resolution, just in case
+	err := resolver.policy.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := newDependencyExplanation(d)
+	node, resolveErr := resolver.resolveDependency(d, explanation)
+	if node != nil {
+		for _, eventLog := range node.eventLogsCombined {
+			resolver.eventLog.Append(eventLog)
+		}
+	}
+	if resolveErr != nil {
+		explanation.Error = resolveErr.Error()
+	}
+	explanation.Resolved = resolveErr == nil && node.resolved
+
+	return explanation, nil
+}
+
+// Combines resolution data into the overall state of the world. If dependency can't be resolved due to an error,
+// error gets recorded for the dependency and returned
+func (resolver *PolicyResolver) combineData(d *lang.Dependency, node *resolutionNode, resolutionErr error) (combineErr error) {
 	// put a lock
 	resolver.combineMutex.Lock()
 
@@ -159,6 +198,11 @@ func (resolver *PolicyResolver) combineData(node *resolutionNode, resolutionErr
 				resolver.eventLog.Append(eventLog)
 			}
 		}
+
+		// record dependency error, so resolution could proceed for the remaining dependencies in partial mode
+		if combineErr != nil {
+			resolver.resolution.recordDependencyError(runtime.KeyForStorable(d), combineErr)
+		}
 		resolver.combineMutex.Unlock()
 	}()
 
@@ -177,9 +221,6 @@ func (resolver *PolicyResolver) combineData(node *resolutionNode, resolutionErr
 		return nil
 	}
 
-	// add a record for dependency resolution
-	resolver.resolution.dependencyInstanceMap[runtime.KeyForStorable(node.dependency)] = node.serviceKey.GetKey()
-
 	// append component instance data
 	err := resolver.resolution.AppendData(node.resolution)
 	if err != nil {
@@ -187,6 +228,9 @@ func (resolver *PolicyResolver) combineData(node *resolutionNode, resolutionErr
 		return err
 	}
 
+	// add a record for dependency resolution
+	resolver.resolution.dependencyInstanceMap[runtime.KeyForStorable(node.dependency)] = node.serviceKey.GetKey()
+
 	return nil
 }
 
@@ -201,6 +245,7 @@ func (resolver *PolicyResolver) resolveNode(node *resolutionNode) error {
 	// Indicate that we are starting to resolve dependency
 	node.objectResolved(node.dependency)
 	node.logStartResolvingDependency()
+	node.explainStartResolvingContract()
 
 	// Locate the user
 	err = node.checkUserExists()
@@ -303,6 +348,7 @@ func (resolver *PolicyResolver) resolveNode(node *resolutionNode) error {
 
 		// Create new map with resolution keys for component
 		node.discoveryTreeNode[node.component.Name] = util.NestedParameterMap{}
+		node.discoveryTreeNodeMasked[node.component.Name] = util.NestedParameterMap{}
 
 		// Calculate and store discovery params
 		err := node.calculateAndStoreDiscoveryParams()
@@ -342,12 +388,14 @@ func (resolver *PolicyResolver) resolveNode(node *resolutionNode) error {
 
 		// Record usage of a given component instance
 		node.logInstanceSuccessfullyResolved(node.componentKey)
+		node.explainInstanceResolved(node.componentKey)
 		node.resolution.RecordResolved(node.componentKey, node.dependency, ruleResult)
 	}
 
 	// Mark note as resolved and record usage of a given service instance
 	node.resolved = true
 	node.logInstanceSuccessfullyResolved(node.serviceKey)
+	node.explainInstanceResolved(node.serviceKey)
 	node.resolution.RecordResolved(node.serviceKey, node.dependency, ruleResult)
 
 	return nil
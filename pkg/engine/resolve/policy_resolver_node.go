@@ -54,6 +54,9 @@ type resolutionNode struct {
 	// component1...component2...component3 -> component instance key
 	discoveryTreeNode util.NestedParameterMap
 
+	// the same node in discovery tree, but with values of all user secrets masked (safe to be shown to the user)
+	discoveryTreeNodeMasked util.NestedParameterMap
+
 	// reference to the current component in discovery tree
 	component *lang.ServiceComponent
 
@@ -68,10 +71,16 @@ type resolutionNode struct {
 
 	// path that we traveled so far (to detect cycles)
 	path []string
+
+	// explanation of the dependency resolution (only set when dependency is being explained)
+	explanation *DependencyExplanation
+
+	// trace of the current contract processing within the explanation
+	trace *ContractExplanation
 }
 
-// Creates a new empty resolution node
-func (resolver *PolicyResolver) newResolutionNode() *resolutionNode {
+// Creates a new empty resolution node. If explanation is not nil, structured trace of the resolution will be recorded into it
+func (resolver *PolicyResolver) newResolutionNode(explanation *DependencyExplanation) *resolutionNode {
 	eventLog := event.NewLog(resolver.eventLog.GetScope(), false)
 	return &resolutionNode{
 		resolved: false,
@@ -85,10 +94,13 @@ func (resolver *PolicyResolver) newResolutionNode() *resolutionNode {
 		depth: 0,
 
 		// empty discovery tree
-		discoveryTreeNode: util.NestedParameterMap{},
+		discoveryTreeNode:       util.NestedParameterMap{},
+		discoveryTreeNodeMasked: util.NestedParameterMap{},
 
 		// empty path
 		path: []string{},
+
+		explanation: explanation,
 	}
 }
 
@@ -135,13 +147,17 @@ func (node *resolutionNode) createChildNode() *resolutionNode {
 		labels: lang.NewLabelSet(node.labels.Labels),
 
 		// move further by the discovery tree via component name link
-		discoveryTreeNode: node.discoveryTreeNode.GetNestedMap(node.component.Name),
+		discoveryTreeNode:       node.discoveryTreeNode.GetNestedMap(node.component.Name),
+		discoveryTreeNodeMasked: node.discoveryTreeNodeMasked.GetNestedMap(node.component.Name),
 
 		// remember the last arrival key
 		arrivalKey: node.componentKey,
 
 		// copy path
 		path: util.CopySliceOfStrings(node.path),
+
+		// keep recording into the same explanation
+		explanation: node.explanation,
 	}
 }
 
@@ -171,6 +187,7 @@ func (node *resolutionNode) cannotResolveInstance(err error) error {
 
 	// Log that service or component instance cannot be resolved
 	node.logCannotResolveInstance()
+	node.explainCannotResolveInstance(err)
 
 	// If it's a critical error, return it
 	if isCriticalError {
@@ -208,6 +225,7 @@ func (node *resolutionNode) getContract(policy *lang.Policy) *lang.Contract {
 	}
 	contract := contractObj.(*lang.Contract)
 	node.logContractFound(contract)
+	node.explainContractFound(contract)
 	return contract
 }
 
@@ -227,6 +245,7 @@ func (node *resolutionNode) getMatchedContext(policy *lang.Policy) (*lang.Contex
 			return nil, node.errorWhenTestingContext(context, err)
 		}
 		node.logTestedContextCriteria(context, matched)
+		node.explainTestedContextCriteria(context, matched)
 		if matched {
 			contextMatched = context
 			break
@@ -264,6 +283,7 @@ func (node *resolutionNode) getMatchedService(policy *lang.Policy) (*lang.Servic
 	}
 
 	node.logServiceFound(service)
+	node.explainServiceFound(service)
 	return service, nil
 }
 
@@ -276,6 +296,7 @@ func (node *resolutionNode) resolveAllocationKeys(policy *lang.Policy) ([]string
 	}
 
 	node.logAllocationKeysSuccessfullyResolved(result)
+	node.explainAllocationKeysResolved(result)
 	return result, nil
 }
 
@@ -320,7 +341,9 @@ func (node *resolutionNode) processRulesWithinNamespace(policyNamespace *lang.Po
 		}
 		node.logTestedRuleMatch(rule, matched)
 		if matched {
+			labelsBefore := node.labelsBeforeRule(result)
 			rule.ApplyActions(result)
+			node.explainTestedRule(policyNamespace, rule, matched, labelsBefore, result)
 
 			// if a dependency has been rejected, handle it right away and return that we cannot resolve it
 			if result.RejectDependency {
@@ -329,6 +352,8 @@ func (node *resolutionNode) processRulesWithinNamespace(policyNamespace *lang.Po
 			if result.ChangedLabelsOnLastApply {
 				node.logLabels(result.Labels, "after transform")
 			}
+		} else {
+			node.explainTestedRule(policyNamespace, rule, matched, nil, result)
 		}
 	}
 
@@ -359,7 +384,19 @@ func (node *resolutionNode) calculateAndStoreCodeParams() error {
 		return node.errorWhenProcessingCodeParams(err)
 	}
 
-	err = node.resolution.RecordCodeParams(node.componentKey, componentCodeParams)
+	// if code params refer to user secrets, calculate their masked version as well, so it can be shown to the user
+	var componentCodeParamsMasked util.NestedParameterMap
+	if len(node.getUserSecrets()) > 0 {
+		componentCodeParamsMasked, err = util.ProcessParameterTree(node.component.Code.Params, node.getContextualDataForCodeDiscoveryTemplateMasked(), node.resolver.templateCache, util.ModeEvaluate)
+		if err != nil {
+			return node.errorWhenProcessingCodeParams(err)
+		}
+		if componentCodeParamsMasked.DeepEqual(componentCodeParams) {
+			componentCodeParamsMasked = nil
+		}
+	}
+
+	err = node.resolution.RecordCodeParams(node.componentKey, componentCodeParams, componentCodeParamsMasked)
 	if err != nil {
 		return node.errorWhenProcessingCodeParams(err)
 	}
@@ -373,7 +410,20 @@ func (node *resolutionNode) calculateAndStoreDiscoveryParams() error {
 		return node.errorWhenProcessingDiscoveryParams(err)
 	}
 
-	err = node.resolution.RecordDiscoveryParams(node.componentKey, componentDiscoveryParams)
+	// if discovery params refer to user secrets (directly or via discovery params of other components), calculate
+	// their masked version as well, so it can be shown to the user and used to calculate masked code params
+	var componentDiscoveryParamsMasked util.NestedParameterMap
+	if len(node.getUserSecrets()) > 0 {
+		componentDiscoveryParamsMasked, err = util.ProcessParameterTree(node.component.Discovery, node.getContextualDataForCodeDiscoveryTemplateMasked(), node.resolver.templateCache, util.ModeEvaluate)
+		if err != nil {
+			return node.errorWhenProcessingDiscoveryParams(err)
+		}
+		if componentDiscoveryParamsMasked.DeepEqual(componentDiscoveryParams) {
+			componentDiscoveryParamsMasked = nil
+		}
+	}
+
+	err = node.resolution.RecordDiscoveryParams(node.componentKey, componentDiscoveryParams, componentDiscoveryParamsMasked)
 	if err != nil {
 		return node.errorWhenProcessingDiscoveryParams(err)
 	}
@@ -384,5 +434,14 @@ func (node *resolutionNode) calculateAndStoreDiscoveryParams() error {
 		node.discoveryTreeNode.GetNestedMap(node.component.Name)[k] = v
 	}
 
+	// Populate masked discovery tree the same way
+	if componentDiscoveryParamsMasked == nil {
+		componentDiscoveryParamsMasked = componentDiscoveryParams
+	}
+	node.discoveryTreeNodeMasked.GetNestedMap(node.component.Name)["instance"] = util.EscapeName(node.componentKey.GetDeployName())
+	for k, v := range componentDiscoveryParamsMasked {
+		node.discoveryTreeNodeMasked.GetNestedMap(node.component.Name)[k] = v
+	}
+
 	return nil
 }
@@ -47,7 +47,7 @@ func (node *resolutionNode) getContextualDataForContextAllocationTemplate() *tem
 			User   interface{}
 			Labels interface{}
 		}{
-			User:   node.proxyUser(node.user),
+			User:   node.proxyUser(node.user, node.getUserSecrets()),
 			Labels: node.labels.Labels,
 		},
 	)
@@ -56,19 +56,54 @@ func (node *resolutionNode) getContextualDataForContextAllocationTemplate() *tem
 // This method defines which contextual information will be exposed to the template engine (for evaluating all templates - discovery, code params, etc)
 // Be careful about what gets exposed through this method. User can refer to structs and their methods from the policy
 func (node *resolutionNode) getContextualDataForCodeDiscoveryTemplate() *template.Parameters {
+	return node.getContextualDataForCodeDiscoveryTemplateWithSecrets(node.getUserSecrets(), node.discoveryTreeNode)
+}
+
+// This method returns the same contextual information as getContextualDataForCodeDiscoveryTemplate, but with values
+// of all user secrets masked (including the ones which got into discovery tree). It's safe to log and show it to the user
+func (node *resolutionNode) getContextualDataForCodeDiscoveryTemplateMasked() *template.Parameters {
+	return node.getContextualDataForCodeDiscoveryTemplateWithSecrets(maskSecrets(node.getUserSecrets()), node.discoveryTreeNodeMasked)
+}
+
+func (node *resolutionNode) getContextualDataForCodeDiscoveryTemplateWithSecrets(secrets map[string]string, discoveryTree util.NestedParameterMap) *template.Parameters {
 	return template.NewParams(
 		struct {
 			User      interface{}
 			Labels    interface{}
 			Discovery interface{}
+			Secrets   interface{}
 		}{
-			User:      node.proxyUser(node.user),
+			User:      node.proxyUser(node.user, secrets),
 			Labels:    node.labels.Labels,
-			Discovery: node.proxyDiscovery(node.discoveryTreeNode, node.componentKey),
+			Discovery: node.proxyDiscovery(discoveryTree, node.componentKey),
+			Secrets:   secrets,
 		},
 	)
 }
 
+// Returns secrets of the current user (or nil, if there is no user)
+func (node *resolutionNode) getUserSecrets() map[string]string {
+	if node.user == nil {
+		return nil
+	}
+	return node.resolver.externalData.SecretLoader.LoadSecretsByUserName(node.user.Name)
+}
+
+// SecretMask is a value which replaces all user secrets in the data shown to the user (event logs, API responses, etc)
+const SecretMask = "******"
+
+// Returns a copy of secrets with all values replaced by SecretMask
+func maskSecrets(secrets map[string]string) map[string]string {
+	if secrets == nil {
+		return nil
+	}
+	result := make(map[string]string, len(secrets))
+	for name := range secrets {
+		result[name] = SecretMask
+	}
+	return result
+}
+
 /*
 	Proxy functions
 */
@@ -85,7 +120,7 @@ func (node *resolutionNode) proxyService(service *lang.Service) interface{} {
 }
 
 // How user is visible from the policy language
-func (node *resolutionNode) proxyUser(user *lang.User) interface{} {
+func (node *resolutionNode) proxyUser(user *lang.User, secrets map[string]string) interface{} {
 	result := struct {
 		Name    interface{}
 		Labels  interface{}
@@ -93,7 +128,7 @@ func (node *resolutionNode) proxyUser(user *lang.User) interface{} {
 	}{
 		Name:    user.Name,
 		Labels:  user.Labels,
-		Secrets: node.resolver.externalData.SecretLoader.LoadSecretsByUserName(user.Name),
+		Secrets: secrets,
 	}
 	return result
 }
@@ -103,7 +103,7 @@ func (node *resolutionNode) errorWhenProcessingCodeParams(cause error) error {
 		fmt.Sprintf("Error when processing code params for service '%s', contract '%s', context '%s', component '%s': %s", node.service.Name, node.contract.Name, node.context.Name, node.component.Name, cause),
 		errors.Details{
 			"component":       node.component,
-			"contextual_data": node.getContextualDataForCodeDiscoveryTemplate(),
+			"contextual_data": node.getContextualDataForCodeDiscoveryTemplateMasked(),
 			"cause":           cause,
 		},
 	)
@@ -115,7 +115,7 @@ func (node *resolutionNode) errorWhenProcessingDiscoveryParams(cause error) erro
 		fmt.Sprintf("Error when processing discovery params for service '%s', contract '%s', context '%s', component '%s': %s", node.service.Name, node.contract.Name, node.context.Name, node.component.Name, cause),
 		errors.Details{
 			"component":       node.component,
-			"contextual_data": node.getContextualDataForCodeDiscoveryTemplate(),
+			"contextual_data": node.getContextualDataForCodeDiscoveryTemplateMasked(),
 			"cause":           cause,
 		},
 	)
@@ -149,10 +149,7 @@ func (node *resolutionNode) logStartResolvingDependency() {
 }
 
 func (node *resolutionNode) logLabels(labelSet *lang.LabelSet, scope string) {
-	secretCnt := 0
-	if node.user != nil {
-		secretCnt = len(node.resolver.externalData.SecretLoader.LoadSecretsByUserName(node.user.Name))
-	}
+	secretCnt := len(node.getUserSecrets())
 	node.eventLog.WithFields(event.Fields{
 		"labels": labelSet.Labels,
 	}).Infof("Labels (%s): %s and %d secrets", scope, labelSet.Labels, secretCnt)
@@ -260,7 +257,7 @@ func (resolver *PolicyResolver) logComponentCodeParams(instance *ComponentInstan
 	code := serviceObj.(*lang.Service).GetComponentsMap()[instance.Metadata.Key.ComponentName].Code
 	if code != nil {
 		paramsTemplate := code.Params
-		params := instance.CalculatedCodeParams
+		params := instance.GetCodeParamsMasked()
 		diff := strings.TrimSpace(paramsTemplate.Diff(params))
 		if len(diff) > 0 {
 			resolver.eventLog.WithFields(event.Fields{
@@ -276,7 +273,7 @@ func (resolver *PolicyResolver) logComponentDiscoveryParams(instance *ComponentI
 		panic(fmt.Sprintf("Fatal error while getting service '%s/%s' from the policy: %s", instance.Metadata.Key.ServiceName, instance.Metadata.Key.Namespace, err))
 	}
 	paramsTemplate := serviceObj.(*lang.Service).GetComponentsMap()[instance.Metadata.Key.ComponentName].Discovery
-	params := instance.CalculatedDiscovery
+	params := instance.GetDiscoveryMasked()
 	diff := strings.TrimSpace(paramsTemplate.Diff(params))
 	if len(diff) > 0 {
 		resolver.eventLog.WithFields(event.Fields{
@@ -0,0 +1,105 @@
+package resolve
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
+)
+
+/*
+	Explanation - record structured trace of resolution, if dependency is being explained
+*/
+
+func (node *resolutionNode) explainStartResolvingContract() {
+	if node.explanation == nil {
+		return
+	}
+	node.trace = &ContractExplanation{
+		Depth:    node.depth,
+		Contract: fmt.Sprintf("%s/%s", node.namespace, node.contractName),
+		Labels:   lang.NewLabelSet(node.labels.Labels).Labels,
+		Contexts: []*ContextExplanation{},
+		Rules:    []*RuleExplanation{},
+	}
+	node.explanation.Contracts = append(node.explanation.Contracts, node.trace)
+}
+
+func (node *resolutionNode) explainContractFound(contract *lang.Contract) {
+	if node.trace == nil {
+		return
+	}
+	node.trace.Contract = fmt.Sprintf("%s/%s", contract.Namespace, contract.Name)
+}
+
+func (node *resolutionNode) explainTestedContextCriteria(context *lang.Context, matched bool) {
+	if node.trace == nil {
+		return
+	}
+	node.trace.Contexts = append(node.trace.Contexts, &ContextExplanation{
+		Name:     context.Name,
+		Criteria: context.Criteria,
+		Matched:  matched,
+	})
+	if matched {
+		node.trace.Context = context.Name
+	}
+}
+
+func (node *resolutionNode) explainServiceFound(service *lang.Service) {
+	if node.trace == nil {
+		return
+	}
+	node.trace.Service = service.Name
+}
+
+func (node *resolutionNode) explainAllocationKeysResolved(resolvedKeys []string) {
+	if node.trace == nil {
+		return
+	}
+	node.trace.AllocationKeys = resolvedKeys
+}
+
+// labelsBeforeRule returns a copy of the current labels, so label changes made by rule could be explained. It returns
+// nil if dependency is not being explained
+func (node *resolutionNode) labelsBeforeRule(result *lang.RuleActionResult) map[string]string {
+	if node.trace == nil {
+		return nil
+	}
+	return lang.NewLabelSet(result.Labels.Labels).Labels
+}
+
+func (node *resolutionNode) explainTestedRule(policyNamespace *lang.PolicyNamespace, rule *lang.Rule, matched bool, labelsBefore map[string]string, result *lang.RuleActionResult) {
+	if node.trace == nil {
+		return
+	}
+	ruleExplanation := &RuleExplanation{
+		Namespace: policyNamespace.Name,
+		Name:      rule.Name,
+		Matched:   matched,
+	}
+	if matched {
+		ruleExplanation.RejectDependency = result.RejectDependency
+		ruleExplanation.LabelChanges = diffLabels(labelsBefore, result.Labels.Labels)
+	}
+	node.trace.Rules = append(node.trace.Rules, ruleExplanation)
+}
+
+func (node *resolutionNode) explainInstanceResolved(cik *ComponentInstanceKey) {
+	if node.trace == nil {
+		return
+	}
+	if cik.IsService() {
+		node.trace.ServiceKey = cik.GetKey()
+		if node.depth == 0 {
+			node.explanation.ServiceKey = cik.GetKey()
+		}
+	} else {
+		node.trace.ComponentKeys = append(node.trace.ComponentKeys, cik.GetKey())
+	}
+}
+
+func (node *resolutionNode) explainCannotResolveInstance(err error) {
+	if node.trace == nil || err == nil || len(node.trace.Error) > 0 {
+		return
+	}
+	node.trace.Error = err.Error()
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/lang/builder"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -215,6 +216,126 @@ func TestPolicyResolverCodeAndDiscoveryParams(t *testing.T) {
 	assert.Equal(t, 5, instance2.CalculatedCodeParams.GetNestedMap("nested").GetNestedMap("param")["nameInt"], "Code parameter should be calculated correctly (int)")
 }
 
+func TestPolicyResolverCodeParamsWithSecrets(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service which uses user secrets in its code parameters
+	service := b.AddService()
+	component := b.CodeComponent(
+		util.NestedParameterMap{
+			"cluster":  "{{ .Labels.cluster }}",
+			"token":    "{{ .Secrets.token }}",
+			"password": "pass-{{ .User.Secrets.password }}",
+		},
+		nil,
+	)
+	b.AddServiceComponent(service, component)
+	contract := b.AddContract(service, b.CriteriaTrue())
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	user := b.AddUser()
+	b.AddUserSecret(user, "token", "secrettoken")
+	b.AddUserSecret(user, "password", "secretpassword")
+	b.AddDependency(user, contract)
+
+	// policy should be resolved successfully
+	eventLog := event.NewLog("test-resolve", false)
+	resolver := NewPolicyResolver(b.Policy(), b.External(), eventLog)
+	resolution, err := resolver.ResolveAllDependencies()
+	if !assert.NoError(t, err, "Policy should be resolved without errors") {
+		t.FailNow()
+	}
+
+	// code params should contain actual secret values
+	instance := getInstanceByParams(t, cluster, contract, contract.Contexts[0], nil, service, component, resolution)
+	assert.Equal(t, "secrettoken", instance.CalculatedCodeParams["token"], "Code parameter should contain secret value")
+	assert.Equal(t, "pass-secretpassword", instance.CalculatedCodeParams["password"], "Code parameter should contain secret value")
+
+	// masked code params should contain masked secret values
+	assert.Equal(t, cluster.Name, instance.GetCodeParamsMasked()["cluster"], "Masked code parameter should be calculated correctly")
+	assert.Equal(t, SecretMask, instance.GetCodeParamsMasked()["token"], "Secret value should be masked")
+	assert.Equal(t, "pass-"+SecretMask, instance.GetCodeParamsMasked()["password"], "Secret value should be masked")
+
+	// event log should not contain secret values (neither in messages, nor in fields)
+	hook := &logContentHook{}
+	eventLog.Save(hook)
+	assert.NotContains(t, hook.content, "secrettoken", "Event log should not contain secret values")
+	assert.NotContains(t, hook.content, "secretpassword", "Event log should not contain secret values")
+	assert.Contains(t, hook.content, SecretMask, "Event log should contain masked secret values")
+}
+
+func TestPolicyResolverCodeParamsWithoutSecrets(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	service := b.AddService()
+	component := b.CodeComponent(util.NestedParameterMap{"cluster": "{{ .Labels.cluster }}"}, nil)
+	b.AddServiceComponent(service, component)
+	contract := b.AddContract(service, b.CriteriaTrue())
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	user := b.AddUser()
+	b.AddUserSecret(user, "token", "secrettoken")
+	b.AddDependency(user, contract)
+
+	// masked code params should not be stored if code params don't refer to secrets
+	resolution := resolvePolicy(t, b, ResSuccess, "Successfully resolved")
+	instance := getInstanceByParams(t, cluster, contract, contract.Contexts[0], nil, service, component, resolution)
+	assert.Nil(t, instance.CalculatedCodeParamsMasked, "Masked code params should not be stored")
+	assert.Equal(t, instance.CalculatedCodeParams, instance.GetCodeParamsMasked(), "Code params should be returned as masked code params")
+}
+
+func TestPolicyResolverDiscoveryParamsWithSecrets(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service, where discovery params of one component refer to user secrets and code params of another
+	// component refer to them
+	service := b.AddService()
+	component1 := b.CodeComponent(
+		nil,
+		util.NestedParameterMap{"url": "http://admin:{{ .Secrets.password }}@{{ .Discovery.instance }}"},
+	)
+	component2 := b.CodeComponent(
+		util.NestedParameterMap{
+			"cluster": "{{ .Labels.cluster }}",
+			"address": fmt.Sprintf("{{ .Discovery.%s.url }}", component1.Name),
+		},
+		nil,
+	)
+	b.AddServiceComponent(service, component1)
+	b.AddServiceComponent(service, component2)
+	contract := b.AddContract(service, b.CriteriaTrue())
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	user := b.AddUser()
+	b.AddUserSecret(user, "password", "secretpassword")
+	b.AddDependency(user, contract)
+
+	// policy should be resolved successfully
+	eventLog := event.NewLog("test-resolve", false)
+	resolver := NewPolicyResolver(b.Policy(), b.External(), eventLog)
+	resolution, err := resolver.ResolveAllDependencies()
+	if !assert.NoError(t, err, "Policy should be resolved without errors") {
+		t.FailNow()
+	}
+
+	// discovery params and code params should contain actual secret values, while their masked versions should not
+	instance1 := getInstanceByParams(t, cluster, contract, contract.Contexts[0], nil, service, component1, resolution)
+	instance2 := getInstanceByParams(t, cluster, contract, contract.Contexts[0], nil, service, component2, resolution)
+	assert.Contains(t, instance1.CalculatedDiscovery["url"], "secretpassword", "Discovery parameter should contain secret value")
+	assert.Contains(t, instance1.GetDiscoveryMasked()["url"], "admin:"+SecretMask+"@", "Secret value should be masked in discovery parameter")
+	assert.Contains(t, instance2.CalculatedCodeParams["address"], "secretpassword", "Code parameter should contain secret value")
+	assert.Equal(t, instance1.GetDiscoveryMasked()["url"], instance2.GetCodeParamsMasked()["address"], "Masked code parameter should be calculated from masked discovery parameter")
+
+	// event log should not contain secret values (neither in messages, nor in fields)
+	hook := &logContentHook{}
+	eventLog.Save(hook)
+	assert.NotContains(t, hook.content, "secretpassword", "Event log should not contain secret values")
+	assert.Contains(t, hook.content, SecretMask, "Event log should contain masked secret values")
+}
+
 func TestPolicyResolverDependencyWithNonExistingUser(t *testing.T) {
 	b := builder.NewPolicyBuilder()
 	service := b.AddService()
@@ -306,6 +427,133 @@ func TestPolicyResolverServiceLoop(t *testing.T) {
 	resolvePolicy(t, b, ResError, "service cycle detected")
 }
 
+func TestPolicyResolverPartial(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a normal service
+	service := b.AddService()
+	component := b.AddServiceComponent(service, b.CodeComponent(nil, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+
+	// create a service with a cycle
+	serviceCycle := b.AddService()
+	contractCycle := b.AddContract(serviceCycle, b.CriteriaTrue())
+	b.AddServiceComponent(serviceCycle, b.ContractComponent(contractCycle))
+
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	// add dependencies
+	dependency := b.AddDependency(b.AddUser(), contract)
+	dependencyFailed := b.AddDependency(b.AddUser(), contractCycle)
+
+	// full policy resolution should fail
+	resolvePolicy(t, b, ResError, "service cycle detected")
+
+	// partial policy resolution should succeed
+	resolver := NewPolicyResolver(b.Policy(), b.External(), event.NewLog("test-resolve", false))
+	resolution, err := resolver.ResolveAllDependenciesPartially()
+	if !assert.NoError(t, err, "Policy should be partially resolved without errors") {
+		t.FailNow()
+	}
+
+	// normal dependency should be resolved
+	assert.Contains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(dependency), "Dependency should be resolved")
+	assert.NotNil(t, getInstanceByParams(t, cluster, contract, contract.Contexts[0], nil, service, component, resolution), "Component instance should be resolved")
+
+	// failed dependency should be recorded along with its error
+	assert.NotContains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(dependencyFailed), "Failed dependency should not be resolved")
+	assert.Len(t, resolution.GetDependencyErrors(), 1, "Only one dependency should fail")
+	assert.Contains(t, resolution.GetDependencyErrors()[runtime.KeyForStorable(dependencyFailed)], "service cycle detected", "Dependency error should be recorded")
+}
+
+func TestPolicyResolverExplainDependency(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service, which depends on another service
+	service1 := b.AddService()
+	contract1 := b.AddContractMultipleContexts(service1, b.Criteria("label1 == 'other'", "true", "false"), b.CriteriaTrue())
+	service2 := b.AddService()
+	component2 := b.AddServiceComponent(service2, b.CodeComponent(nil, nil))
+	contract2 := b.AddContract(service2, b.CriteriaTrue())
+	b.AddServiceComponent(service1, b.ContractComponent(contract2))
+
+	// add rule to set cluster
+	cluster := b.AddCluster()
+	rule := b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	// add dependency
+	dependency := b.AddDependency(b.AddUser(), contract1)
+	dependency.Labels["label1"] = "value1"
+
+	resolver := NewPolicyResolver(b.Policy(), b.External(), event.NewLog("test-resolve", false))
+	explanation, err := resolver.ExplainDependency(dependency)
+	if !assert.NoError(t, err, "Dependency should be explained without errors") {
+		t.FailNow()
+	}
+
+	assert.Equal(t, runtime.KeyForStorable(dependency), explanation.Dependency, "Dependency key should be set")
+	assert.True(t, explanation.Resolved, "Dependency should be resolved")
+	assert.Empty(t, explanation.Error, "Dependency should be resolved without errors")
+	if !assert.Len(t, explanation.Contracts, 2, "Both contracts should be explained") {
+		t.FailNow()
+	}
+
+	// first context shouldn't match, second one should
+	top := explanation.Contracts[0]
+	assert.Equal(t, 0, top.Depth, "Top-level contract should be on depth 0")
+	assert.Equal(t, contract1.Namespace+"/"+contract1.Name, top.Contract, "Top-level contract should be explained first")
+	assert.Equal(t, "value1", top.Labels["label1"], "Initial labels should be recorded")
+	if assert.Len(t, top.Contexts, 2, "Both contexts should be tested") {
+		assert.False(t, top.Contexts[0].Matched, "First context should not be matched")
+		assert.True(t, top.Contexts[1].Matched, "Second context should be matched")
+	}
+	assert.Equal(t, contract1.Contexts[1].Name, top.Context, "Matched context should be recorded")
+	assert.Equal(t, service1.Name, top.Service, "Allocated service should be recorded")
+	assert.Equal(t, explanation.ServiceKey, top.ServiceKey, "Resulting service instance should be recorded")
+
+	// rule should be applied and change labels
+	if assert.Len(t, top.Rules, 1, "Rule should be tested") {
+		assert.Equal(t, rule.Name, top.Rules[0].Name, "Rule name should be recorded")
+		assert.True(t, top.Rules[0].Matched, "Rule should be matched")
+		assert.Equal(t, []*LabelChange{{Name: lang.LabelCluster, After: cluster.Name}}, top.Rules[0].LabelChanges, "Label changes made by rule should be recorded")
+	}
+
+	// dependent contract should be explained along with its component instance
+	sub := explanation.Contracts[1]
+	assert.Equal(t, 1, sub.Depth, "Dependent contract should be on depth 1")
+	assert.Equal(t, contract2.Namespace+"/"+contract2.Name, sub.Contract, "Dependent contract should be explained")
+	if assert.Len(t, sub.ComponentKeys, 1, "Component instance should be recorded") {
+		key := NewComponentInstanceKey(cluster, contract2, contract2.Contexts[0], nil, service2, component2)
+		assert.Equal(t, key.GetKey(), sub.ComponentKeys[0], "Correct component instance should be recorded")
+	}
+}
+
+func TestPolicyResolverExplainDependencyError(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service with a cycle
+	service := b.AddService()
+	contract := b.AddContract(service, b.CriteriaTrue())
+	b.AddServiceComponent(service, b.ContractComponent(contract))
+
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+	dependency := b.AddDependency(b.AddUser(), contract)
+
+	resolver := NewPolicyResolver(b.Policy(), b.External(), event.NewLog("test-resolve", false))
+	explanation, err := resolver.ExplainDependency(dependency)
+	if !assert.NoError(t, err, "Dependency should be explained without errors") {
+		t.FailNow()
+	}
+
+	assert.False(t, explanation.Resolved, "Dependency should not be resolved")
+	assert.Contains(t, explanation.Error, "service cycle detected", "Resolution error should be recorded")
+	if assert.Len(t, explanation.Contracts, 2, "Contract should be explained twice before cycle is detected") {
+		assert.Contains(t, explanation.Contracts[1].Error, "service cycle detected", "Error should be recorded for the contract where it occurred")
+	}
+}
+
 func TestPolicyResolverPickClusterViaRules(t *testing.T) {
 	b := builder.NewPolicyBuilder()
 
@@ -422,3 +670,17 @@ func getInstanceByParams(t *testing.T, cluster *lang.Cluster, contract *lang.Con
 	}
 	return instance
 }
+
+// logContentHook collects messages and fields of all event log entries
+type logContentHook struct {
+	content string
+}
+
+func (hook *logContentHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (hook *logContentHook) Fire(e *logrus.Entry) error {
+	hook.content += fmt.Sprintf("%s %v\n", e.Message, e.Data)
+	return nil
+}
@@ -23,6 +23,32 @@ const (
 	RevisionStatusSuccess = "success"
 	// RevisionStatusError represents Revision status with apply finished with error
 	RevisionStatusError = "error"
+	// RevisionStatusInterrupted represents Revision status with apply interrupted before completion (e.g. revision
+	// timeout expired or server got shut down)
+	RevisionStatusInterrupted = "interrupted"
+	// RevisionStatusBlocked represents Revision status with apply blocked, because it's too destructive and needs to be
+	// approved first
+	RevisionStatusBlocked = "blocked"
+	// RevisionStatusPendingApproval represents Revision status with apply waiting for a manual approval, because it
+	// changes component instances in namespaces, which require approval
+	RevisionStatusPendingApproval = "pending-approval"
+	// RevisionStatusRejected represents Revision status with apply rejected by a user (blocked or pending approval
+	// revision won't be applied and enforcer waits for the next policy change)
+	RevisionStatusRejected = "rejected"
+)
+
+const (
+	// RevisionActionStatusPending represents status of the action, which hasn't been executed yet
+	RevisionActionStatusPending = "pending"
+	// RevisionActionStatusRunning represents status of the action, which is being executed
+	RevisionActionStatusRunning = "running"
+	// RevisionActionStatusSuccess represents status of the action, which got successfully executed
+	RevisionActionStatusSuccess = "success"
+	// RevisionActionStatusError represents status of the action, which failed
+	RevisionActionStatusError = "error"
+	// RevisionActionStatusSkipped represents status of the action, which wasn't executed (e.g. because actions it
+	// depends on failed or applying got interrupted)
+	RevisionActionStatusSkipped = "skipped"
 )
 
 // Revision is a "milestone" in applying
@@ -36,6 +62,63 @@ type Revision struct {
 	Status    string
 	Progress  RevisionProgress
 	AppliedAt time.Time
+
+	// BlockedReason explains why revision got blocked or waits for approval (e.g. which delete limit got exceeded)
+	BlockedReason string `yaml:",omitempty"`
+
+	// ApprovalNamespaces is a list of namespaces requiring manual approval, which revision changes component instances in
+	ApprovalNamespaces []string `yaml:",omitempty"`
+
+	// PlannedActions is a list of actions, which revision is going to apply once approved
+	PlannedActions []string `yaml:",omitempty"`
+
+	// ApprovedBy is the name of the user, who approved revision to be applied
+	ApprovedBy string `yaml:",omitempty"`
+	ApprovedAt time.Time
+
+	// Deferred contains component instances (by key), which changes got deferred by freeze windows, along with the
+	// explanation why
+	Deferred map[string]string `yaml:",omitempty"`
+
+	// RejectedBy is the name of the user, who rejected revision
+	RejectedBy string `yaml:",omitempty"`
+	RejectedAt time.Time
+
+	// Actions is a list of actions applied within revision along with their results, in the order they were planned
+	Actions []*RevisionAction `yaml:",omitempty"`
+}
+
+// RevisionAction represents result of a single action applied within revision
+type RevisionAction struct {
+	Name       string
+	Status     string
+	Error      string `yaml:",omitempty"`
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Duration   time.Duration
+}
+
+// ResetActions sets the list of actions (by their names), which are going to be applied within revision. All of them
+// are pending until applying starts
+func (revision *Revision) ResetActions(names []string) {
+	revision.Actions = make([]*RevisionAction, len(names))
+	for idx, name := range names {
+		revision.Actions[idx] = &RevisionAction{
+			Name:   name,
+			Status: RevisionActionStatusPending,
+		}
+	}
+}
+
+// IsWaitingForApproval returns true if revision got blocked or requires manual approval and it hasn't been approved
+// or rejected yet
+func (revision *Revision) IsWaitingForApproval() bool {
+	return (revision.Status == RevisionStatusBlocked || revision.Status == RevisionStatusPendingApproval) && !revision.IsApproved()
+}
+
+// IsApproved returns true if revision got blocked or required manual approval and then approved by a user
+func (revision *Revision) IsApproved() bool {
+	return len(revision.ApprovedBy) > 0
 }
 
 // RevisionProgress represents revision applying progress
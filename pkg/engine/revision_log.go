@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"strings"
+)
+
+// RevisionLogObject is Info for RevisionLog
+var RevisionLogObject = &runtime.Info{
+	Kind:        "revision-log",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &RevisionLog{} },
+}
+
+// RevisionLog represents event log entries (both policy resolution and apply ones) of a single Revision
+type RevisionLog struct {
+	runtime.TypeKind `yaml:",inline"`
+	Metadata         RevisionLogMetadata
+
+	// Entries are the event log entries in the order they were recorded
+	Entries []*event.LogEntry
+}
+
+// RevisionLogMetadata is the metadata for RevisionLog object
+type RevisionLogMetadata struct {
+	// Revision is a generation of the corresponding revision
+	Revision runtime.Generation
+}
+
+// GetName returns RevisionLog name
+func (revisionLog *RevisionLog) GetName() string {
+	return revisionLog.Metadata.Revision.String()
+}
+
+// GetNamespace returns RevisionLog namespace
+func (revisionLog *RevisionLog) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (revisionLog *RevisionLog) GetDefaultColumns() []string {
+	return []string{"Revision", "Log"}
+}
+
+// AsColumns returns RevisionLog representation as columns
+func (revisionLog *RevisionLog) AsColumns() map[string]string {
+	lines := []string{}
+	for _, entry := range revisionLog.Entries {
+		lines = append(lines, "["+entry.Level+"] "+entry.Message)
+	}
+
+	logStr := "(empty)"
+	if len(lines) > 0 {
+		logStr = strings.Join(lines, "\n")
+	}
+
+	return map[string]string{
+		"Revision": revisionLog.Metadata.Revision.String(),
+		"Log":      logStr,
+	}
+}
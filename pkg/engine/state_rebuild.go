@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// StateRebuildObject is Info for StateRebuild
+var StateRebuildObject = &runtime.Info{
+	Kind:        "state-rebuild",
+	Storable:    true,
+	Versioned:   false,
+	Constructor: func() runtime.Object { return &StateRebuild{} },
+}
+
+// StateRebuildName is the name of the StateRebuild object (there is only one state rebuild, which gets overwritten by
+// every request to rebuild actual state)
+const StateRebuildName = "latest"
+
+// StateRebuildKey is the key for the StateRebuild object
+var StateRebuildKey = runtime.KeyFromParts(runtime.SystemNS, StateRebuildObject.Kind, StateRebuildName)
+
+// StateRebuildStatus represents status of the actual state rebuild
+type StateRebuildStatus string
+
+const (
+	// StateRebuildStatusRequested represents state rebuild, which has been requested, but hasn't been done yet
+	StateRebuildStatusRequested StateRebuildStatus = "requested"
+	// StateRebuildStatusSuccess represents state rebuild, which has been successfully done
+	StateRebuildStatusSuccess StateRebuildStatus = "success"
+	// StateRebuildStatusError represents state rebuild, which has failed
+	StateRebuildStatusError StateRebuildStatus = "error"
+)
+
+// StateRebuild represents the latest request to rebuild actual state from what is really running in the cloud, so
+// component instances found in the cloud get adopted instead of being created again (e.g. after the store got lost)
+type StateRebuild struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Status is the status of the state rebuild
+	Status StateRebuildStatus
+
+	// RequestedBy is the name of the user, who requested the state rebuild
+	RequestedBy string
+
+	// RequestedAt is when the state rebuild was requested
+	RequestedAt time.Time
+
+	// Reset defines whether actual state should be reset before being rebuilt
+	Reset bool `yaml:",omitempty"`
+
+	// FinishedAt is when the state rebuild was done or failed
+	FinishedAt time.Time
+
+	// Adopted contains keys of component instances, which have been found in the cloud and adopted into the actual
+	// state
+	Adopted []string `yaml:",omitempty"`
+
+	// Error is the error state rebuild failed with
+	Error string `yaml:",omitempty"`
+}
+
+// GetName returns StateRebuild name
+func (rebuild *StateRebuild) GetName() string {
+	return StateRebuildName
+}
+
+// GetNamespace returns StateRebuild namespace
+func (rebuild *StateRebuild) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// IsRequested returns true if the state rebuild has been requested, but hasn't been done yet
+func (rebuild *StateRebuild) IsRequested() bool {
+	return rebuild.Status == StateRebuildStatusRequested
+}
@@ -2,10 +2,12 @@ package event
 
 import (
 	"github.com/Sirupsen/logrus"
+	"sync"
 )
 
 // HookMemory implements event log hook, which buffers all event log entries in hookMemory
 type HookMemory struct {
+	lock    sync.Mutex // logrus fires hooks concurrently, so entries should be protected
 	entries []*logrus.Entry
 }
 
@@ -16,6 +18,9 @@ func (buf *HookMemory) Levels() []logrus.Level {
 
 // Fire processes a single log entry
 func (buf *HookMemory) Fire(e *logrus.Entry) error {
+	buf.lock.Lock()
+	defer buf.lock.Unlock()
+
 	buf.entries = append(buf.entries, e)
 	return nil
 }
@@ -0,0 +1,73 @@
+package event
+
+import (
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"time"
+)
+
+// LogEntry is a single event log entry in the form suitable for persisting in the store
+type LogEntry struct {
+	Time    time.Time
+	Level   string
+	Scope   string `yaml:",omitempty"`
+	Message string
+	Fields  map[string]string `yaml:",omitempty"`
+}
+
+// HookStore implements event log hook, which converts all event log entries into LogEntry objects, so they can be
+// persisted in the store
+type HookStore struct {
+	Entries []*LogEntry
+}
+
+// Levels defines on which log levels this hook should be fired
+func (buf *HookStore) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire processes a single log entry
+func (buf *HookStore) Fire(e *logrus.Entry) error {
+	entry := &LogEntry{
+		Time:    e.Time,
+		Level:   e.Level.String(),
+		Message: e.Message,
+		Fields:  make(map[string]string),
+	}
+	for key, value := range e.Data {
+		switch key {
+		case "attachedTo":
+			// objects attached to the event log are not persisted
+		case "scope":
+			entry.Scope = fmt.Sprintf("%v", value)
+		default:
+			entry.Fields[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	buf.Entries = append(buf.Entries, entry)
+	return nil
+}
+
+// FilterLogEntries returns log entries with the given level or more severe one (e.g. "warning" returns warnings,
+// errors and more severe entries). All entries are returned if level is empty
+func FilterLogEntries(entries []*LogEntry, level string) ([]*LogEntry, error) {
+	if len(level) == 0 {
+		return entries, nil
+	}
+
+	minLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*LogEntry{}
+	for _, entry := range entries {
+		entryLevel, err := logrus.ParseLevel(entry.Level)
+		if err != nil || entryLevel <= minLevel {
+			result = append(result, entry)
+		}
+	}
+
+	return result, nil
+}
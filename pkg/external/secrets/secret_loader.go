@@ -1,8 +1,31 @@
 package secrets
 
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+)
+
 // SecretLoader is an interface which allows aptomi to load secrets for users
 // from different sources (e.g. file, external store, etc)
 type SecretLoader interface {
 	// LoadSecretsByUserName should load a set of secrets for a given user
 	LoadSecretsByUserName(string) map[string]string
 }
+
+// NewSecretLoader returns SecretLoader for the source of secrets specified in config. If no source is specified,
+// loader returns no secrets
+func NewSecretLoader(cfg config.Secrets) (SecretLoader, error) {
+	if cfg.SourceCount() > 1 {
+		return nil, fmt.Errorf("only one source of secrets could be specified")
+	}
+
+	if cfg.Vault != nil {
+		return NewSecretLoaderFromVault(*cfg.Vault, cfg.RefreshInterval), nil
+	}
+
+	if cfg.EncryptedFile != nil {
+		return NewSecretLoaderFromEncryptedFile(*cfg.EncryptedFile, cfg.RefreshInterval)
+	}
+
+	return NewSecretLoaderFromDir(cfg.Dir, cfg.RefreshInterval), nil
+}
@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	log "github.com/Sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshInterval is how often secrets are reloaded from the source if refresh interval is not specified
+const DefaultRefreshInterval = time.Minute
+
+// cachedSecretLoader keeps secrets of all users loaded from the source and reloads them once refresh interval
+// expires, so rotated secrets are picked up without restart. If reloading fails, previously loaded secrets are kept,
+// so temporary unavailability of the source doesn't change the resolved policy. Secrets are loaded outside of the
+// lock and only one load runs at a time, so a slow source doesn't block lookups while previously loaded secrets exist
+type cachedSecretLoader struct {
+	source          string
+	load            func() (map[string]map[string]string, error)
+	refreshInterval time.Duration
+	lock            sync.Mutex // protects all fields below
+	secrets         map[string]map[string]string
+	loadedAt        time.Time
+	loading         chan struct{} // not nil while secrets are being loaded, gets closed once loading is done
+}
+
+func newCachedSecretLoader(source string, refreshInterval time.Duration, load func() (map[string]map[string]string, error)) *cachedSecretLoader {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+	return &cachedSecretLoader{
+		source:          source,
+		load:            load,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// LoadSecretsAll loads secrets for all users
+func (loader *cachedSecretLoader) LoadSecretsAll() map[string]map[string]string {
+	// this can be called concurrently by the engine, so it needs to be thread safe
+	loader.lock.Lock()
+	secrets, loading := loader.secrets, loader.loading
+	if secrets != nil && (loading != nil || time.Since(loader.loadedAt) < loader.refreshInterval) {
+		// secrets are fresh or being reloaded by someone else, previously loaded ones could be used meanwhile
+		loader.lock.Unlock()
+		return secrets
+	}
+	if loading != nil {
+		// secrets haven't been loaded yet, so wait until someone else loads them
+		loader.lock.Unlock()
+		<-loading
+		loader.lock.Lock()
+		defer loader.lock.Unlock()
+		return loader.secrets
+	}
+	loading = make(chan struct{})
+	loader.loading = loading
+	loader.lock.Unlock()
+
+	loaded, err := loader.load()
+
+	loader.lock.Lock()
+	defer loader.lock.Unlock()
+	defer close(loading)
+	loader.loading = nil
+	loader.loadedAt = time.Now()
+
+	if err != nil {
+		log.Errorf("Error while loading secrets from %s (previously loaded secrets will be used): %s", loader.source, err)
+		if loader.secrets == nil {
+			loader.secrets = make(map[string]map[string]string)
+		}
+		return loader.secrets
+	}
+
+	secrets = make(map[string]map[string]string)
+	for user, userSecrets := range loaded {
+		secrets[strings.ToLower(user)] = userSecrets
+	}
+	loader.secrets = secrets
+
+	return loader.secrets
+}
+
+// LoadSecretsByUserName loads secrets for a single user
+func (loader *cachedSecretLoader) LoadSecretsByUserName(user string) map[string]string {
+	return loader.LoadSecretsAll()[strings.ToLower(user)]
+}
@@ -6,7 +6,7 @@ import (
 )
 
 func TestLoadSecrets(t *testing.T) {
-	secretLoader := NewSecretLoaderFromDir("../../testdata/unittests")
+	secretLoader := NewSecretLoaderFromDir("../../testdata/unittests", DefaultRefreshInterval)
 
 	{
 		secrets := secretLoader.LoadSecretsByUserName("alice")
@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// SecretLoaderFromEncryptedFile allows to load secrets for users from a YAML file encrypted with AES-GCM. File
+// should contain base64-encoded nonce followed by the encrypted data (see EncryptData)
+type SecretLoaderFromEncryptedFile struct {
+	*cachedSecretLoader
+	fileName string
+	aead     cipher.AEAD
+}
+
+// NewSecretLoaderFromEncryptedFile returns new SecretLoaderFromEncryptedFile, given encrypted file config and interval
+// for re-reading secrets
+func NewSecretLoaderFromEncryptedFile(cfg config.EncryptedFile, refreshInterval time.Duration) (SecretLoader, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding secrets encryption key: %s", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := &SecretLoaderFromEncryptedFile{
+		fileName: cfg.File,
+		aead:     aead,
+	}
+	loader.cachedSecretLoader = newCachedSecretLoader("encrypted file "+cfg.File, refreshInterval, loader.loadFromFile)
+	return loader, nil
+}
+
+func (loader *SecretLoaderFromEncryptedFile) loadFromFile() (map[string]map[string]string, error) {
+	encrypted, err := ioutil.ReadFile(loader.fileName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file '%s': %s", loader.fileName, err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encrypted)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode file '%s': %s", loader.fileName, err)
+	}
+
+	nonceSize := loader.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("unable to decrypt file '%s': data is too short", loader.fileName)
+	}
+
+	decrypted, err := loader.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt file '%s': %s", loader.fileName, err)
+	}
+
+	secrets := []*UserSecrets{}
+	err = yaml.Unmarshal(decrypted, &secrets)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal secrets from '%s': %s", loader.fileName, err)
+	}
+
+	result := make(map[string]map[string]string)
+	for _, secret := range secrets {
+		result[secret.User] = secret.Secrets
+	}
+
+	return result, nil
+}
+
+// EncryptData encrypts data with AES-GCM using the given key and returns it in the format expected by
+// SecretLoaderFromEncryptedFile. It could be used to prepare encrypted secrets file
+func EncryptData(key []byte, data []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", fmt.Errorf("error while generating nonce: %s", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, data, nil)), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets encryption key: %s", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error while initializing AES-GCM: %s", err)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadSecretsFromEncryptedFile(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	data := []byte(`
+- user: Alice
+  secrets:
+    twitterAppKey: aliceappkey
+- user: bob
+  secrets:
+    twitterAppKey: bobappkey
+`)
+
+	encrypted, err := EncryptData(key, data)
+	assert.NoError(t, err, "Data should be encrypted without errors")
+
+	tmpFile := util.WriteTempFile("unittest", []byte(encrypted))
+	defer os.Remove(tmpFile) // nolint: errcheck
+
+	loader, err := NewSecretLoaderFromEncryptedFile(config.EncryptedFile{File: tmpFile, Key: base64.StdEncoding.EncodeToString(key)}, time.Hour)
+	assert.NoError(t, err, "Secret loader should be created without errors")
+	assert.Equal(t, "aliceappkey", loader.LoadSecretsByUserName("alice")["twitterAppKey"], "Secrets for alice should be loaded from encrypted file")
+	assert.Equal(t, "bobappkey", loader.LoadSecretsByUserName("BOB")["twitterAppKey"], "Secrets for bob should be loaded from encrypted file")
+
+	// file encrypted with a different key can't be read
+	wrongKey := []byte("fedcba9876543210fedcba9876543210")
+	loader, err = NewSecretLoaderFromEncryptedFile(config.EncryptedFile{File: tmpFile, Key: base64.StdEncoding.EncodeToString(wrongKey)}, time.Hour)
+	assert.NoError(t, err, "Secret loader should be created without errors")
+	assert.Empty(t, loader.(*SecretLoaderFromEncryptedFile).LoadSecretsAll(), "No secrets should be loaded with a wrong key")
+
+	// invalid keys are rejected
+	_, err = NewSecretLoaderFromEncryptedFile(config.EncryptedFile{File: tmpFile, Key: base64.StdEncoding.EncodeToString([]byte("short"))}, time.Hour)
+	assert.Error(t, err, "Key of invalid length should be rejected")
+	_, err = NewSecretLoaderFromEncryptedFile(config.EncryptedFile{File: tmpFile, Key: "not base64!"}, time.Hour)
+	assert.Error(t, err, "Key which is not base64-encoded should be rejected")
+}
@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SecretLoaderFromVault allows to load secrets for users from Vault-compatible KV store over HTTP. Secrets of each
+// user are stored as a separate KV secret named after the user
+type SecretLoaderFromVault struct {
+	*cachedSecretLoader
+	cfg    config.Vault
+	client *http.Client
+}
+
+// NewSecretLoaderFromVault returns new SecretLoaderFromVault, given Vault config and interval for re-reading secrets
+func NewSecretLoaderFromVault(cfg config.Vault, refreshInterval time.Duration) SecretLoader {
+	loader := &SecretLoaderFromVault{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	loader.cachedSecretLoader = newCachedSecretLoader("vault "+cfg.Address, refreshInterval, loader.loadFromVault)
+	return loader
+}
+
+func (loader *SecretLoaderFromVault) loadFromVault() (map[string]map[string]string, error) {
+	users, err := loader.listUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]string)
+	for _, user := range users {
+		secrets, errRead := loader.readUserSecrets(user)
+		if errRead != nil {
+			return nil, errRead
+		}
+		result[user] = secrets
+	}
+
+	return result, nil
+}
+
+// listUsers returns names of all users, which have secrets stored in Vault
+func (loader *SecretLoaderFromVault) listUsers() ([]string, error) {
+	response := &struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}{}
+
+	found, err := loader.get(loader.getURL("metadata", "")+"?list=true", response)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	result := []string{}
+	for _, key := range response.Data.Keys {
+		// skip nested folders
+		if !strings.HasSuffix(key, "/") {
+			result = append(result, key)
+		}
+	}
+
+	return result, nil
+}
+
+// readUserSecrets returns secrets of the given user stored in Vault
+func (loader *SecretLoaderFromVault) readUserSecrets(user string) (map[string]string, error) {
+	response := &struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+
+	found, err := loader.get(loader.getURL("data", user), response)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	data := response.Data
+	if loader.cfg.KVVersion == 2 {
+		// KV v2 returns secret data along with its metadata
+		data, _ = data["data"].(map[string]interface{})
+	}
+
+	result := make(map[string]string)
+	for key, value := range data {
+		result[key] = fmt.Sprintf("%v", value)
+	}
+
+	return result, nil
+}
+
+// getURL returns URL for the given user secret (or for the folder with all user secrets if user is empty). Prefix
+// is a path prefix used by KV v2 (data for reading secrets and metadata for listing them)
+func (loader *SecretLoaderFromVault) getURL(prefix string, user string) string {
+	parts := []string{strings.TrimSuffix(loader.cfg.Address, "/"), "v1", strings.Trim(loader.cfg.Mount, "/")}
+	if loader.cfg.KVVersion == 2 {
+		parts = append(parts, prefix)
+	}
+	parts = append(parts, strings.Trim(loader.cfg.Path, "/"))
+	if len(user) > 0 {
+		parts = append(parts, user)
+	}
+	return strings.Join(parts, "/")
+}
+
+// get makes request to Vault and decodes JSON response. It returns false if there is nothing found at the given URL
+func (loader *SecretLoaderFromVault) get(url string, response interface{}) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-Vault-Token", loader.cfg.Token)
+
+	resp, err := loader.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("error while making request to vault: %s", err)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected response status from vault for %s: %s", req.URL.Path, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(response)
+	if err != nil {
+		return false, fmt.Errorf("error while decoding response from vault for %s: %s", req.URL.Path, err)
+	}
+
+	return true, nil
+}
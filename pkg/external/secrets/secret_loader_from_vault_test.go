@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"encoding/json"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// vaultStandIn emulates Vault KV secrets engine for the given set of user secrets
+func vaultStandIn(t *testing.T, kvVersion int, secrets map[string]map[string]interface{}) *httptest.Server {
+	listPath := "/v1/secret/aptomi"
+	dataPath := "/v1/secret/aptomi/"
+	if kvVersion == 2 {
+		listPath = "/v1/secret/metadata/aptomi"
+		dataPath = "/v1/secret/data/aptomi/"
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("X-Vault-Token") != "token" {
+			writer.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var response interface{}
+		if request.URL.Path == listPath && request.URL.Query().Get("list") == "true" {
+			keys := []string{"folder/"}
+			for user := range secrets {
+				keys = append(keys, user)
+			}
+			response = map[string]interface{}{"data": map[string]interface{}{"keys": keys}}
+		} else if userSecrets, ok := secrets[request.URL.Path[len(dataPath):]]; ok && len(request.URL.Path) > len(dataPath) {
+			if kvVersion == 2 {
+				response = map[string]interface{}{"data": map[string]interface{}{"data": userSecrets}}
+			} else {
+				response = map[string]interface{}{"data": userSecrets}
+			}
+		} else {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		err := json.NewEncoder(writer).Encode(response)
+		assert.NoError(t, err, "Vault stand-in should encode response without errors")
+	}))
+}
+
+func TestLoadSecretsFromVault(t *testing.T) {
+	for _, kvVersion := range []int{1, 2} {
+		secrets := map[string]map[string]interface{}{
+			"Alice": {"twitterAppKey": "aliceappkey", "port": 8080},
+			"bob":   {"twitterAppKey": "bobappkey"},
+		}
+		server := vaultStandIn(t, kvVersion, secrets)
+
+		cfg := config.Vault{Address: server.URL, Token: "token", Mount: "secret", Path: "aptomi", KVVersion: kvVersion}
+		loader := NewSecretLoaderFromVault(cfg, time.Hour)
+
+		aliceSecrets := loader.LoadSecretsByUserName("alice")
+		assert.Equal(t, map[string]string{"twitterAppKey": "aliceappkey", "port": "8080"}, aliceSecrets, "Secrets for alice should be loaded from vault (KV v%d)", kvVersion)
+		assert.Equal(t, "bobappkey", loader.LoadSecretsByUserName("Bob")["twitterAppKey"], "Secrets for bob should be loaded from vault (KV v%d)", kvVersion)
+		assert.Nil(t, loader.LoadSecretsByUserName("carol"), "There should be no secrets for carol (KV v%d)", kvVersion)
+
+		// previously loaded secrets should be used if vault is not available
+		server.Close()
+		loader.(*SecretLoaderFromVault).loadedAt = time.Time{}
+		assert.Equal(t, aliceSecrets, loader.LoadSecretsByUserName("alice"), "Previously loaded secrets should be used if vault is not available (KV v%d)", kvVersion)
+	}
+}
+
+func TestLoadSecretsFromVaultRefresh(t *testing.T) {
+	secrets := map[string]map[string]interface{}{
+		"alice": {"twitterAppKey": "aliceappkey"},
+	}
+	server := vaultStandIn(t, 1, secrets)
+	defer server.Close()
+
+	cfg := config.Vault{Address: server.URL, Token: "token", Mount: "secret", Path: "aptomi"}
+	loader := NewSecretLoaderFromVault(cfg, 50*time.Millisecond)
+	assert.Equal(t, "aliceappkey", loader.LoadSecretsByUserName("alice")["twitterAppKey"], "Secrets should be loaded from vault")
+
+	secrets["alice"]["twitterAppKey"] = "rotatedkey"
+	assert.Equal(t, "aliceappkey", loader.LoadSecretsByUserName("alice")["twitterAppKey"], "Cached secrets should be returned before refresh interval expires")
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, "rotatedkey", loader.LoadSecretsByUserName("alice")["twitterAppKey"], "Rotated secrets should be loaded after refresh interval expires")
+}
@@ -0,0 +1,58 @@
+package secrets
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewSecretLoader(t *testing.T) {
+	loader, err := NewSecretLoader(config.Secrets{Dir: "../../testdata/unittests"})
+	assert.NoError(t, err, "Secret loader should be created without errors")
+	assert.IsType(t, &SecretLoaderFromDir{}, loader, "Directory secret loader should be created")
+
+	loader, err = NewSecretLoader(config.Secrets{Vault: &config.Vault{Address: "http://127.0.0.1:8200", Token: "token", Mount: "secret", Path: "aptomi"}})
+	assert.NoError(t, err, "Secret loader should be created without errors")
+	assert.IsType(t, &SecretLoaderFromVault{}, loader, "Vault secret loader should be created")
+
+	_, err = NewSecretLoader(config.Secrets{Dir: "../../testdata/unittests", Vault: &config.Vault{}})
+	assert.Error(t, err, "Only one source of secrets should be allowed")
+}
+
+func TestCachedSecretLoaderConcurrent(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	loader := newCachedSecretLoader("test", time.Millisecond, func() (map[string]map[string]string, error) {
+		<-release
+		return map[string]map[string]string{"Alice": {"key": fmt.Sprintf("value-%d", atomic.AddInt32(&loads, 1))}}, nil
+	})
+
+	// concurrent callers wait for the first load, which happens only once
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "value-1", loader.LoadSecretsByUserName("alice")["key"], "Secrets should be loaded")
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	release <- struct{}{}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads), "Secrets should be loaded only once")
+
+	// while secrets are being reloaded, previously loaded ones are returned without waiting
+	time.Sleep(10 * time.Millisecond)
+	reloaded := make(chan map[string]string)
+	go func() {
+		reloaded <- loader.LoadSecretsByUserName("alice")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, "value-1", loader.LoadSecretsByUserName("alice")["key"], "Previously loaded secrets should be returned during reload")
+	release <- struct{}{}
+	assert.Equal(t, "value-2", (<-reloaded)["key"], "Secrets should be reloaded")
+}
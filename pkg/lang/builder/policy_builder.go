@@ -83,6 +83,11 @@ func (builder *PolicyBuilder) AddUser() *lang.User {
 	return result
 }
 
+// AddUserSecret adds a secret for a given user
+func (builder *PolicyBuilder) AddUserSecret(user *lang.User, secretName string, secretValue string) {
+	builder.secrets.AddSecret(user.Name, secretName, secretValue)
+}
+
 // PanicWhenLoadingUsers tells mock user loader to start panicking when loading users
 func (builder *PolicyBuilder) PanicWhenLoadingUsers() {
 	builder.users.SetPanic(true)
@@ -0,0 +1,92 @@
+package lang
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Aptomi/aptomi/pkg/util/cron"
+	"time"
+)
+
+// FreezeWindowObject is an informational data structure with Kind and Constructor for FreezeWindow
+var FreezeWindowObject = &runtime.Info{
+	Kind:        "freezewindow",
+	Storable:    true,
+	Versioned:   true,
+	Constructor: func() runtime.Object { return &FreezeWindow{} },
+}
+
+// FreezeWindow defines a period of time, during which changes of component instances in its scope are not allowed
+// (e.g. business-critical periods). Policy still gets resolved and revisions get recorded during a freeze window, but
+// actions on in-scope component instances get deferred until the window is over.
+//
+// Freeze window is either a single time range (From, To) or a recurring one, which starts every time Cron expression
+// fires and lasts for Duration. Freeze windows can only be defined in the system namespace.
+type FreezeWindow struct {
+	runtime.TypeKind `yaml:",inline"`
+	Metadata         `validate:"required"`
+
+	// From is the start of a single freeze window (RFC 3339 timestamp)
+	From string `yaml:"from,omitempty" validate:"omitempty,timestamp"`
+
+	// To is the end of a single freeze window (RFC 3339 timestamp)
+	To string `yaml:"to,omitempty" validate:"omitempty,timestamp"`
+
+	// Cron is a standard 5-field cron expression, defining when a recurring freeze window starts
+	Cron string `yaml:"cron,omitempty" validate:"omitempty,cron"`
+
+	// Duration is how long a recurring freeze window lasts
+	Duration time.Duration `yaml:"duration,omitempty" validate:"min=0"`
+
+	// Timezone is a time zone name from IANA database (e.g. "America/Los_Angeles"), in which Cron expression gets
+	// evaluated. If empty, UTC is used
+	Timezone string `yaml:"timezone,omitempty" validate:"omitempty,timezone"`
+
+	// Namespaces is a list of namespaces in scope of the freeze window. If empty, all namespaces are in scope
+	Namespaces []string `yaml:"namespaces,omitempty" validate:"omitempty,dive,identifier"`
+
+	// Clusters is a list of clusters in scope of the freeze window. If empty, all clusters are in scope
+	Clusters []string `yaml:"clusters,omitempty" validate:"omitempty,dive,identifier"`
+
+	// Labels is a set of labels, which component instance should have in order to be in scope of the freeze window.
+	// If empty, component instances with any labels are in scope
+	Labels map[string]string `yaml:"labels,omitempty" validate:"omitempty,labels"`
+}
+
+// IsActive returns true if freeze window is in effect at the given time
+func (window *FreezeWindow) IsActive(now time.Time) bool {
+	if len(window.Cron) > 0 {
+		schedule, err := cron.Parse(window.Cron)
+		if err != nil {
+			return false
+		}
+		location, err := time.LoadLocation(window.Timezone)
+		if err != nil {
+			return false
+		}
+		_, found := schedule.LastBefore(now.In(location), window.Duration)
+		return found
+	}
+
+	from, errFrom := time.Parse(time.RFC3339, window.From)
+	to, errTo := time.Parse(time.RFC3339, window.To)
+	if errFrom != nil || errTo != nil {
+		return false
+	}
+	return !now.Before(from) && now.Before(to)
+}
+
+// InScope returns true if component instance with the given namespace, cluster and labels is in scope of the freeze window
+func (window *FreezeWindow) InScope(namespace string, cluster string, labels map[string]string) bool {
+	if len(window.Namespaces) > 0 && !util.ContainsString(window.Namespaces, namespace) {
+		return false
+	}
+	if len(window.Clusters) > 0 && !util.ContainsString(window.Clusters, cluster) {
+		return false
+	}
+	for name, value := range window.Labels {
+		if labelValue, ok := labels[name]; !ok || labelValue != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package lang
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFreezeWindowIsActive(t *testing.T) {
+	now := time.Date(2018, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	// single time range
+	window := makeFreezeWindow(runtime.SystemNS, "2018-01-01T00:00:00Z", "2018-01-02T00:00:00Z", "", 0, nil)
+	assert.True(t, window.IsActive(now), "Freeze window should be active within time range")
+	assert.False(t, window.IsActive(now.AddDate(0, 0, 1)), "Freeze window should not be active after time range")
+	assert.False(t, window.IsActive(now.AddDate(0, 0, -1)), "Freeze window should not be active before time range")
+
+	// recurring window, every weekday from 9:00 for 8 hours (2018-01-01 is Monday)
+	window = makeFreezeWindow(runtime.SystemNS, "", "", "0 9 * * 1-5", 8*time.Hour, nil)
+	assert.True(t, window.IsActive(now), "Recurring freeze window should be active on Monday at 12:00")
+	assert.False(t, window.IsActive(now.Add(6*time.Hour)), "Recurring freeze window should not be active on Monday at 18:00")
+	assert.False(t, window.IsActive(now.AddDate(0, 0, 6)), "Recurring freeze window should not be active on Sunday")
+
+	// recurring window in another time zone (12:00 UTC is 21:00 in Tokyo, 6:00 in Chicago on 2018-01-01)
+	window.Timezone = "Asia/Tokyo"
+	assert.False(t, window.IsActive(now), "Recurring freeze window should not be active on Monday at 21:00 in Tokyo")
+	assert.True(t, window.IsActive(now.Add(-6*time.Hour)), "Recurring freeze window should be active on Monday at 15:00 in Tokyo")
+	window.Timezone = "America/Chicago"
+	assert.False(t, window.IsActive(now), "Recurring freeze window should not be active on Monday at 6:00 in Chicago")
+	assert.True(t, window.IsActive(now.Add(4*time.Hour)), "Recurring freeze window should be active on Monday at 10:00 in Chicago")
+}
+
+func TestFreezeWindowInScope(t *testing.T) {
+	window := makeFreezeWindow(runtime.SystemNS, "2018-01-01T00:00:00Z", "2018-01-02T00:00:00Z", "", 0, nil)
+	assert.True(t, window.InScope("main", "cluster", nil), "Freeze window without scope should cover everything")
+
+	window.Namespaces = []string{"prod"}
+	window.Clusters = []string{"cluster"}
+	window.Labels = map[string]string{"tier": "critical"}
+	assert.True(t, window.InScope("prod", "cluster", map[string]string{"tier": "critical", "other": "value"}), "Component instance should be in scope")
+	assert.False(t, window.InScope("main", "cluster", map[string]string{"tier": "critical"}), "Component instance in another namespace should not be in scope")
+	assert.False(t, window.InScope("prod", "other", map[string]string{"tier": "critical"}), "Component instance in another cluster should not be in scope")
+	assert.False(t, window.InScope("prod", "cluster", map[string]string{"tier": "regular"}), "Component instance with different labels should not be in scope")
+}
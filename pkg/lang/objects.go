@@ -11,6 +11,7 @@ var (
 		ClusterObject,
 		RuleObject,
 		ACLRuleObject,
+		FreezeWindowObject,
 	}
 
 	policyObjectsMap = make(map[runtime.Kind]bool)
@@ -8,25 +8,27 @@ import (
 // PolicyNamespace describes a specific namespace within Aptomi policy.
 // All policy objects get placed in the appropriate maps and structs within PolicyNamespace.
 type PolicyNamespace struct {
-	Name         string               `validate:"identifier"`
-	Services     map[string]*Service  `validate:"dive"`
-	Contracts    map[string]*Contract `validate:"dive"`
-	Clusters     map[string]*Cluster  `validate:"dive"`
-	Rules        *GlobalRules         `validate:"required"`
-	ACLRules     *GlobalRules         `validate:"required"`
-	Dependencies *GlobalDependencies  `validate:"required"`
+	Name          string                   `validate:"identifier"`
+	Services      map[string]*Service      `validate:"dive"`
+	Contracts     map[string]*Contract     `validate:"dive"`
+	Clusters      map[string]*Cluster      `validate:"dive"`
+	FreezeWindows map[string]*FreezeWindow `validate:"dive"`
+	Rules         *GlobalRules             `validate:"required"`
+	ACLRules      *GlobalRules             `validate:"required"`
+	Dependencies  *GlobalDependencies      `validate:"required"`
 }
 
 // NewPolicyNamespace creates a new PolicyNamespace
 func NewPolicyNamespace(name string) *PolicyNamespace {
 	return &PolicyNamespace{
-		Name:         name,
-		Services:     make(map[string]*Service),
-		Contracts:    make(map[string]*Contract),
-		Clusters:     make(map[string]*Cluster),
-		Rules:        NewGlobalRules(),
-		ACLRules:     NewGlobalRules(),
-		Dependencies: NewGlobalDependencies(),
+		Name:          name,
+		Services:      make(map[string]*Service),
+		Contracts:     make(map[string]*Contract),
+		Clusters:      make(map[string]*Cluster),
+		FreezeWindows: make(map[string]*FreezeWindow),
+		Rules:         NewGlobalRules(),
+		ACLRules:      NewGlobalRules(),
+		Dependencies:  NewGlobalDependencies(),
 	}
 }
 
@@ -38,6 +40,8 @@ func (policyNamespace *PolicyNamespace) addObject(obj Base) error {
 		policyNamespace.Contracts[obj.GetName()] = obj.(*Contract)
 	case ClusterObject.Kind:
 		policyNamespace.Clusters[obj.GetName()] = obj.(*Cluster)
+	case FreezeWindowObject.Kind:
+		policyNamespace.FreezeWindows[obj.GetName()] = obj.(*FreezeWindow)
 	case RuleObject.Kind:
 		policyNamespace.Rules.addRule(obj.(*Rule))
 	case ACLRuleObject.Kind:
@@ -67,6 +71,11 @@ func (policyNamespace *PolicyNamespace) removeObject(obj Base) bool {
 			delete(policyNamespace.Clusters, obj.GetName())
 			return true
 		}
+	case FreezeWindowObject.Kind:
+		if _, exist := policyNamespace.FreezeWindows[obj.GetName()]; exist {
+			delete(policyNamespace.FreezeWindows, obj.GetName())
+			return true
+		}
 	case RuleObject.Kind:
 		return policyNamespace.Rules.removeRule(obj.(*Rule))
 	case ACLRuleObject.Kind:
@@ -93,6 +102,10 @@ func (policyNamespace *PolicyNamespace) getObjectsByKind(kind string) []Base {
 		for _, cluster := range policyNamespace.Clusters {
 			result = append(result, cluster)
 		}
+	case FreezeWindowObject.Kind:
+		for _, window := range policyNamespace.FreezeWindows {
+			result = append(result, window)
+		}
 	case RuleObject.Kind:
 		for _, rule := range policyNamespace.Rules.Rules {
 			result = append(result, rule)
@@ -129,6 +142,10 @@ func (policyNamespace *PolicyNamespace) getObject(kind string, name string) (run
 		if result, ok = policyNamespace.Clusters[name]; !ok {
 			return nil, nil
 		}
+	case FreezeWindowObject.Kind:
+		if result, ok = policyNamespace.FreezeWindows[name]; !ok {
+			return nil, nil
+		}
 	case RuleObject.Kind:
 		if result, ok = policyNamespace.Rules.RuleMap[name]; !ok {
 			return nil, nil
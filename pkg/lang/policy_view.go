@@ -79,3 +79,22 @@ func (view *PolicyView) CanConsume(service *Service) (bool, error) {
 	}
 	return true, nil
 }
+
+// CanApprove returns if user has permissions to approve changes of component instances in a given namespace.
+// If a user can manage services in a given namespace, then he can approve changes of their instances
+func (view *PolicyView) CanApprove(namespace string) (bool, error) {
+	obj := &Service{
+		TypeKind: ServiceObject.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: namespace,
+		},
+	}
+	privilege, err := view.Policy.aclResolver.GetUserPrivileges(view.User, obj)
+	if err != nil {
+		return false, err
+	}
+	if !privilege.Manage {
+		return false, fmt.Errorf("user '%s' doesn't have ACL permissions to approve changes in namespace '%s'", view.User.Name, namespace)
+	}
+	return true, nil
+}
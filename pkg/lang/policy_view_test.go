@@ -75,6 +75,20 @@ func TestPolicyViewCommonObjects(t *testing.T) {
 		}
 	}
 	assert.Equal(t, []int{0, 0, 0}, errCntConsume, "PolicyView.CanConsume() should work correctly")
+
+	// check CanApprove(), only domain and namespace admins can approve changes
+	services := policy.GetObjectsByKind(ServiceObject.Kind)
+	assert.NotEmpty(t, services, "Service list should not be empty")
+	errCntApprove := []int{0, 0, 0}
+	for i := 0; i < len(users); i++ {
+		policyView := policy.View(users[i])
+		for _, obj := range services {
+			if _, err := policyView.CanApprove(obj.GetNamespace()); err != nil {
+				errCntApprove[i]++
+			}
+		}
+	}
+	assert.Equal(t, []int{0, 0, len(services)}, errCntApprove, "PolicyView.CanApprove() should work correctly")
 }
 
 func TestPolicyViewManageACLRules(t *testing.T) {
@@ -23,7 +23,7 @@ const namespaceAll = "*"
 // ACLRole is a struct for defining user roles and their privileges.
 // Aptomi has 4 built-in user roles: domain admin, namespace admin, service consumer, and nobody.
 // Domain admin has full access rights to all namespaces. It can manage global objects in 'system' namespace (clusters,
-// rules, and ACL rules).
+// rules, ACL rules, and freeze windows).
 // Namespace admin has full access right to a given set of namespaces, but it cannot global objects in 'system' namespace (clusters,
 // rules, and ACL rules).
 // Service consumer can only consume services within a given set of namespaces. Service consumption is treated as capability
@@ -98,9 +98,10 @@ var domainAdmin = &ACLRole{
 			RuleObject.Kind:       fullAccess,
 		},
 		GlobalObjects: map[string]*Privilege{
-			ClusterObject.Kind: fullAccess,
-			RuleObject.Kind:    fullAccess,
-			ACLRuleObject.Kind: fullAccess,
+			ClusterObject.Kind:      fullAccess,
+			RuleObject.Kind:         fullAccess,
+			ACLRuleObject.Kind:      fullAccess,
+			FreezeWindowObject.Kind: fullAccess,
 		},
 	},
 }
@@ -117,9 +118,10 @@ var namespaceAdmin = &ACLRole{
 			RuleObject.Kind:       fullAccess,
 		},
 		GlobalObjects: map[string]*Privilege{
-			ClusterObject.Kind: viewAccess,
-			RuleObject.Kind:    viewAccess,
-			ACLRuleObject.Kind: viewAccess,
+			ClusterObject.Kind:      viewAccess,
+			RuleObject.Kind:         viewAccess,
+			ACLRuleObject.Kind:      viewAccess,
+			FreezeWindowObject.Kind: viewAccess,
 		},
 	},
 }
@@ -136,9 +138,10 @@ var serviceConsumer = &ACLRole{
 			RuleObject.Kind:       viewAccess,
 		},
 		GlobalObjects: map[string]*Privilege{
-			ClusterObject.Kind: viewAccess,
-			RuleObject.Kind:    viewAccess,
-			ACLRuleObject.Kind: viewAccess,
+			ClusterObject.Kind:      viewAccess,
+			RuleObject.Kind:         viewAccess,
+			ACLRuleObject.Kind:      viewAccess,
+			FreezeWindowObject.Kind: viewAccess,
 		},
 	},
 }
@@ -155,9 +158,10 @@ var nobody = &ACLRole{
 			RuleObject.Kind:       viewAccess,
 		},
 		GlobalObjects: map[string]*Privilege{
-			ClusterObject.Kind: viewAccess,
-			RuleObject.Kind:    viewAccess,
-			ACLRuleObject.Kind: viewAccess,
+			ClusterObject.Kind:      viewAccess,
+			RuleObject.Kind:         viewAccess,
+			ACLRuleObject.Kind:      viewAccess,
+			FreezeWindowObject.Kind: viewAccess,
 		},
 	},
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
 	"sync"
+	"time"
 )
 
 // ServiceObject is an informational data structure with Kind and Constructor for Service
@@ -72,6 +73,10 @@ type Code struct {
 	// and can refer to arbitrary labels, as well as discovery parameters exposed by other components (within the
 	// current service) and discovery parameters exposed by services the current service depends on
 	Params util.NestedParameterMap `validate:"omitempty,templateNestedMap"`
+
+	// ReadyTimeout, if set, overrides how long dependent components should wait for the component instance to become
+	// ready after it gets created or updated (it only has effect when enforcer is configured to wait for readiness)
+	ReadyTimeout time.Duration `yaml:"readytimeout,omitempty"`
 }
 
 // GetComponentsMap lazily initializes and returns a map of name -> component, while being thread-safe
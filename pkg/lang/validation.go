@@ -7,6 +7,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/lang/template"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Aptomi/aptomi/pkg/util/cron"
 	english "github.com/go-playground/locales/en"
 	"github.com/go-playground/universal-translator"
 	"gopkg.in/go-playground/validator.v9"
@@ -14,13 +15,14 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Constants
 var (
 	identifierRegex = "^[a-zA-Z][a-zA-Z0-9_-]{0,63}$"
 	clusterTypes    = []string{"kubernetes"}
-	codeTypes       = []string{"helm", "aptomi/code/kubernetes-helm"}
+	codeTypes       = []string{"helm", "aptomi/code/kubernetes-helm", "kubernetes-raw", "aptomi/code/kubernetes-raw"}
 	labelOpsKeys    = []string{"set", "remove"}
 	allowReject     = []string{"allow", "reject"}
 )
@@ -70,10 +72,14 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 	_ = result.RegisterValidation("labelOperations", validateLabelOperations)
 	_ = result.RegisterValidation("allowReject", validateAllowRejectAction)
 	_ = result.RegisterValidation("addRoleNS", validateACLRoleActionMap)
+	_ = result.RegisterValidation("timestamp", validateTimestamp)
+	_ = result.RegisterValidation("cron", validateCron)
+	_ = result.RegisterValidation("timezone", validateTimezone)
 
 	// validators with context containing policy
 	result.RegisterStructValidation(validateRule, Rule{})
 	result.RegisterStructValidation(validateCluster, Cluster{})
+	result.RegisterStructValidation(validateFreezeWindow, FreezeWindow{})
 	result.RegisterStructValidationCtx(validateService, Service{})
 	result.RegisterStructValidationCtx(validateDependency, Dependency{})
 	result.RegisterStructValidationCtx(validateContract, Contract{})
@@ -136,6 +142,18 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 			tag:         "addRoleNS",
 			translation: fmt.Sprintf("{0} must be a valid role assignment map (key must be in %s, namespace list must be comma-separated identifiers/wildcards)", util.GetSortedStringKeys(ACLRolesMap)),
 		},
+		{
+			tag:         "timestamp",
+			translation: fmt.Sprintf("{0} must be a valid RFC 3339 timestamp, but found '{1}'"),
+		},
+		{
+			tag:         "cron",
+			translation: fmt.Sprintf("{0} must be a valid 5-field cron expression, but found '{1}'"),
+		},
+		{
+			tag:         "timezone",
+			translation: fmt.Sprintf("{0} must be a valid IANA time zone name, but found '{1}'"),
+		},
 		// dynamic/custom
 		{
 			tag:         "exists",
@@ -161,6 +179,10 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 			tag:         "aclRuleActions",
 			translation: fmt.Sprintf("{0} is a required field for ACL rule. Must specify role assignment map"),
 		},
+		{
+			tag:         "freezeWindowTime",
+			translation: fmt.Sprintf("{0} must define either a time range (from, to) or a recurring schedule (cron, duration)"),
+		},
 		{
 			tag:         "systemNS",
 			translation: fmt.Sprintf("{0} must be '%s', but found '{1}'", runtime.SystemNS),
@@ -224,6 +246,24 @@ func validateAllowRejectAction(fl validator.FieldLevel) bool {
 	return util.ContainsString(allowReject, fl.Field().String())
 }
 
+// checks if a given string is a valid RFC 3339 timestamp
+func validateTimestamp(fl validator.FieldLevel) bool {
+	_, err := time.Parse(time.RFC3339, fl.Field().String())
+	return err == nil
+}
+
+// checks if a given string is a valid cron expression
+func validateCron(fl validator.FieldLevel) bool {
+	_, err := cron.Parse(fl.Field().String())
+	return err == nil
+}
+
+// checks if a given string is a valid time zone name
+func validateTimezone(fl validator.FieldLevel) bool {
+	_, err := time.LoadLocation(fl.Field().String())
+	return err == nil
+}
+
 // checks if a given string is a valid cluster type
 func validateClusterType(fl validator.FieldLevel) bool {
 	return util.ContainsString(clusterTypes, fl.Field().String())
@@ -429,6 +469,31 @@ func validateCluster(sl validator.StructLevel) {
 	}
 }
 
+// checks if freeze window is valid
+func validateFreezeWindow(sl validator.StructLevel) {
+	window := sl.Current().Addr().Interface().(*FreezeWindow)
+	if window.Namespace != runtime.SystemNS {
+		sl.ReportError(window.Namespace, "Namespace", "", "systemNS", "")
+	}
+
+	// freeze window should be either a single time range or a recurring one
+	timeRange := len(window.From) > 0 && len(window.To) > 0 && len(window.Cron) <= 0 && window.Duration <= 0
+	recurring := len(window.From) <= 0 && len(window.To) <= 0 && len(window.Cron) > 0 && window.Duration > 0
+	if !timeRange && !recurring {
+		sl.ReportError(window, "From|To|Cron|Duration", "", "freezeWindowTime", "")
+		return
+	}
+
+	// time range should end after it starts
+	if timeRange {
+		from, errFrom := time.Parse(time.RFC3339, window.From)
+		to, errTo := time.Parse(time.RFC3339, window.To)
+		if errFrom == nil && errTo == nil && !to.After(from) {
+			sl.ReportError(window.To, "To", "", "freezeWindowTime", "")
+		}
+	}
+}
+
 func isIdentifier(id string) bool {
 	ok, err := regexp.MatchString(identifierRegex, id)
 	return ok && err == nil
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"strconv"
 	"testing"
+	"time"
 )
 
 const (
@@ -153,6 +154,33 @@ func TestPolicyValidationCluster(t *testing.T) {
 	})
 }
 
+func TestPolicyValidationFreezeWindow(t *testing.T) {
+	// Freeze windows (Time range or Cron & Duration, Scope)
+	runValidationTests(t, ResSuccess, true, []Base{
+		makeFreezeWindow(runtime.SystemNS, "2018-01-01T00:00:00Z", "2018-01-02T00:00:00Z", "", 0, nil),
+		makeFreezeWindow(runtime.SystemNS, "", "", "0 9 * * 1-5", 8*time.Hour, []string{"main"}),
+	})
+	runValidationTests(t, ResFailure, true, []Base{
+		makeFreezeWindow("main", "2018-01-01T00:00:00Z", "2018-01-02T00:00:00Z", "", 0, nil),
+		makeFreezeWindow(runtime.SystemNS, "", "", "", 0, nil),
+		makeFreezeWindow(runtime.SystemNS, "2018-01-01", "2018-01-02T00:00:00Z", "", 0, nil),
+		makeFreezeWindow(runtime.SystemNS, "2018-01-02T00:00:00Z", "2018-01-01T00:00:00Z", "", 0, nil),
+		makeFreezeWindow(runtime.SystemNS, "2018-01-01T00:00:00Z", "", "", 0, nil),
+		makeFreezeWindow(runtime.SystemNS, "", "", "0 9 * *", 8*time.Hour, nil),
+		makeFreezeWindow(runtime.SystemNS, "", "", "0 9 * * 1-5", 0, nil),
+		makeFreezeWindow(runtime.SystemNS, "2018-01-01T00:00:00Z", "2018-01-02T00:00:00Z", "0 9 * * 1-5", 8*time.Hour, nil),
+		makeFreezeWindow(runtime.SystemNS, "", "", "0 9 * * 1-5", 8*time.Hour, []string{"1-invalid"}),
+	})
+
+	// Freeze windows (Timezone)
+	window := makeFreezeWindow(runtime.SystemNS, "", "", "0 9 * * 1-5", 8*time.Hour, nil)
+	window.Timezone = "Europe/London"
+	runValidationTests(t, ResSuccess, true, []Base{window})
+	window = makeFreezeWindow(runtime.SystemNS, "", "", "0 9 * * 1-5", 8*time.Hour, nil)
+	window.Timezone = "Mars/Olympus"
+	runValidationTests(t, ResFailure, true, []Base{window})
+}
+
 func runValidationTests(t *testing.T, result int, every bool, objects []Base) {
 	t.Helper()
 
@@ -313,6 +341,21 @@ func makeCluster(clusterType, ns string) *Cluster {
 	}
 }
 
+func makeFreezeWindow(ns, from, to, cron string, duration time.Duration, namespaces []string) *FreezeWindow {
+	return &FreezeWindow{
+		TypeKind: FreezeWindowObject.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: ns,
+			Name:      "freeze",
+		},
+		From:       from,
+		To:         to,
+		Cron:       cron,
+		Duration:   duration,
+		Namespaces: namespaces,
+	}
+}
+
 func makeService(name string, labelNum int) *Service {
 	service := &Service{
 		TypeKind: ServiceObject.GetTypeKind(),
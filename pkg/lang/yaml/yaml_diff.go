@@ -0,0 +1,110 @@
+package yaml
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"sort"
+	"strings"
+)
+
+// FieldDiff represents a difference in a single field of two objects serialized into YAML. From is empty if the
+// field has been added and To is empty if the field has been removed
+type FieldDiff struct {
+	Path string
+	From string `yaml:",omitempty"`
+	To   string `yaml:",omitempty"`
+}
+
+func (diff *FieldDiff) String() string {
+	return fmt.Sprintf("%s: %s -> %s", diff.Path, valueOrNone(diff.From), valueOrNone(diff.To))
+}
+
+// DiffObjects serializes both objects into YAML and returns a list of fields with different values, sorted by path.
+// Fields are identified by dot-separated paths, list elements are identified by their index (e.g. "a.b[0].c")
+func DiffObjects(from interface{}, to interface{}) ([]*FieldDiff, error) {
+	fromFields, err := flattenObject(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toFields, err := flattenObject(to)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for path := range fromFields {
+		paths = append(paths, path)
+	}
+	for path := range toFields {
+		if _, exist := fromFields[path]; !exist {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	result := []*FieldDiff{}
+	for _, path := range paths {
+		if fromFields[path] != toFields[path] {
+			result = append(result, &FieldDiff{Path: path, From: fromFields[path], To: toFields[path]})
+		}
+	}
+
+	return result, nil
+}
+
+// flattenObject serializes object into YAML and returns map from field path into its scalar value
+func flattenObject(obj interface{}) (map[string]string, error) {
+	result := make(map[string]string)
+	if obj == nil {
+		return result, nil
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("can't serialize object '%+v': %s", obj, err)
+	}
+
+	var generic interface{}
+	err = yaml.Unmarshal(data, &generic)
+	if err != nil {
+		return nil, fmt.Errorf("can't unmarshal serialized object '%+v': %s", obj, err)
+	}
+
+	flattenValue("", generic, result)
+	return result, nil
+}
+
+func flattenValue(path string, value interface{}, result map[string]string) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		if len(v) == 0 {
+			result[path] = "{}"
+		}
+		for key, item := range v {
+			keyStr := fmt.Sprintf("%v", key)
+			if len(path) > 0 {
+				keyStr = path + "." + keyStr
+			}
+			flattenValue(keyStr, item, result)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			result[path] = "[]"
+		}
+		for idx, item := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", path, idx), item, result)
+		}
+	case nil:
+		result[path] = "null"
+	default:
+		result[path] = strings.TrimSpace(fmt.Sprintf("%v", v))
+	}
+}
+
+func valueOrNone(value string) string {
+	if len(value) == 0 {
+		return "(none)"
+	}
+	return value
+}
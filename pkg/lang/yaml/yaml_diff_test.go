@@ -0,0 +1,41 @@
+package yaml
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type diffTestObject struct {
+	Name   string
+	Labels map[string]string
+	Items  []string
+}
+
+func TestDiffObjects(t *testing.T) {
+	from := &diffTestObject{
+		Name:   "a",
+		Labels: map[string]string{"l1": "v1", "l2": "v2"},
+		Items:  []string{"x", "y"},
+	}
+	to := &diffTestObject{
+		Name:   "b",
+		Labels: map[string]string{"l1": "v1", "l3": "v3"},
+		Items:  []string{"x"},
+	}
+
+	diff, err := DiffObjects(from, to)
+	assert.NoError(t, err, "Objects should be compared without errors")
+	assert.Equal(t, []*FieldDiff{
+		{Path: "items[1]", From: "y"},
+		{Path: "labels.l2", From: "v2"},
+		{Path: "labels.l3", To: "v3"},
+		{Path: "name", From: "a", To: "b"},
+	}, diff, "Diff should contain all changed fields sorted by path")
+
+	diff, err = DiffObjects(from, from)
+	assert.NoError(t, err, "Objects should be compared without errors")
+	assert.Empty(t, diff, "Diff should be empty for equal objects")
+
+	assert.Equal(t, "name: a -> b", (&FieldDiff{Path: "name", From: "a", To: "b"}).String())
+	assert.Equal(t, "name: (none) -> b", (&FieldDiff{Path: "name", To: "b"}).String())
+}
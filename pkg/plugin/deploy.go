@@ -1,19 +1,85 @@
 package plugin
 
 import (
+	"context"
+	"fmt"
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/util"
+	"time"
 )
 
 // DeployPlugin is a definition of deployment plugin which takes care of creating, updating and destroying
-// component instances in the cloud
+// component instances in the cloud. Plugins should stop processing and return an error once the given context is done
 type DeployPlugin interface {
 	Plugin
 
 	GetSupportedCodeTypes() []string
-	Create(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error
-	Update(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error
-	Destroy(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error
-	Endpoints(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error)
+	Create(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error
+	Update(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error
+	Destroy(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error
+	Endpoints(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error)
+}
+
+// ReadinessPlugin is an optional interface, which could be implemented by deployment plugins to report whether
+// component instance deployed in the cloud is ready (e.g. all its pods are up and running)
+type ReadinessPlugin interface {
+	Status(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (bool, error)
+}
+
+// LiveStatePlugin is an optional interface, which could be implemented by deployment plugins to read back the live
+// state of component instance deployed in the cloud. It's used to detect drift, i.e. changes made in the cloud
+// bypassing Aptomi (e.g. Helm release deleted or upgraded by hand)
+type LiveStatePlugin interface {
+	// LiveState returns the state component instance with the given deploy name has in the cloud right now
+	LiveState(ctx context.Context, cluster *lang.Cluster, deployName string, eventLog *event.Log) (*LiveState, error)
+
+	// ExpectedLiveState returns the state component instance is expected to have in the cloud, once it's deployed
+	// with the given params
+	ExpectedLiveState(params util.NestedParameterMap) (*LiveState, error)
+}
+
+// GCPlugin is an optional interface, which could be implemented by deployment plugins to find and delete deployments
+// left in the cloud, which aren't referenced by any component instance (e.g. after failed deletes or actual state
+// reset)
+type GCPlugin interface {
+	// Orphans returns names of deployments made by the plugin in the cluster, which don't correspond to any of the
+	// given deploy names
+	Orphans(ctx context.Context, cluster *lang.Cluster, deployNames []string, eventLog *event.Log) ([]string, error)
+
+	// DestroyOrphan deletes deployment with the given name, as returned by Orphans
+	DestroyOrphan(ctx context.Context, cluster *lang.Cluster, name string, eventLog *event.Log) error
+}
+
+// LiveState represents the state of component instance deployed in the cloud
+type LiveState struct {
+	// Exists is true if component instance is deployed in the cloud (e.g. Helm release exists)
+	Exists bool
+
+	// Version is the version of the deployed code (e.g. Helm chart version)
+	Version string
+
+	// ParamsHash is the hash of params component instance is deployed with (e.g. Helm release values), see util.HashObject
+	ParamsHash string
+
+	// CreatedAt is when component instance was deployed in the cloud for the first time (zero if not known)
+	CreatedAt time.Time
+
+	// UpdatedAt is the last time component instance was updated in the cloud (zero if not known)
+	UpdatedAt time.Time
+}
+
+// Drift returns the explanation of how the live state differs from the expected one. It returns an empty string if
+// there is no drift
+func (state *LiveState) Drift(expected *LiveState) string {
+	if !state.Exists {
+		return "not found in the cloud"
+	}
+	if state.Version != expected.Version {
+		return fmt.Sprintf("version is %s, while expected %s", state.Version, expected.Version)
+	}
+	if state.ParamsHash != expected.ParamsHash {
+		return "params have been changed"
+	}
+	return ""
 }
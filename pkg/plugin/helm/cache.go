@@ -12,6 +12,7 @@ type clusterCache struct {
 	cluster         *lang.Cluster
 	config          *Config
 	lock            sync.Mutex // all caching ops should use this lock
+	inited          bool       // true if cache has been initialized successfully
 	kubeConfig      *rest.Config
 	namespace       string
 	tillerNamespace string
@@ -22,13 +23,14 @@ type clusterCache struct {
 }
 
 func (plugin *Plugin) getClusterCache(cluster *lang.Cluster, eventLog *event.Log) (*clusterCache, error) {
-	rawCache, loaded := plugin.cache.LoadOrStore(cluster.Name, new(clusterCache))
+	rawCache, _ := plugin.cache.LoadOrStore(cluster.Name, new(clusterCache))
 	cache := rawCache.(*clusterCache)
-	if !loaded {
-		err := cache.init(cluster, eventLog)
-		if err != nil {
-			return nil, err
-		}
+
+	// init is called every time, as cache could be requested by multiple actions running in parallel and each of them
+	// should wait for it to be initialized
+	err := cache.init(cluster, eventLog)
+	if err != nil {
+		return nil, err
 	}
 
 	return cache, nil
@@ -38,10 +40,20 @@ func (cache *clusterCache) init(cluster *lang.Cluster, eventLog *event.Log) erro
 	cache.lock.Lock()
 	defer cache.lock.Unlock()
 
+	if cache.inited {
+		return nil
+	}
+
 	err := cache.initConfig(cluster)
 	if err != nil {
 		return err
 	}
 
-	return cache.ensureTillerTunnel(eventLog)
+	err = cache.ensureTillerTunnel(eventLog)
+	if err != nil {
+		return err
+	}
+
+	cache.inited = true
+	return nil
 }
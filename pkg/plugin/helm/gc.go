@@ -0,0 +1,109 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"sort"
+)
+
+// releaseListLimit is the max number of Helm releases retrieved from Tiller at once
+const releaseListLimit = 256
+
+// releaseStatuses contains all statuses Helm release could have, as releases should be garbage-collected regardless of
+// their status (e.g. deleted, but not purged ones, or the ones which got stuck while being installed)
+var releaseStatuses = []release.Status_Code{
+	release.Status_UNKNOWN,
+	release.Status_DEPLOYED,
+	release.Status_DELETED,
+	release.Status_SUPERSEDED,
+	release.Status_FAILED,
+	release.Status_DELETING,
+	release.Status_PENDING_INSTALL,
+	release.Status_PENDING_UPGRADE,
+	release.Status_PENDING_ROLLBACK,
+}
+
+// Orphans returns names of Helm releases in the cluster, which don't correspond to any of the given deploy names.
+// Only Helm releases deployed by Aptomi (i.e. the ones in the namespace Aptomi deploys into, which have the release
+// marker matching their names) are considered, all other releases are left untouched
+func (plugin *Plugin) Orphans(ctx context.Context, cluster *lang.Cluster, deployNames []string, eventLog *event.Log) ([]string, error) {
+	var result []string
+	err := util.RunWithContext(ctx, func() error {
+		var errOrphans error
+		result, errOrphans = plugin.orphans(cluster, deployNames, eventLog)
+		return errOrphans
+	})
+	return result, err
+}
+
+func (plugin *Plugin) orphans(cluster *lang.Cluster, deployNames []string, eventLog *event.Log) ([]string, error) {
+	cache, err := plugin.getClusterCache(cluster, eventLog)
+	if err != nil {
+		return nil, err
+	}
+
+	helmClient, err := cache.newHelmClient(eventLog)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := []*release.Release{}
+	offset := ""
+	for {
+		resp, errList := helmClient.ListReleases(
+			helm.ReleaseListNamespace(cache.namespace),
+			helm.ReleaseListStatuses(releaseStatuses),
+			helm.ReleaseListLimit(releaseListLimit),
+			helm.ReleaseListOffset(offset),
+		)
+		if errList != nil {
+			return nil, fmt.Errorf("error while listing Helm releases in cluster '%s': %s", cluster.Name, errList)
+		}
+		if resp == nil {
+			break
+		}
+
+		releases = append(releases, resp.Releases...)
+
+		if len(resp.Next) == 0 {
+			break
+		}
+		offset = resp.Next
+	}
+
+	return orphanReleases(releases, deployNames), nil
+}
+
+// orphanReleases returns sorted names of the given Helm releases, which have been deployed by Aptomi, but don't
+// correspond to any of the given deploy names. Every release name is returned once, even if there are multiple
+// revisions of the release
+func orphanReleases(releases []*release.Release, deployNames []string) []string {
+	known := make(map[string]bool)
+	for _, deployName := range deployNames {
+		known[getHelmReleaseName(deployName)] = true
+	}
+
+	result := []string{}
+	for _, rel := range releases {
+		if known[rel.Name] || !isManagedRelease(rel) {
+			continue
+		}
+		known[rel.Name] = true
+		result = append(result, rel.Name)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// DestroyOrphan deletes Helm release returned by Orphans
+func (plugin *Plugin) DestroyOrphan(ctx context.Context, cluster *lang.Cluster, name string, eventLog *event.Log) error {
+	return util.RunExclusiveWithContext(ctx, exclusiveKey(cluster, name), func() error {
+		return plugin.destroyRelease(cluster, name, eventLog)
+	})
+}
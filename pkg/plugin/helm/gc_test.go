@@ -0,0 +1,58 @@
+package helm
+
+import (
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"testing"
+)
+
+func makeManagedRelease(t *testing.T, deployName string, code release.Status_Code) *release.Release {
+	t.Helper()
+	values, err := getHelmReleaseValues(deployName, util.NestedParameterMap{"chartName": "chart"})
+	if !assert.NoError(t, err, "Release values should be serialized") {
+		t.FailNow()
+	}
+	rel := makeRelease(code)
+	rel.Name = getHelmReleaseName(deployName)
+	rel.Config = &chart.Config{Raw: string(values)}
+	return rel
+}
+
+func TestOrphanReleases(t *testing.T) {
+	releases := []*release.Release{
+		// managed releases referenced by component instances
+		makeManagedRelease(t, "main#contract#context#web", release.Status_DEPLOYED),
+		makeManagedRelease(t, "main#contract#context#web", release.Status_SUPERSEDED),
+
+		// managed releases not referenced by component instances, regardless of their status
+		makeManagedRelease(t, "main#contract#context#db", release.Status_DEPLOYED),
+		makeManagedRelease(t, "main#contract#context#db", release.Status_SUPERSEDED),
+		makeManagedRelease(t, "main#contract#old#web", release.Status_DELETED),
+		makeManagedRelease(t, "main#contract#failed#web", release.Status_FAILED),
+		makeManagedRelease(t, "main#contract#stuck#web", release.Status_PENDING_INSTALL),
+
+		// releases not deployed by Aptomi
+		makeRelease(release.Status_DEPLOYED),
+		{Name: "no-values", Info: &release.Info{Status: &release.Status{Code: release.Status_DEPLOYED}}},
+	}
+
+	// release with the marker, which doesn't match its name (e.g. values copied from another release)
+	copied := makeManagedRelease(t, "main#contract#context#cache", release.Status_DEPLOYED)
+	copied.Name = "cache"
+	releases = append(releases, copied)
+
+	orphans := orphanReleases(releases, []string{"main#contract#context#web"})
+	assert.Equal(t, []string{
+		"main-contract-context-db",
+		"main-contract-failed-web",
+		"main-contract-old-web",
+		"main-contract-stuck-web",
+	}, orphans, "Only managed releases not referenced by component instances should be orphans")
+
+	// release marker shouldn't affect values used to detect drift
+	values, err := getReleaseValues(releases[0])
+	assert.NoError(t, err, "Release values should be read")
+	assert.Equal(t, util.NestedParameterMap{"chartName": "chart"}, values, "Release values should not include release marker")
+}
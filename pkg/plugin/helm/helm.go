@@ -1,17 +1,20 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/lang"
+	pluginapi "github.com/Aptomi/aptomi/pkg/plugin"
 	"github.com/Aptomi/aptomi/pkg/util"
-	"github.com/pmezard/go-difflib/difflib"
-	"gopkg.in/yaml.v2"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	api "k8s.io/client-go/pkg/api/v1"
 	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
 	"strings"
+	"time"
 )
 
 var helmCodeTypes = []string{"helm", "aptomi/code/kubernetes-helm"}
@@ -22,13 +25,17 @@ func (plugin *Plugin) GetSupportedCodeTypes() []string {
 }
 
 // Create implements creation of a new component instance in the cloud by deploying a Helm chart
-func (plugin *Plugin) Create(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
-	return plugin.createOrUpdate(cluster, deployName, params, eventLog, true)
+func (plugin *Plugin) Create(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return util.RunExclusiveWithContext(ctx, exclusiveKey(cluster, getHelmReleaseName(deployName)), func() error {
+		return plugin.createOrUpdate(cluster, deployName, params, eventLog, true)
+	})
 }
 
 // Update implements update of an existing component instance in the cloud by updating parameters of a helm chart
-func (plugin *Plugin) Update(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
-	return plugin.createOrUpdate(cluster, deployName, params, eventLog, true)
+func (plugin *Plugin) Update(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return util.RunExclusiveWithContext(ctx, exclusiveKey(cluster, getHelmReleaseName(deployName)), func() error {
+		return plugin.createOrUpdate(cluster, deployName, params, eventLog, true)
+	})
 }
 
 func (plugin *Plugin) createOrUpdate(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log, create bool) error {
@@ -53,13 +60,13 @@ func (plugin *Plugin) createOrUpdate(cluster *lang.Cluster, deployName string, p
 		return err
 	}
 
-	helmParams, err := yaml.Marshal(params)
+	helmParams, err := getHelmReleaseValues(deployName, params)
 	if err != nil {
 		return err
 	}
 
 	currRelease, err := helmClient.ReleaseContent(releaseName)
-	if err != nil && !strings.Contains(err.Error(), "not found") {
+	if err != nil && !isReleaseNotFound(err, releaseName) {
 		return fmt.Errorf("error while looking for Helm release %s: %s", releaseName, err)
 	}
 
@@ -72,7 +79,6 @@ func (plugin *Plugin) createOrUpdate(cluster *lang.Cluster, deployName string, p
 				"release": releaseName,
 				"chart":   chartName,
 				"path":    chartPath,
-				"params":  string(helmParams),
 			}).Infof("Installing Helm release '%s', chart '%s', cluster: '%s'", releaseName, chartName, cluster.Name)
 
 			_, err = helmClient.InstallRelease(chartPath, cache.namespace, helm.ReleaseName(releaseName), helm.ValueOverrides(helmParams), helm.InstallReuseName(true))
@@ -85,7 +91,6 @@ func (plugin *Plugin) createOrUpdate(cluster *lang.Cluster, deployName string, p
 		"release": releaseName,
 		"chart":   chartName,
 		"path":    chartPath,
-		"params":  string(helmParams),
 	}).Infof("Updating Helm release '%s', chart '%s', cluster: '%s'", releaseName, chartName, cluster.Name)
 
 	newRelease, err := helmClient.UpdateRelease(releaseName, chartPath, helm.UpdateValueOverrides(helmParams))
@@ -93,42 +98,45 @@ func (plugin *Plugin) createOrUpdate(cluster *lang.Cluster, deployName string, p
 		return err
 	}
 
-	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
-		A:        difflib.SplitLines(currRelease.Release.Manifest),
-		B:        difflib.SplitLines(newRelease.Release.Manifest),
-		FromFile: "Previous",
-		ToFile:   "Current",
-		Context:  3,
-	})
-	if err != nil {
-		return fmt.Errorf("error while calculating diff between chart manifests for Helm release '%s', chart '%s', cluster: '%s'", releaseName, chartName, cluster.Name)
-	}
-
-	if len(diff) == 0 {
-		diff = "without changes"
-	} else {
-		diff = "with diff: \n\n" + diff
+	// neither params nor manifests are logged, as they may contain user secrets, while event logs are persisted and
+	// served via API
+	changes := "without changes"
+	if currRelease.Release.Manifest != newRelease.Release.Manifest {
+		changes = "with changes"
 	}
 
 	eventLog.WithFields(event.Fields{
 		"release": releaseName,
 		"chart":   chartName,
 		"path":    chartPath,
-		"params":  string(helmParams),
-	}).Debugf("Updated Helm release '%s', chart '%s', cluster: '%s' %s", releaseName, chartName, cluster.Name, diff)
+	}).Debugf("Updated Helm release '%s', chart '%s', cluster: '%s' %s", releaseName, chartName, cluster.Name, changes)
 
-	return err
+	return nil
+}
+
+// exclusiveKey returns key to make sure changes of the same Helm release don't overlap, even if some of them got
+// abandoned due to timeout
+func exclusiveKey(cluster *lang.Cluster, releaseName string) string {
+	return "helm/" + cluster.Name + "/" + releaseName
 }
 
 // Destroy implements destruction of an existing component instance in the cloud by running "helm delete" on the corresponding helm chart
-func (plugin *Plugin) Destroy(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+func (plugin *Plugin) Destroy(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return util.RunExclusiveWithContext(ctx, exclusiveKey(cluster, getHelmReleaseName(deployName)), func() error {
+		return plugin.destroy(cluster, deployName, eventLog)
+	})
+}
+
+func (plugin *Plugin) destroy(cluster *lang.Cluster, deployName string, eventLog *event.Log) error {
+	return plugin.destroyRelease(cluster, getHelmReleaseName(deployName), eventLog)
+}
+
+func (plugin *Plugin) destroyRelease(cluster *lang.Cluster, releaseName string, eventLog *event.Log) error {
 	cache, err := plugin.getClusterCache(cluster, eventLog)
 	if err != nil {
 		return err
 	}
 
-	releaseName := getHelmReleaseName(deployName)
-
 	helmClient, err := cache.newHelmClient(eventLog)
 	if err != nil {
 		return err
@@ -138,8 +146,90 @@ func (plugin *Plugin) Destroy(cluster *lang.Cluster, deployName string, params u
 		"release": releaseName,
 	}).Infof("Deleting Helm release '%s'", releaseName)
 
+	// release could be missing, e.g. if it failed to be created, so there is nothing to delete
 	_, err = helmClient.DeleteRelease(releaseName, helm.DeletePurge(true))
-	return err
+	if err != nil && !isReleaseNotFound(err, releaseName) {
+		return err
+	}
+
+	return nil
+}
+
+// LiveState returns the state of the Helm release corresponding to the component instance, as it's deployed in the
+// cluster right now
+func (plugin *Plugin) LiveState(ctx context.Context, cluster *lang.Cluster, deployName string, eventLog *event.Log) (*pluginapi.LiveState, error) {
+	var state *pluginapi.LiveState
+	err := util.RunWithContext(ctx, func() error {
+		var errState error
+		state, errState = plugin.liveState(cluster, deployName, eventLog)
+		return errState
+	})
+	return state, err
+}
+
+func (plugin *Plugin) liveState(cluster *lang.Cluster, deployName string, eventLog *event.Log) (*pluginapi.LiveState, error) {
+	cache, err := plugin.getClusterCache(cluster, eventLog)
+	if err != nil {
+		return nil, err
+	}
+
+	helmClient, err := cache.newHelmClient(eventLog)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseName := getHelmReleaseName(deployName)
+	currRelease, err := helmClient.ReleaseContent(releaseName)
+	if err != nil && !isReleaseNotFound(err, releaseName) {
+		return nil, fmt.Errorf("error while looking for Helm release %s: %s", releaseName, err)
+	}
+	if currRelease == nil {
+		return &pluginapi.LiveState{Exists: false}, nil
+	}
+
+	return releaseLiveState(currRelease.Release)
+}
+
+// releaseLiveState returns the live state of the given Helm release. Release is considered to exist only if it's
+// deployed, while failed, deleted (but not purged) or still being installed ones are treated as missing
+func releaseLiveState(rel *release.Release) (*pluginapi.LiveState, error) {
+	if rel == nil || rel.Info == nil || rel.Info.Status == nil || rel.Info.Status.Code != release.Status_DEPLOYED {
+		return &pluginapi.LiveState{Exists: false}, nil
+	}
+
+	state := &pluginapi.LiveState{Exists: true}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		state.Version = rel.Chart.Metadata.Version
+	}
+	if rel.Info.FirstDeployed != nil {
+		state.CreatedAt = time.Unix(rel.Info.FirstDeployed.Seconds, int64(rel.Info.FirstDeployed.Nanos))
+	}
+	if rel.Info.LastDeployed != nil {
+		state.UpdatedAt = time.Unix(rel.Info.LastDeployed.Seconds, int64(rel.Info.LastDeployed.Nanos))
+	}
+
+	// release values are the params release got installed or updated with
+	values, err := getReleaseValues(rel)
+	if err != nil {
+		return nil, err
+	}
+	state.ParamsHash = util.HashObject(values)
+
+	return state, nil
+}
+
+// ExpectedLiveState returns the state Helm release is expected to have once it's deployed with the given params
+func (plugin *Plugin) ExpectedLiveState(params util.NestedParameterMap) (*pluginapi.LiveState, error) {
+	_, _, chartVersion, err := getHelmReleaseInfo(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pluginapi.LiveState{
+		Exists:     true,
+		Version:    chartVersion,
+		ParamsHash: util.HashObject(params),
+	}, nil
 }
 
 // Cleanup implements cleanup phase for the Helm plugin. It closes all created and cached Tiller tunnels.
@@ -157,8 +247,18 @@ func (plugin *Plugin) Cleanup() error {
 }
 
 // Endpoints returns map from port type to url for all services of the current chart
+func (plugin *Plugin) Endpoints(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error) {
+	var endpoints map[string]string
+	err := util.RunWithContext(ctx, func() error {
+		var errEndpoints error
+		endpoints, errEndpoints = plugin.endpoints(cluster, deployName, eventLog)
+		return errEndpoints
+	})
+	return endpoints, err
+}
+
 // TODO: reduce cyclomatic complexity
-func (plugin *Plugin) Endpoints(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error) { // nolint: gocyclo
+func (plugin *Plugin) endpoints(cluster *lang.Cluster, deployName string, eventLog *event.Log) (map[string]string, error) { // nolint: gocyclo
 	cache, err := plugin.getClusterCache(cluster, eventLog)
 	if err != nil {
 		return nil, err
@@ -251,3 +351,74 @@ func (plugin *Plugin) Endpoints(cluster *lang.Cluster, deployName string, params
 
 	return endpoints, nil
 }
+
+// Status returns readiness of the component instance. It's ready when all deployments of the corresponding Helm
+// release have all their replicas updated and available, and all pods of the release are ready
+func (plugin *Plugin) Status(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (bool, error) {
+	var ready bool
+	err := util.RunWithContext(ctx, func() error {
+		var errStatus error
+		ready, errStatus = plugin.status(cluster, deployName, eventLog)
+		return errStatus
+	})
+	return ready, err
+}
+
+func (plugin *Plugin) status(cluster *lang.Cluster, deployName string, eventLog *event.Log) (bool, error) {
+	cache, err := plugin.getClusterCache(cluster, eventLog)
+	if err != nil {
+		return false, err
+	}
+
+	kubeClient, err := cache.newKubeClient()
+	if err != nil {
+		return false, err
+	}
+
+	releaseName := getHelmReleaseName(deployName)
+
+	selector := labels.Set{"release": releaseName}.AsSelector().String()
+	options := meta.ListOptions{LabelSelector: selector}
+
+	// Check all corresponding deployments
+	deployments, err := kubeClient.ExtensionsV1beta1().Deployments(cache.namespace).List(options)
+	if err != nil {
+		return false, err
+	}
+
+	for _, deployment := range deployments.Items {
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		if deployment.Status.ObservedGeneration < deployment.Generation || deployment.Status.UpdatedReplicas < replicas || deployment.Status.AvailableReplicas < replicas {
+			eventLog.WithFields(event.Fields{
+				"release":    releaseName,
+				"deployment": deployment.Name,
+			}).Debugf("Deployment '%s' of Helm release '%s' is not ready: %d/%d replicas available", deployment.Name, releaseName, deployment.Status.AvailableReplicas, replicas)
+			return false, nil
+		}
+	}
+
+	// Check all corresponding pods
+	pods, err := kubeClient.CoreV1().Pods(cache.namespace).List(options)
+	if err != nil {
+		return false, err
+	}
+
+	for _, pod := range pods.Items {
+		// pods of completed jobs are never ready
+		if pod.Status.Phase == api.PodSucceeded {
+			continue
+		}
+		if !isPodReady(pod.Status.Conditions) {
+			eventLog.WithFields(event.Fields{
+				"release": releaseName,
+				"pod":     pod.Name,
+			}).Debugf("Pod '%s' of Helm release '%s' is not ready", pod.Name, releaseName)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
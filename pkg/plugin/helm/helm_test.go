@@ -0,0 +1,61 @@
+package helm
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"testing"
+)
+
+func makeRelease(code release.Status_Code) *release.Release {
+	return &release.Release{
+		Name:   "release",
+		Info:   &release.Info{Status: &release.Status{Code: code}},
+		Chart:  &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0"}},
+		Config: &chart.Config{Raw: "replicas: 2\n"},
+	}
+}
+
+func TestReleaseLiveState(t *testing.T) {
+	// deployed release exists and reports its chart version and values
+	state, err := releaseLiveState(makeRelease(release.Status_DEPLOYED))
+	assert.NoError(t, err, "Live state of deployed release should be retrieved")
+	assert.True(t, state.Exists, "Deployed release should exist")
+	assert.Equal(t, "1.0.0", state.Version, "Live state should have chart version")
+	assert.Equal(t, util.HashObject(util.NestedParameterMap{"replicas": 2}), state.ParamsHash, "Live state should have hash of release values")
+
+	// releases which aren't deployed are treated as missing
+	for _, code := range []release.Status_Code{
+		release.Status_UNKNOWN,
+		release.Status_DELETED,
+		release.Status_SUPERSEDED,
+		release.Status_FAILED,
+		release.Status_DELETING,
+		release.Status_PENDING_INSTALL,
+		release.Status_PENDING_UPGRADE,
+		release.Status_PENDING_ROLLBACK,
+	} {
+		state, err = releaseLiveState(makeRelease(code))
+		assert.NoError(t, err, "Live state of %s release should be retrieved", code)
+		assert.False(t, state.Exists, "Release with status %s should not exist", code)
+	}
+
+	// release without status is treated as missing as well
+	state, err = releaseLiveState(&release.Release{Name: "release"})
+	assert.NoError(t, err, "Live state of release without status should be retrieved")
+	assert.False(t, state.Exists, "Release without status should not exist")
+}
+
+func TestIsReleaseNotFound(t *testing.T) {
+	assert.False(t, isReleaseNotFound(nil, "release"), "No error should not mean missing release")
+
+	// Tiller error for the missing release, as it comes back over gRPC
+	assert.True(t, isReleaseNotFound(fmt.Errorf(`rpc error: code = Unknown desc = release: "release" not found`), "release"), "Tiller error for missing release should be detected")
+
+	// other errors, even mentioning "not found", don't mean that release is missing
+	assert.False(t, isReleaseNotFound(fmt.Errorf(`rpc error: code = Unknown desc = release: "another" not found`), "release"), "Error for another release should not be treated as missing release")
+	assert.False(t, isReleaseNotFound(fmt.Errorf(`rpc error: code = Unknown desc = configmaps "tiller" not found`), "release"), "Missing Tiller resource should not be treated as missing release")
+	assert.False(t, isReleaseNotFound(fmt.Errorf("could not find tiller"), "release"), "Other errors should not be treated as missing release")
+}
@@ -5,11 +5,13 @@ import (
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/util"
 	"github.com/Aptomi/aptomi/pkg/util/retry"
+	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"k8s.io/helm/cmd/helm/installer"
 	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/helm/portforwarder"
 	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/release"
 	"k8s.io/helm/pkg/repo"
 	"strings"
 )
@@ -36,6 +38,59 @@ func getHelmReleaseName(deployName string) string {
 	return strings.ToLower(util.EscapeName(deployName))
 }
 
+// releaseMarker is the key of the value, which gets added to the values of every Helm release deployed by Aptomi. It
+// holds the deploy name of the corresponding component instance, so Helm releases managed by Aptomi could be told apart
+// from the other ones (Helm releases don't carry any labels)
+const releaseMarker = "aptomiDeployName"
+
+// getHelmReleaseValues returns values Helm release should be installed or updated with, i.e. the given params along
+// with the release marker
+func getHelmReleaseValues(deployName string, params util.NestedParameterMap) ([]byte, error) {
+	values := util.NestedParameterMap{}
+	for key, value := range params {
+		values[key] = value
+	}
+	values[releaseMarker] = deployName
+
+	return yaml.Marshal(values)
+}
+
+// getReleaseValues returns values the given Helm release got installed or updated with, without the release marker
+func getReleaseValues(rel *release.Release) (util.NestedParameterMap, error) {
+	values := util.NestedParameterMap{}
+	if rel.Config != nil {
+		err := yaml.Unmarshal([]byte(rel.Config.Raw), &values)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading values of Helm release %s: %s", rel.Name, err)
+		}
+	}
+	delete(values, releaseMarker)
+
+	return values, nil
+}
+
+// isManagedRelease returns true if the given Helm release has been deployed by Aptomi, i.e. it has the release marker
+// matching its name
+func isManagedRelease(rel *release.Release) bool {
+	if rel.Config == nil {
+		return false
+	}
+	values := util.NestedParameterMap{}
+	err := yaml.Unmarshal([]byte(rel.Config.Raw), &values)
+	if err != nil {
+		return false
+	}
+	deployName, ok := values[releaseMarker].(string)
+	return ok && len(deployName) > 0 && getHelmReleaseName(deployName) == rel.Name
+}
+
+// isReleaseNotFound returns true if the given error has been returned by Tiller because the Helm release with the given
+// name doesn't exist. Tiller errors come wrapped into gRPC errors, so the message of Tiller storage driver error is
+// looked for, instead of matching any error which happens to contain "not found"
+func isReleaseNotFound(err error, releaseName string) bool {
+	return err != nil && strings.Contains(err.Error(), fmt.Sprintf("release: %q not found", releaseName))
+}
+
 func (cache *clusterCache) newHelmClient(eventLog *event.Log) (*helm.Client, error) {
 	return helm.NewClient(helm.Host(cache.tillerHost)), nil
 }
@@ -1,18 +1,26 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/engine/progress"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/external"
 	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/util"
 )
 
 // Process is a action which gets called only once. It manages all Istio rules across all clusters, making sure they
 // are up to date by creating/deleting/updating rules if/as needed
+func (plugin *Plugin) Process(ctx context.Context, policy *lang.Policy, resolution *resolve.PolicyResolution, externalData *external.Data, eventLog *event.Log) error {
+	return util.RunWithContext(ctx, func() error {
+		return plugin.process(policy, resolution, externalData, eventLog)
+	})
+}
+
 // TODO: reduce cyclomatic complexity
-func (plugin *Plugin) Process(policy *lang.Policy, resolution *resolve.PolicyResolution, externalData *external.Data, eventLog *event.Log) error { // nolint: gocyclo
+func (plugin *Plugin) process(policy *lang.Policy, resolution *resolve.PolicyResolution, externalData *external.Data, eventLog *event.Log) error { // nolint: gocyclo
 	// Do not run Istio
 	if true {
 		return nil
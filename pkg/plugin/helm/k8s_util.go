@@ -167,3 +167,12 @@ func (cache *clusterCache) createKubeClusterRoleBinding(client kubernetes.Interf
 
 	return err
 }
+
+func isPodReady(conditions []api.PodCondition) bool {
+	for _, condition := range conditions {
+		if condition.Type == api.PodReady {
+			return condition.Status == api.ConditionTrue
+		}
+	}
+	return false
+}
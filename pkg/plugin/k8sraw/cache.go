@@ -0,0 +1,208 @@
+package k8sraw
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	api "k8s.io/client-go/pkg/api/v1"
+	"k8s.io/client-go/rest"
+	"strings"
+	"sync"
+)
+
+type clusterCache struct {
+	cluster         *lang.Cluster
+	config          *Config
+	lock            sync.Mutex // all caching ops should use this lock
+	inited          bool       // true if cache has been initialized successfully
+	kubeConfig      *rest.Config
+	namespace       string
+	externalAddress string // kube external address
+	discovery       discovery.CachedDiscoveryInterface
+	mapper          apimeta.RESTMapper
+	clientPool      dynamic.ClientPool
+}
+
+func (plugin *Plugin) getClusterCache(cluster *lang.Cluster) (*clusterCache, error) {
+	rawCache, _ := plugin.cache.LoadOrStore(cluster.Name, new(clusterCache))
+	cache := rawCache.(*clusterCache)
+
+	// always call init, so concurrent callers block until the first one finishes initialization
+	err := cache.init(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+func (cache *clusterCache) init(cluster *lang.Cluster) error {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if cache.inited {
+		return nil
+	}
+
+	err := cache.initConfig(cluster)
+	if err != nil {
+		return err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cache.kubeConfig)
+	if err != nil {
+		return fmt.Errorf("could not get kubernetes discovery client for cluster %s: %s", cluster.Name, err)
+	}
+
+	cache.discovery = discovery.NewMemCacheClient(discoveryClient)
+	cache.mapper = discovery.NewDeferredDiscoveryRESTMapper(cache.discovery, apimeta.InterfacesForUnstructured)
+	cache.clientPool = dynamic.NewClientPool(cache.kubeConfig, cache.mapper, dynamic.LegacyAPIPathResolverFunc)
+	cache.inited = true
+
+	return nil
+}
+
+func (cache *clusterCache) newKubeClient() (kubernetes.Interface, error) {
+	client, err := kubernetes.NewForConfig(cache.kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not get kubernetes client: %s", err)
+	}
+
+	return client, nil
+}
+
+func (cache *clusterCache) getKubeExternalAddress(client kubernetes.Interface) (string, error) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if len(cache.externalAddress) > 0 {
+		return cache.externalAddress, nil
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(meta.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, addrType := range []api.NodeAddressType{api.NodeExternalIP, api.NodeInternalIP, api.NodeHostName} {
+		for _, node := range nodes.Items {
+			for _, addr := range node.Status.Addresses {
+				if addr.Type == addrType {
+					cache.externalAddress = addr.Address
+					return cache.externalAddress, nil
+				}
+			}
+		}
+	}
+
+	return "", fmt.Errorf("couldn't find external IP for cluster: %s", cache.cluster.Name)
+}
+
+func (cache *clusterCache) newResourceClient(obj *unstructured.Unstructured) (*dynamic.ResourceClient, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := cache.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("can't find resource for %s in cluster %s: %s", gvk, cache.cluster.Name, err)
+	}
+
+	client, err := cache.clientPool.ClientForGroupVersionKind(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("could not get kubernetes client for %s: %s", gvk, err)
+	}
+
+	namespace := ""
+	if mapping.Scope.Name() == apimeta.RESTScopeNameNamespace {
+		namespace = obj.GetNamespace()
+		if len(namespace) == 0 {
+			namespace = cache.namespace
+			obj.SetNamespace(namespace)
+		}
+	}
+
+	resource := &meta.APIResource{
+		Name:       mapping.Resource,
+		Namespaced: len(namespace) > 0,
+		Kind:       gvk.Kind,
+	}
+
+	return client.Resource(resource, namespace), nil
+}
+
+// labeledObject is an object found in the cluster along with the client to manage it
+type labeledObject struct {
+	obj    *unstructured.Unstructured
+	client *dynamic.ResourceClient
+}
+
+// findLabeledObjects returns all objects in the cluster of all kinds, which are labeled with the given deploy name.
+// Objects served by several API groups (e.g. deployments in "apps" and "extensions") are returned only once
+func (cache *clusterCache) findLabeledObjects(deployName string) ([]*labeledObject, error) {
+	resourceLists, err := cache.discovery.ServerPreferredResources()
+	if err != nil {
+		return nil, fmt.Errorf("can't get resources supported by cluster %s: %s", cache.cluster.Name, err)
+	}
+	resourceLists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}, resourceLists)
+
+	selector := labels.Set{deployNameLabel: getDeployNameLabel(deployName)}.AsSelector().String()
+	seen := make(map[string]bool)
+	result := []*labeledObject{}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse group version %s: %s", resourceList.GroupVersion, err)
+		}
+
+		for idx := range resourceList.APIResources {
+			resource := resourceList.APIResources[idx]
+			if strings.Contains(resource.Name, "/") {
+				// skip subresources
+				continue
+			}
+
+			client, err := cache.clientPool.ClientForGroupVersionKind(gv.WithKind(resource.Kind))
+			if err != nil {
+				return nil, fmt.Errorf("could not get kubernetes client for %s: %s", gv.WithKind(resource.Kind), err)
+			}
+
+			listObj, err := client.Resource(&resource, meta.NamespaceAll).List(meta.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return nil, fmt.Errorf("error while listing %s: %s", resource.Name, err)
+			}
+			items, err := apimeta.ExtractList(listObj)
+			if err != nil {
+				return nil, fmt.Errorf("error while listing %s: %s", resource.Name, err)
+			}
+
+			for _, item := range items {
+				obj, ok := item.(*unstructured.Unstructured)
+				if !ok {
+					return nil, fmt.Errorf("unexpected type while listing %s: %T", resource.Name, item)
+				}
+				key := getObjectKey(obj)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				result = append(result, &labeledObject{
+					obj:    obj,
+					client: client.Resource(&resource, obj.GetNamespace()),
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// getObjectKey returns key identifying the object in the cluster regardless of the API group version it's served by
+func getObjectKey(obj *unstructured.Unstructured) string {
+	return strings.Join([]string{obj.GetKind(), obj.GetNamespace(), obj.GetName()}, "/")
+}
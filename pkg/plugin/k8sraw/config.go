@@ -0,0 +1,89 @@
+package k8sraw
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Config represents K8s Raw plugin configuration
+type Config struct {
+	Namespace  string      `yaml:",omitempty"`
+	Local      bool        `yaml:",omitempty"`
+	Context    string      `yaml:",omitempty"`
+	KubeConfig interface{} `yaml:",omitempty"` // it's just a kubeconfig, we don't need to parse it
+}
+
+func (cache *clusterCache) initConfig(cluster *lang.Cluster) error {
+	cache.cluster = cluster
+
+	config := &Config{}
+	cache.config = config
+
+	err := cluster.ParseConfigInto(config)
+	if err != nil {
+		return fmt.Errorf("error while parsing K8s Raw plugin specific cluster config: %s", err)
+	}
+
+	if config.Local && config.KubeConfig != nil {
+		return fmt.Errorf("kube-config can't be specified when using local type in cluster: %s", cluster.Name)
+	}
+
+	if config.KubeConfig != nil {
+		cache.kubeConfig, cache.namespace, err = initKubeConfig(config, cluster)
+	} else {
+		cache.kubeConfig, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(config.Namespace) > 0 {
+		cache.namespace = config.Namespace
+	}
+	if len(cache.namespace) == 0 {
+		cache.namespace = "default"
+	}
+
+	return nil
+}
+
+func initKubeConfig(config *Config, cluster *lang.Cluster) (*rest.Config, string, error) {
+	var data []byte
+	if strData, ok := config.KubeConfig.(string); ok {
+		data = []byte(strData)
+	} else {
+		yamlData, err := yaml.Marshal(config.KubeConfig)
+		if err != nil {
+			return nil, "", fmt.Errorf("error while marshaling kube config into bytes: %s", err)
+		}
+		data = yamlData
+	}
+
+	kubeConfig, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("error while loading kube config for cluster %s: %s", cluster.Name, err)
+	}
+
+	overrides := &clientcmd.ConfigOverrides{}
+	if len(config.Context) > 0 {
+		overrides.CurrentContext = config.Context
+	} else if len(kubeConfig.CurrentContext) == 0 {
+		return nil, "", fmt.Errorf("context for cluster %s should be explicitly defined (context in cluster config or current-context in kubeconfig)", cluster.Name)
+	}
+
+	conf := clientcmd.NewNonInteractiveClientConfig(*kubeConfig, overrides.CurrentContext, overrides, nil)
+
+	clientConf, err := conf.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("could not get kubernetes config for cluster %s: %s", cluster.Name, err)
+	}
+
+	if namespace, _, nsErr := conf.Namespace(); nsErr == nil && len(namespace) > 0 {
+		return clientConf, namespace, nil
+	}
+
+	return clientConf, "", nil
+}
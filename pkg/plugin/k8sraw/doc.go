@@ -0,0 +1,7 @@
+// Package k8sraw implements support for Kubernetes Raw plugin, which can deploy plain Kubernetes manifests onto k8s
+// clusters via Kubernetes API without Helm and Tiller.
+//
+// Manifests should be provided as a multi-document YAML string in the "manifest" code parameter. All created objects
+// get labeled with the component deploy name, so they could be found later for pruning, deletion and endpoints
+// discovery.
+package k8sraw
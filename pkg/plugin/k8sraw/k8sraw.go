@@ -0,0 +1,233 @@
+package k8sraw
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/util"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var k8sRawCodeTypes = []string{"kubernetes-raw", "aptomi/code/kubernetes-raw"}
+
+// GetSupportedCodeTypes returns all code types for which this plugin is registered to
+func (plugin *Plugin) GetSupportedCodeTypes() []string {
+	return k8sRawCodeTypes
+}
+
+// Create implements creation of a new component instance in the cloud by creating all objects from the manifest
+func (plugin *Plugin) Create(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return util.RunExclusiveWithContext(ctx, exclusiveKey(cluster, deployName), func() error {
+		_, err := plugin.createOrUpdate(ctx, cluster, deployName, params, eventLog)
+		return err
+	})
+}
+
+// Update implements update of an existing component instance in the cloud by updating all objects from the manifest
+// (objects which don't exist yet will be created). Objects removed from the manifest get deleted
+func (plugin *Plugin) Update(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return util.RunExclusiveWithContext(ctx, exclusiveKey(cluster, deployName), func() error {
+		objects, err := plugin.createOrUpdate(ctx, cluster, deployName, params, eventLog)
+		if err != nil {
+			return err
+		}
+
+		keep := make(map[string]bool)
+		for _, obj := range objects {
+			keep[getObjectKey(obj)] = true
+		}
+		return plugin.deleteLabeled(ctx, cluster, deployName, keep, eventLog)
+	})
+}
+
+// createOrUpdate applies all objects from the manifest and returns them
+func (plugin *Plugin) createOrUpdate(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) ([]*unstructured.Unstructured, error) {
+	cache, err := plugin.getClusterCache(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := parseManifest(deployName, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objects {
+		// stop applying objects once context is done
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		client, err := cache.newResourceClient(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = client.Get(obj.GetName(), meta.GetOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, fmt.Errorf("error while looking for %s '%s': %s", obj.GetKind(), obj.GetName(), err)
+		}
+
+		if err != nil {
+			eventLog.WithFields(event.Fields{
+				"kind":      obj.GetKind(),
+				"name":      obj.GetName(),
+				"namespace": obj.GetNamespace(),
+			}).Infof("Creating %s '%s' for '%s', cluster: '%s'", obj.GetKind(), obj.GetName(), deployName, cluster.Name)
+
+			_, err = client.Create(obj)
+		} else {
+			eventLog.WithFields(event.Fields{
+				"kind":      obj.GetKind(),
+				"name":      obj.GetName(),
+				"namespace": obj.GetNamespace(),
+			}).Infof("Updating %s '%s' for '%s', cluster: '%s'", obj.GetKind(), obj.GetName(), deployName, cluster.Name)
+
+			// existing object gets patched, so fields set by the cluster (e.g. immutable spec.clusterIP of services)
+			// are preserved
+			var data []byte
+			data, err = obj.MarshalJSON()
+			if err == nil {
+				_, err = client.Patch(obj.GetName(), types.MergePatchType, data)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error while applying %s '%s': %s", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return objects, nil
+}
+
+// exclusiveKey returns key to make sure changes of the same component instance don't overlap, even if some of them
+// got abandoned due to timeout
+func exclusiveKey(cluster *lang.Cluster, deployName string) string {
+	return "k8sraw/" + cluster.Name + "/" + deployName
+}
+
+// Destroy implements destruction of an existing component instance in the cloud by deleting all objects labeled with
+// the component deploy name, including the ones which aren't in the manifest anymore
+func (plugin *Plugin) Destroy(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return util.RunExclusiveWithContext(ctx, exclusiveKey(cluster, deployName), func() error {
+		return plugin.deleteLabeled(ctx, cluster, deployName, nil, eventLog)
+	})
+}
+
+// deleteLabeled deletes all objects labeled with the given deploy name, except the ones with keys from keep
+func (plugin *Plugin) deleteLabeled(ctx context.Context, cluster *lang.Cluster, deployName string, keep map[string]bool, eventLog *event.Log) error {
+	cache, err := plugin.getClusterCache(cluster)
+	if err != nil {
+		return err
+	}
+
+	labeled, err := cache.findLabeledObjects(deployName)
+	if err != nil {
+		return err
+	}
+
+	propagation := meta.DeletePropagationBackground
+	for _, item := range labeled {
+		// stop deleting objects once context is done
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		obj := item.obj
+		if keep[getObjectKey(obj)] {
+			continue
+		}
+
+		eventLog.WithFields(event.Fields{
+			"kind":      obj.GetKind(),
+			"name":      obj.GetName(),
+			"namespace": obj.GetNamespace(),
+		}).Infof("Deleting %s '%s' for '%s', cluster: '%s'", obj.GetKind(), obj.GetName(), deployName, cluster.Name)
+
+		err = item.client.Delete(obj.GetName(), &meta.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("error while deleting %s '%s': %s", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// Cleanup implements cleanup phase for the K8s Raw plugin. There is nothing to cleanup as connections aren't kept
+// open between calls.
+func (plugin *Plugin) Cleanup() error {
+	return nil
+}
+
+// Endpoints returns map from port type to url for all services labeled with the component deploy name
+func (plugin *Plugin) Endpoints(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error) {
+	var endpoints map[string]string
+	err := util.RunWithContext(ctx, func() error {
+		var errEndpoints error
+		endpoints, errEndpoints = plugin.endpoints(cluster, deployName)
+		return errEndpoints
+	})
+	return endpoints, err
+}
+
+func (plugin *Plugin) endpoints(cluster *lang.Cluster, deployName string) (map[string]string, error) {
+	cache, err := plugin.getClusterCache(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeClient, err := cache.newKubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.Set{deployNameLabel: getDeployNameLabel(deployName)}.AsSelector().String()
+	services, err := kubeClient.CoreV1().Services(meta.NamespaceAll).List(meta.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make(map[string]string)
+	for _, service := range services.Items {
+		var host string
+		switch service.Spec.Type {
+		case "NodePort":
+			host, err = cache.getKubeExternalAddress(kubeClient)
+			if err != nil {
+				return nil, err
+			}
+		case "LoadBalancer":
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				host = ingress.IP
+				if len(host) == 0 {
+					host = ingress.Hostname
+				}
+			}
+		}
+		if len(host) == 0 {
+			continue
+		}
+
+		for _, port := range service.Spec.Ports {
+			portNumber := port.Port
+			if service.Spec.Type == "NodePort" {
+				portNumber = port.NodePort
+			}
+			sURL := fmt.Sprintf("%s:%d", host, portNumber)
+
+			if util.StringContainsAny(port.Name, "https") {
+				sURL = "https://" + sURL
+			} else if util.StringContainsAny(port.Name, "ui", "rest", "http") {
+				sURL = "http://" + sURL
+			}
+
+			endpoints[port.Name] = sURL
+		}
+	}
+
+	return endpoints, nil
+}
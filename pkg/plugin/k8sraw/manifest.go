@@ -0,0 +1,79 @@
+package k8sraw
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"io"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"strings"
+)
+
+const (
+	// deployNameLabel is the label set on all objects created by the plugin to find them by the component deploy name
+	deployNameLabel = "aptomi.io/deploy-name"
+
+	maxLabelValueLength = 63
+)
+
+// parseManifest parses multi-document YAML manifest from the code params into the list of k8s objects, marking each
+// of them with the label corresponding to the provided deploy name
+func parseManifest(deployName string, params util.NestedParameterMap) ([]*unstructured.Unstructured, error) {
+	manifest, err := params.GetString("manifest", "")
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest) == 0 {
+		return nil, fmt.Errorf("manifest is a mandatory parameter")
+	}
+
+	result := []*unstructured.Unstructured{}
+	decoder := k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	for {
+		obj := make(map[string]interface{})
+		err = decoder.Decode(&obj)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing manifest: %s", err)
+		}
+
+		// skip empty documents
+		if len(obj) == 0 {
+			continue
+		}
+
+		item := &unstructured.Unstructured{Object: obj}
+		if len(item.GetKind()) == 0 || len(item.GetAPIVersion()) == 0 || len(item.GetName()) == 0 {
+			return nil, fmt.Errorf("object #%d in manifest should have apiVersion, kind and metadata.name defined", len(result)+1)
+		}
+
+		labels := item.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[deployNameLabel] = getDeployNameLabel(deployName)
+		item.SetLabels(labels)
+
+		result = append(result, item)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("manifest doesn't contain any objects")
+	}
+
+	return result, nil
+}
+
+// getDeployNameLabel returns value for the label used to mark all objects that belong to the given component instance.
+// Label values are limited to 63 characters, so long deploy names are truncated and suffixed with a hash.
+func getDeployNameLabel(deployName string) string {
+	name := strings.ToLower(util.EscapeName(deployName))
+	if len(name) <= maxLabelValueLength {
+		return name
+	}
+
+	suffix := fmt.Sprintf("-%x", util.HashFnv(deployName))
+	return strings.TrimRight(name[:maxLabelValueLength-len(suffix)], "-.") + suffix
+}
@@ -0,0 +1,64 @@
+package k8sraw
+
+import (
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	params := util.NestedParameterMap{
+		"manifest": `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  type: NodePort
+  ports:
+  - name: http
+    port: 80
+---
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+  namespace: demo
+---
+`,
+	}
+
+	objects, err := parseManifest("cluster-main-web", params)
+	assert.NoError(t, err, "Manifest should be parsed without errors")
+	if assert.Len(t, objects, 2, "Manifest should contain 2 objects") {
+		assert.Equal(t, "Service", objects[0].GetKind(), "Object kind should be parsed")
+		assert.Equal(t, "web", objects[0].GetLabels()["app"], "Existing labels should be preserved")
+		assert.Equal(t, "cluster-main-web", objects[0].GetLabels()[deployNameLabel], "Deploy name label should be added")
+		assert.Equal(t, "demo", objects[1].GetNamespace(), "Object namespace should be parsed")
+		assert.Equal(t, "cluster-main-web", objects[1].GetLabels()[deployNameLabel], "Deploy name label should be added")
+	}
+}
+
+func TestParseManifestInvalid(t *testing.T) {
+	for _, manifest := range []interface{}{
+		"",
+		"---\n",
+		"kind: Service\nmetadata:\n  name: web",
+		"apiVersion: v1\nkind: Service",
+		42,
+	} {
+		_, err := parseManifest("web", util.NestedParameterMap{"manifest": manifest})
+		assert.Error(t, err, "Invalid manifest should not be parsed: %v", manifest)
+	}
+}
+
+func TestDeployNameLabel(t *testing.T) {
+	assert.Equal(t, "main-web-component", getDeployNameLabel("main#web_component"), "Deploy name should be escaped")
+
+	long := getDeployNameLabel(strings.Repeat("verylongname#", 10))
+	assert.True(t, len(long) <= maxLabelValueLength, "Deploy name label should be truncated")
+	assert.NotEqual(t, long, getDeployNameLabel(strings.Repeat("verylongname#", 11)), "Truncated labels should be different for different deploy names")
+}
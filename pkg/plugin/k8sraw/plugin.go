@@ -0,0 +1,17 @@
+package k8sraw
+
+import (
+	"sync"
+)
+
+// Plugin uses Kubernetes API for deployment of raw manifests on kubernetes
+type Plugin struct {
+	cache *sync.Map
+}
+
+// NewPlugin creates a new kubernetes raw plugin
+func NewPlugin() *Plugin {
+	return &Plugin{
+		cache: new(sync.Map),
+	}
+}
@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
@@ -23,12 +24,18 @@ func (reg *MockRegistry) GetDeployPlugin(codeType string) (DeployPlugin, error)
 	return reg.DeployPlugin, nil
 }
 
+// GetDeployPlugins always returns the same deployment plugin
+func (reg *MockRegistry) GetDeployPlugins() []DeployPlugin {
+	return []DeployPlugin{reg.DeployPlugin}
+}
+
 // GetPostProcessingPlugins always returns the same post-processing plugin
 func (reg *MockRegistry) GetPostProcessingPlugins() []PostProcessPlugin {
 	return []PostProcessPlugin{reg.PostProcessPlugin}
 }
 
-// MockDeployPlugin is a mock plugin which does nothing, except sleeping a given time amount on every action
+// MockDeployPlugin is a mock plugin which does nothing, except sleeping a given time amount on every action (or until
+// context is done)
 type MockDeployPlugin struct {
 	SleepTime time.Duration
 }
@@ -44,29 +51,87 @@ func (p *MockDeployPlugin) GetSupportedCodeTypes() []string {
 }
 
 // Create does nothing but sleeps
-func (p *MockDeployPlugin) Create(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
-	time.Sleep(p.SleepTime)
-	return nil
+func (p *MockDeployPlugin) Create(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return p.sleep(ctx)
 }
 
 // Update does nothing but sleeps
-func (p *MockDeployPlugin) Update(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
-	time.Sleep(p.SleepTime)
-	return nil
+func (p *MockDeployPlugin) Update(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return p.sleep(ctx)
 }
 
 // Destroy does nothing but sleeps
-func (p *MockDeployPlugin) Destroy(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
-	time.Sleep(p.SleepTime)
-	return nil
+func (p *MockDeployPlugin) Destroy(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+	return p.sleep(ctx)
 }
 
 // Endpoints sleeps and then always returns an empty set of endpoints
-func (p *MockDeployPlugin) Endpoints(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error) {
-	time.Sleep(p.SleepTime)
+func (p *MockDeployPlugin) Endpoints(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error) {
+	err := p.sleep(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return make(map[string]string), nil
 }
 
+func (p *MockDeployPlugin) sleep(ctx context.Context) error {
+	select {
+	case <-time.After(p.SleepTime):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status always reports that component instance is ready
+func (p *MockDeployPlugin) Status(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (bool, error) {
+	return true, nil
+}
+
+// MockLiveStatePlugin is a mock plugin which does nothing, except reporting live state of component instances (by
+// deploy name). It expects code params to have "version" parameter
+type MockLiveStatePlugin struct {
+	MockDeployPlugin
+
+	// Live is live state of component instances by deploy name. Component instances, which aren't listed, don't exist
+	Live map[string]*LiveState
+
+	// Fail is a substring to search in deploy names. When found, reading live state fails
+	Fail string
+}
+
+// NewMockLiveStatePlugin creates a new MockLiveStatePlugin without any component instances running in the cloud
+func NewMockLiveStatePlugin() *MockLiveStatePlugin {
+	return &MockLiveStatePlugin{Live: make(map[string]*LiveState)}
+}
+
+// LiveState returns live state of component instance from Live, unless its deploy name contains Fail
+func (p *MockLiveStatePlugin) LiveState(ctx context.Context, cluster *lang.Cluster, deployName string, eventLog *event.Log) (*LiveState, error) {
+	if len(p.Fail) > 0 && strings.Contains(deployName, p.Fail) {
+		return nil, fmt.Errorf("live state failed by plugin mock")
+	}
+	state, ok := p.Live[deployName]
+	if !ok {
+		return &LiveState{Exists: false}, nil
+	}
+	return state, nil
+}
+
+// ExpectedLiveState returns live state of component instance with the given code params, with version taken from
+// "version" parameter
+func (p *MockLiveStatePlugin) ExpectedLiveState(params util.NestedParameterMap) (*LiveState, error) {
+	return &LiveState{
+		Exists:     true,
+		Version:    params["version"].(string),
+		ParamsHash: util.HashObject(params),
+	}, nil
+}
+
+// Endpoints always returns a single endpoint with deploy name as its URL
+func (p *MockLiveStatePlugin) Endpoints(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error) {
+	return map[string]string{"url": deployName}, nil
+}
+
 // MockDeployPluginFailComponents is a mock plugin which does nothing, except fails component actions if their name contains
 // one of the given strings
 type MockDeployPluginFailComponents struct {
@@ -75,6 +140,10 @@ type MockDeployPluginFailComponents struct {
 
 	// FailAsPanic, if set to true, will panic on matching components. Otherwise it will return an error
 	FailAsPanic bool
+
+	// NotReadyComponents is a list of substrings to search in component names. When found, the corresponding
+	// component will never be reported as ready
+	NotReadyComponents []string
 }
 
 // Cleanup does nothing
@@ -88,7 +157,7 @@ func (p *MockDeployPluginFailComponents) GetSupportedCodeTypes() []string {
 }
 
 // Create does nothing, except failing components if their name contains of the strings from FailComponents
-func (p *MockDeployPluginFailComponents) Create(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+func (p *MockDeployPluginFailComponents) Create(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
 	eventLog.WithFields(event.Fields{}).Infof("[+] %s", deployName)
 	for _, s := range p.FailComponents {
 		if strings.Contains(deployName, s) {
@@ -99,7 +168,7 @@ func (p *MockDeployPluginFailComponents) Create(cluster *lang.Cluster, deployNam
 }
 
 // Update does nothing, except failing components if their name contains of the strings from FailComponents
-func (p *MockDeployPluginFailComponents) Update(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+func (p *MockDeployPluginFailComponents) Update(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
 	eventLog.WithFields(event.Fields{}).Infof("[*] %s", deployName)
 	for _, s := range p.FailComponents {
 		if strings.Contains(deployName, s) {
@@ -110,7 +179,7 @@ func (p *MockDeployPluginFailComponents) Update(cluster *lang.Cluster, deployNam
 }
 
 // Destroy does nothing, except failing components if their name contains of the strings from FailComponents
-func (p *MockDeployPluginFailComponents) Destroy(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
+func (p *MockDeployPluginFailComponents) Destroy(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) error {
 	eventLog.WithFields(event.Fields{}).Infof("[-] %s", deployName)
 	for _, s := range p.FailComponents {
 		if strings.Contains(deployName, s) {
@@ -129,16 +198,26 @@ func (p *MockDeployPluginFailComponents) fail(action string, deployName string)
 }
 
 // Endpoints always returns an empty set of endpoints
-func (p *MockDeployPluginFailComponents) Endpoints(cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error) {
+func (p *MockDeployPluginFailComponents) Endpoints(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (map[string]string, error) {
 	return make(map[string]string), nil
 }
 
+// Status reports that component instance is ready, unless its name contains one of the strings from NotReadyComponents
+func (p *MockDeployPluginFailComponents) Status(ctx context.Context, cluster *lang.Cluster, deployName string, params util.NestedParameterMap, eventLog *event.Log) (bool, error) {
+	for _, s := range p.NotReadyComponents {
+		if strings.Contains(deployName, s) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // MockPostProcessPlugin is a mock post-processing plugin which does nothing
 type MockPostProcessPlugin struct {
 }
 
 // Process does nothing
-func (p *MockPostProcessPlugin) Process(desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, externalData *external.Data, eventLog *event.Log) error {
+func (p *MockPostProcessPlugin) Process(ctx context.Context, desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, externalData *external.Data, eventLog *event.Log) error {
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"context"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/external"
@@ -8,9 +9,10 @@ import (
 )
 
 // PostProcessPlugin is a definition of post-processing plugin which gets called once by an action from the engine
-// applier, after engine is done processing all component instances
+// applier, after engine is done processing all component instances. Plugins should stop processing and return an error
+// once the given context is done
 type PostProcessPlugin interface {
 	Plugin
 
-	Process(desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, externalData *external.Data, eventLog *event.Log) error
+	Process(ctx context.Context, desiredPolicy *lang.Policy, desiredState *resolve.PolicyResolution, externalData *external.Data, eventLog *event.Log) error
 }
@@ -5,10 +5,12 @@ import "fmt"
 // Registry is a registry of all Aptomi engine plugins
 type Registry interface {
 	GetDeployPlugin(codeType string) (DeployPlugin, error)
+	GetDeployPlugins() []DeployPlugin
 	GetPostProcessingPlugins() []PostProcessPlugin
 }
 
 type defaultRegistry struct {
+	deployPluginsList  []DeployPlugin
 	deployPlugins      map[string]DeployPlugin
 	postProcessPlugins []PostProcessPlugin
 }
@@ -26,6 +28,7 @@ func NewRegistry(deployPlugins []DeployPlugin, postProcessPlugins []PostProcessP
 		}
 	}
 	return &defaultRegistry{
+		deployPluginsList:  deployPlugins,
 		deployPlugins:      deployPluginsMap,
 		postProcessPlugins: postProcessPlugins,
 	}
@@ -39,6 +42,10 @@ func (reg *defaultRegistry) GetDeployPlugin(codeType string) (DeployPlugin, erro
 	return plugin, nil
 }
 
+func (reg *defaultRegistry) GetDeployPlugins() []DeployPlugin {
+	return reg.deployPluginsList
+}
+
 func (reg *defaultRegistry) GetPostProcessingPlugins() []PostProcessPlugin {
 	return reg.postProcessPlugins
 }
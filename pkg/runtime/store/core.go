@@ -1,19 +1,27 @@
 package store
 
 import (
+	"context"
 	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/engine/actual"
 	"github.com/Aptomi/aptomi/pkg/engine/progress"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
 )
 
 // Core represents main object store interface that covers database operations for all objects
 type Core interface {
 	Policy
 	Revision
+	RevisionLog
 	ActualState
+	Lease
+	Drift
+	StateRebuild
+	GarbageCollection
 }
 
 // Policy represents database operations for Policy object
@@ -23,6 +31,12 @@ type Policy interface {
 	InitPolicy() error
 	UpdatePolicy(updated []lang.Base, performedBy string) (changed bool, data *engine.PolicyData, err error)
 	DeleteFromPolicy(deleted []lang.Base, performedBy string) (changed bool, data *engine.PolicyData, err error)
+	RollbackPolicy(gen runtime.Generation, performedBy string) (changed bool, data *engine.PolicyData, err error)
+
+	// SubscribePolicyChanges returns a channel, which gets notified every time policy gets changed, including changes
+	// made through other store instances sharing the same DB. Bursts of changes are coalesced into a single
+	// notification. Subscription is active until the given context gets cancelled
+	SubscribePolicyChanges(ctx context.Context) <-chan struct{}
 }
 
 // Revision represents database operations for Revision object
@@ -33,12 +47,49 @@ type Revision interface {
 	NewRevision(policyGen runtime.Generation) (*engine.Revision, error)
 	SaveRevision(revision *engine.Revision) error
 	UpdateRevision(revision *engine.Revision) error
+	ApproveRevision(gen runtime.Generation, approvedBy string) (*engine.Revision, error)
+	RejectRevision(gen runtime.Generation, rejectedBy string) (*engine.Revision, error)
 	GetRevisionProgressUpdater(revision *engine.Revision) progress.Indicator
 }
 
+// RevisionLog represents database operations for the event logs of revisions
+type RevisionLog interface {
+	GetRevisionLog(gen runtime.Generation) (*engine.RevisionLog, error)
+	SaveRevisionLog(gen runtime.Generation, eventLog *event.Log) error
+	DeleteRevisionLogs(olderThan runtime.Generation) error
+}
+
 // ActualState represents database operations for the actual state handling
 type ActualState interface {
 	GetActualState() (*resolve.PolicyResolution, error)
 	GetActualStateUpdater() actual.StateUpdater
 	ResetActualState() error
 }
+
+// Lease represents database operations for the leases
+type Lease interface {
+	GetLease(name string) (*engine.Lease, error)
+	AcquireLease(name string, holder string, duration time.Duration) (lease *engine.Lease, acquired bool, err error)
+	ReleaseLease(name string, holder string) error
+}
+
+// Drift represents database operations for the results of drift checks
+type Drift interface {
+	GetDriftReport() (*engine.DriftReport, error)
+	SaveDriftReport(report *engine.DriftReport) error
+}
+
+// StateRebuild represents database operations for the requests to rebuild actual state from the cloud
+type StateRebuild interface {
+	GetStateRebuild() (*engine.StateRebuild, error)
+	RequestStateRebuild(requestedBy string, reset bool) (*engine.StateRebuild, error)
+	SaveStateRebuild(rebuild *engine.StateRebuild) error
+}
+
+// GarbageCollection represents database operations for the garbage collection of orphaned deployments
+type GarbageCollection interface {
+	GetGarbageCollection() (*engine.GarbageCollection, error)
+	RequestGarbageCollectionPlan(requestedBy string) (*engine.GarbageCollection, error)
+	RequestGarbageCollectionRun(requestedBy string, plannedAt time.Time) (*engine.GarbageCollection, error)
+	SaveGarbageCollection(collection *engine.GarbageCollection) error
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"github.com/Aptomi/aptomi/pkg/runtime/store"
 	"sync"
+	"time"
 )
 
 // defaultStore is the generic store implementation that is the glue layer for saving
@@ -10,9 +11,16 @@ import (
 type defaultStore struct {
 	policyChangeLock sync.Mutex
 	store            store.Generic
+
+	// policySubscribers are notified on policy changes
+	policySubscribersLock sync.Mutex
+	policySubscribers     []*policySubscriber
+
+	// policyChangesPollInterval is how often subscribers check for policy changes made through other store instances
+	policyChangesPollInterval time.Duration
 }
 
 // NewStore returns default implementation of generic store
 func NewStore(store store.Generic) store.Core {
-	return &defaultStore{sync.Mutex{}, store}
+	return &defaultStore{store: store, policyChangesPollInterval: policyChangesPollInterval}
 }
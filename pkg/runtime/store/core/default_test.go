@@ -0,0 +1,35 @@
+package core
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/bolt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestStore returns store backed by the bolt DB in a temp dir along with the function, which closes the store and
+// deletes the temp dir
+func newTestStore(t *testing.T) (store.Core, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "aptomi-store-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		t.FailNow()
+	}
+
+	b := bolt.NewGenericStore(runtime.NewRegistry().Append(store.Objects...))
+	err = b.Open(config.DB{Connection: filepath.Join(dir, "db.bolt")})
+	if !assert.NoError(t, err, "Store should be opened") {
+		os.RemoveAll(dir) // nolint: errcheck
+		t.FailNow()
+	}
+
+	return NewStore(b), func() {
+		b.Close()         // nolint: errcheck
+		os.RemoveAll(dir) // nolint: errcheck
+	}
+}
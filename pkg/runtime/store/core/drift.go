@@ -0,0 +1,34 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+)
+
+// GetDriftReport returns results of the latest drift check or nil if drift hasn't been checked yet
+func (ds *defaultStore) GetDriftReport() (*engine.DriftReport, error) {
+	reportObj, err := ds.store.Get(engine.DriftReportKey)
+	if err != nil {
+		return nil, err
+	}
+	if reportObj == nil {
+		return nil, nil
+	}
+
+	report, ok := reportObj.(*engine.DriftReport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type while getting DriftReport from DB")
+	}
+
+	return report, nil
+}
+
+// SaveDriftReport saves results of the drift check, overwriting the previous ones
+func (ds *defaultStore) SaveDriftReport(report *engine.DriftReport) error {
+	_, err := ds.store.Save(report)
+	if err != nil {
+		return fmt.Errorf("error while saving drift report: %s", err)
+	}
+
+	return nil
+}
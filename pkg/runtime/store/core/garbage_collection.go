@@ -0,0 +1,111 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// GetGarbageCollection returns the latest garbage collection or nil if it has never been requested
+func (ds *defaultStore) GetGarbageCollection() (*engine.GarbageCollection, error) {
+	collectionObj, err := ds.store.Get(engine.GarbageCollectionKey)
+	if err != nil {
+		return nil, err
+	}
+	if collectionObj == nil {
+		return nil, nil
+	}
+
+	collection, ok := collectionObj.(*engine.GarbageCollection)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type while getting GarbageCollection from DB")
+	}
+
+	return collection, nil
+}
+
+// RequestGarbageCollectionPlan requests orphans to be found by enforcer. It fails if the previous garbage collection
+// is still in progress
+func (ds *defaultStore) RequestGarbageCollectionPlan(requestedBy string) (*engine.GarbageCollection, error) {
+	prev, err := ds.GetGarbageCollection()
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && prev.IsInProgress() {
+		return nil, fmt.Errorf("garbage collection is in progress: %s", prev.Status)
+	}
+
+	collection := &engine.GarbageCollection{
+		TypeKind:        engine.GarbageCollectionObject.GetTypeKind(),
+		Status:          engine.GarbageCollectionStatusPlanRequested,
+		PlanRequestedBy: requestedBy,
+	}
+	err = ds.SaveGarbageCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	// orphans should be found right away, same way as changed policy gets enforced
+	err = ds.notifyPolicyChanged()
+	if err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// RequestGarbageCollectionRun confirms deletion of the orphans found by the plan made at plannedAt, so they get
+// deleted by enforcer. It fails if there is no plan with orphans to delete or if the latest plan isn't the one, which
+// has been confirmed (e.g. another plan has been made after the user reviewed orphans)
+func (ds *defaultStore) RequestGarbageCollectionRun(requestedBy string, plannedAt time.Time) (*engine.GarbageCollection, error) {
+	collection := &engine.GarbageCollection{TypeKind: engine.GarbageCollectionObject.GetTypeKind()}
+
+	// plan is checked and confirmed atomically, so orphans which haven't been confirmed never get deleted
+	var errConfirm error
+	_, err := ds.store.SaveIf(collection, func(existing runtime.Storable) bool {
+		if existing == nil || existing.(*engine.GarbageCollection).Status != engine.GarbageCollectionStatusPlanned {
+			errConfirm = fmt.Errorf("garbage collection should be planned first")
+			return false
+		}
+
+		*collection = *existing.(*engine.GarbageCollection)
+		if !collection.PlannedAt.Equal(plannedAt) {
+			errConfirm = fmt.Errorf("garbage collection plan made at %s has been confirmed, but the latest plan was made at %s", plannedAt, collection.PlannedAt)
+			return false
+		}
+		if len(collection.Orphans) <= 0 {
+			errConfirm = fmt.Errorf("no orphans found by garbage collection plan")
+			return false
+		}
+
+		collection.Status = engine.GarbageCollectionStatusRunRequested
+		collection.RunRequestedBy = requestedBy
+		errConfirm = nil
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while saving garbage collection: %s", err)
+	}
+	if errConfirm != nil {
+		return nil, errConfirm
+	}
+
+	// orphans should be deleted right away, same way as changed policy gets enforced
+	err = ds.notifyPolicyChanged()
+	if err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// SaveGarbageCollection saves the garbage collection, overwriting the previous one
+func (ds *defaultStore) SaveGarbageCollection(collection *engine.GarbageCollection) error {
+	_, err := ds.store.Save(collection)
+	if err != nil {
+		return fmt.Errorf("error while saving garbage collection: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/gc"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRequestGarbageCollection(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	// orphans can't be deleted until they are found
+	_, err := s.RequestGarbageCollectionRun("admin", time.Time{})
+	assert.Error(t, err, "Garbage collection should not run without plan")
+
+	collection, err := s.RequestGarbageCollectionPlan("admin")
+	assert.NoError(t, err, "Garbage collection plan should be requested")
+	assert.True(t, collection.IsInProgress(), "Garbage collection should be in progress")
+	_, err = s.RequestGarbageCollectionPlan("admin")
+	assert.Error(t, err, "Garbage collection plan should not be requested while in progress")
+	_, err = s.RequestGarbageCollectionRun("admin", time.Time{})
+	assert.Error(t, err, "Garbage collection should not run until planned")
+
+	// plan without orphans can't be run
+	collection.Status = engine.GarbageCollectionStatusPlanned
+	collection.PlannedAt = time.Now()
+	assert.NoError(t, s.SaveGarbageCollection(collection), "Garbage collection should be saved")
+	_, err = s.RequestGarbageCollectionRun("admin", collection.PlannedAt)
+	assert.Error(t, err, "Garbage collection should not run without orphans")
+
+	// plan with orphans gets confirmed
+	collection.Orphans = []*gc.Orphan{{Cluster: "cluster", Name: "orphan"}}
+	assert.NoError(t, s.SaveGarbageCollection(collection), "Garbage collection should be saved")
+	_, err = s.RequestGarbageCollectionRun("admin", collection.PlannedAt.Add(-time.Minute))
+	assert.Error(t, err, "Garbage collection should not run if another plan has been confirmed")
+	collection, err = s.RequestGarbageCollectionRun("admin", collection.PlannedAt)
+	assert.NoError(t, err, "Garbage collection should be requested to run")
+	assert.Equal(t, engine.GarbageCollectionStatusRunRequested, collection.Status, "Garbage collection should be requested to run")
+
+	loaded, err := s.GetGarbageCollection()
+	assert.NoError(t, err, "Garbage collection should be loaded")
+	assert.Equal(t, "admin", loaded.RunRequestedBy, "Confirmation should be persisted")
+	assert.Equal(t, collection.Orphans, loaded.Orphans, "Orphans should be persisted")
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// GetLease returns lease with the given name or nil if it doesn't exist
+func (ds *defaultStore) GetLease(name string) (*engine.Lease, error) {
+	leaseObj, err := ds.store.Get(runtime.KeyFromParts(runtime.SystemNS, engine.LeaseObject.Kind, name))
+	if err != nil {
+		return nil, err
+	}
+	if leaseObj == nil {
+		return nil, nil
+	}
+
+	lease, ok := leaseObj.(*engine.Lease)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type while getting Lease from DB")
+	}
+
+	return lease, nil
+}
+
+// AcquireLease acquires (or renews, if it's already held by the same holder) lease with the given name for the given
+// duration. Lease could be acquired only if it doesn't exist, it's held by the same holder or it's already expired.
+// Fencing token of the lease is incremented, unless it's renewed before expiration.
+// If lease isn't acquired, then lease of the current holder is returned
+func (ds *defaultStore) AcquireLease(name string, holder string, duration time.Duration) (*engine.Lease, bool, error) {
+	now := time.Now()
+	lease := &engine.Lease{
+		TypeKind:   engine.LeaseObject.GetTypeKind(),
+		Metadata:   engine.LeaseMetadata{Name: name},
+		Holder:     holder,
+		AcquiredAt: now,
+		RenewedAt:  now,
+		ExpiresAt:  now.Add(duration),
+	}
+
+	var current *engine.Lease
+	acquired, err := ds.store.SaveIf(lease, func(existing runtime.Storable) bool {
+		if existing == nil {
+			lease.Token = 1
+			return true
+		}
+
+		current = existing.(*engine.Lease)
+		if current.Holder == holder && !current.IsExpired(now) {
+			// renewing own lease
+			lease.AcquiredAt = current.AcquiredAt
+			lease.Token = current.Token
+			return true
+		}
+
+		lease.Token = current.Token + 1
+		return current.IsExpired(now)
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("error while acquiring lease '%s': %s", name, err)
+	}
+
+	if !acquired {
+		if current == nil {
+			// lease has been created concurrently by someone else
+			current, err = ds.GetLease(name)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		return current, false, nil
+	}
+
+	return lease, true, nil
+}
+
+// ReleaseLease releases lease with the given name, if it's held by the given holder, so it could be acquired by
+// someone else right away instead of waiting for it to expire
+func (ds *defaultStore) ReleaseLease(name string, holder string) error {
+	lease := &engine.Lease{
+		TypeKind: engine.LeaseObject.GetTypeKind(),
+		Metadata: engine.LeaseMetadata{Name: name},
+	}
+	_, err := ds.store.SaveIf(lease, func(existing runtime.Storable) bool {
+		if existing == nil || existing.(*engine.Lease).Holder != holder {
+			return false
+		}
+
+		*lease = *existing.(*engine.Lease)
+		lease.ExpiresAt = time.Now()
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("error while releasing lease '%s': %s", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLease(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	// no lease initially
+	lease, err := s.GetLease(engine.LeaderLeaseName)
+	assert.NoError(t, err, "Lease should be loaded without errors")
+	assert.Nil(t, lease, "Lease should not exist")
+
+	// first replica acquires the lease
+	lease, acquired, err := s.AcquireLease(engine.LeaderLeaseName, "replica-1", 200*time.Millisecond)
+	assert.NoError(t, err, "Lease should be acquired without errors")
+	assert.True(t, acquired, "Lease should be acquired by the first replica")
+	assert.Equal(t, uint64(1), lease.Token, "First lease should get the first fencing token")
+	acquiredAt := lease.AcquiredAt
+
+	// second replica can't acquire the lease while it's held by the first one
+	lease, acquired, err = s.AcquireLease(engine.LeaderLeaseName, "replica-2", 200*time.Millisecond)
+	assert.NoError(t, err, "Lease should be acquired without errors")
+	assert.False(t, acquired, "Lease should not be acquired by the second replica")
+	assert.Equal(t, "replica-1", lease.Holder, "Current lease holder should be returned")
+
+	// first replica renews the lease
+	lease, acquired, err = s.AcquireLease(engine.LeaderLeaseName, "replica-1", 200*time.Millisecond)
+	assert.NoError(t, err, "Lease should be renewed without errors")
+	assert.True(t, acquired, "Lease should be renewed by the holder")
+	assert.Equal(t, acquiredAt.UnixNano(), lease.AcquiredAt.UnixNano(), "Acquisition time should be preserved on renewal")
+	assert.Equal(t, uint64(1), lease.Token, "Fencing token should be preserved on renewal")
+
+	// second replica takes over the lease once it expires
+	time.Sleep(300 * time.Millisecond)
+	lease, acquired, err = s.AcquireLease(engine.LeaderLeaseName, "replica-2", 200*time.Millisecond)
+	assert.NoError(t, err, "Lease should be acquired without errors")
+	assert.True(t, acquired, "Expired lease should be taken over by the second replica")
+	assert.Equal(t, "replica-2", lease.Holder, "Lease holder should be changed")
+	assert.Equal(t, uint64(2), lease.Token, "Fencing token should be incremented when lease changes hands")
+
+	lease, err = s.GetLease(engine.LeaderLeaseName)
+	assert.NoError(t, err, "Lease should be loaded without errors")
+	assert.Equal(t, "replica-2", lease.Holder, "Lease should be stored")
+
+	// lease can only be released by its holder
+	assert.NoError(t, s.ReleaseLease(engine.LeaderLeaseName, "replica-1"), "Lease should be released without errors")
+	lease, err = s.GetLease(engine.LeaderLeaseName)
+	assert.NoError(t, err, "Lease should be loaded without errors")
+	assert.False(t, lease.IsExpired(time.Now()), "Lease should not be released by non-holder")
+
+	// once released, lease could be acquired right away
+	assert.NoError(t, s.ReleaseLease(engine.LeaderLeaseName, "replica-2"), "Lease should be released without errors")
+	lease, acquired, err = s.AcquireLease(engine.LeaderLeaseName, "replica-1", 200*time.Millisecond)
+	assert.NoError(t, err, "Lease should be acquired without errors")
+	assert.True(t, acquired, "Released lease should be acquired by another replica")
+	assert.Equal(t, uint64(3), lease.Token, "Fencing token should be incremented when lease changes hands")
+}
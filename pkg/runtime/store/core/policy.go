@@ -99,6 +99,11 @@ func (ds *defaultStore) UpdatePolicy(updatedObjects []lang.Base, performedBy str
 		if err != nil {
 			return false, nil, err
 		}
+
+		err = ds.notifyPolicyChanged()
+		if err != nil {
+			return false, nil, err
+		}
 	}
 
 	return changed, policyData, err
@@ -148,6 +153,51 @@ func (ds *defaultStore) DeleteFromPolicy(deleted []lang.Base, performedBy string
 		if err != nil {
 			return false, nil, err
 		}
+
+		err = ds.notifyPolicyChanged()
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
+	return changed, policyData, nil
+}
+
+// RollbackPolicy creates new policy generation with the same set of objects as in the provided policy generation
+func (ds *defaultStore) RollbackPolicy(gen runtime.Generation, performedBy string) (bool, *engine.PolicyData, error) {
+	// we should process only a single policy update request at once
+	ds.policyChangeLock.Lock()
+	defer ds.policyChangeLock.Unlock()
+
+	targetPolicyData, err := ds.GetPolicyData(gen)
+	if err != nil {
+		return false, nil, err
+	}
+	if targetPolicyData == nil {
+		return false, nil, fmt.Errorf("policy generation %d not found", gen)
+	}
+
+	policyData, err := ds.GetPolicyData(runtime.LastGen)
+	if err != nil {
+		return false, nil, err
+	}
+
+	changed := !targetPolicyData.Equals(policyData)
+	if changed {
+		policyData.Objects = targetPolicyData.CopyObjects()
+		policyData.Metadata.UpdatedAt = time.Now()
+		policyData.Metadata.UpdatedBy = performedBy
+
+		// save policy data
+		_, err = ds.store.Save(policyData)
+		if err != nil {
+			return false, nil, err
+		}
+
+		err = ds.notifyPolicyChanged()
+		if err != nil {
+			return false, nil, err
+		}
 	}
 
 	return changed, policyData, nil
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// policyChangesPollInterval is how often subscribers check the store for policy changes made through other store
+// instances, i.e. by other server replicas
+const policyChangesPollInterval = time.Second
+
+// policySubscriber is a subscriber to policy changes
+type policySubscriber struct {
+	changes chan struct{}
+
+	// seen is the counter of the latest policy change subscriber got notified about
+	seen uint64
+}
+
+// notify notifies subscriber about policy change, without blocking if it already has a pending notification. It
+// should be called while holding policySubscribersLock
+func (subscriber *policySubscriber) notify(counter uint64) {
+	if counter <= subscriber.seen {
+		return
+	}
+	subscriber.seen = counter
+
+	select {
+	case subscriber.changes <- struct{}{}:
+	default:
+	}
+}
+
+// SubscribePolicyChanges returns a channel, which gets notified every time policy gets changed. Changes made through
+// this store are notified right away, while changes made through other store instances sharing the same DB are
+// noticed by polling the store. Channel holds at most one pending notification, so a burst of policy changes results
+// in a single notification. Subscription is active until the given context gets cancelled
+func (ds *defaultStore) SubscribePolicyChanges(ctx context.Context) <-chan struct{} {
+	subscriber := &policySubscriber{changes: make(chan struct{}, 1)}
+
+	// changes made before subscription shouldn't be notified. If they can't be loaded, subscriber just gets notified
+	// once more, which only results in an extra enforcement
+	changes, err := ds.getPolicyChanges()
+	if err == nil && changes != nil {
+		subscriber.seen = changes.Counter
+	}
+
+	ds.policySubscribersLock.Lock()
+	ds.policySubscribers = append(ds.policySubscribers, subscriber)
+	ds.policySubscribersLock.Unlock()
+
+	go ds.watchPolicyChanges(ctx, subscriber)
+
+	return subscriber.changes
+}
+
+// watchPolicyChanges polls the store for policy changes made through other store instances and notifies the given
+// subscriber about them, until the given context gets cancelled
+func (ds *defaultStore) watchPolicyChanges(ctx context.Context, subscriber *policySubscriber) {
+	ticker := time.NewTicker(ds.policyChangesPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ds.unsubscribePolicyChanges(subscriber)
+			return
+		case <-ticker.C:
+		}
+
+		// errors are ignored, as changes will be noticed on the next poll and enforcer does periodic resyncs anyway
+		changes, err := ds.getPolicyChanges()
+		if err != nil || changes == nil {
+			continue
+		}
+
+		ds.policySubscribersLock.Lock()
+		subscriber.notify(changes.Counter)
+		ds.policySubscribersLock.Unlock()
+	}
+}
+
+func (ds *defaultStore) unsubscribePolicyChanges(subscriber *policySubscriber) {
+	ds.policySubscribersLock.Lock()
+	defer ds.policySubscribersLock.Unlock()
+
+	for idx, existing := range ds.policySubscribers {
+		if existing == subscriber {
+			ds.policySubscribers = append(ds.policySubscribers[:idx], ds.policySubscribers[idx+1:]...)
+			return
+		}
+	}
+}
+
+// getPolicyChanges returns the latest policy change or nil if policy has never been changed
+func (ds *defaultStore) getPolicyChanges() (*engine.PolicyChanges, error) {
+	changesObj, err := ds.store.Get(engine.PolicyChangesKey)
+	if err != nil {
+		return nil, err
+	}
+	if changesObj == nil {
+		return nil, nil
+	}
+
+	changes, ok := changesObj.(*engine.PolicyChanges)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type while getting PolicyChanges from DB")
+	}
+
+	return changes, nil
+}
+
+// notifyPolicyChanged records policy change into the store, so subscribers of all store instances get notified about
+// it, and notifies subscribers of this store right away
+func (ds *defaultStore) notifyPolicyChanged() error {
+	changes := &engine.PolicyChanges{
+		TypeKind:  engine.PolicyChangesObject.GetTypeKind(),
+		ChangedAt: time.Now(),
+	}
+
+	// counter is incremented atomically, so changes made through different store instances are never lost
+	_, err := ds.store.SaveIf(changes, func(existing runtime.Storable) bool {
+		changes.Counter = 1
+		if existing != nil {
+			changes.Counter = existing.(*engine.PolicyChanges).Counter + 1
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("error while saving policy changes: %s", err)
+	}
+
+	ds.policySubscribersLock.Lock()
+	defer ds.policySubscribersLock.Unlock()
+
+	for _, subscriber := range ds.policySubscribers {
+		subscriber.notify(changes.Counter)
+	}
+
+	return nil
+}
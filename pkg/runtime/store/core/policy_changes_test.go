@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPolicyChanges(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	if !assert.NoError(t, s.InitPolicy(), "Policy should be initialized") {
+		t.FailNow()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	policyChanges := s.SubscribePolicyChanges(ctx)
+
+	cluster := &lang.Cluster{
+		TypeKind: lang.ClusterObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: runtime.SystemNS, Name: "cluster"},
+		Type:     "kubernetes",
+		Config:   "something",
+	}
+
+	// a burst of policy changes should result in a single notification
+	for _, clusterConfig := range []string{"config-1", "config-2", "config-3"} {
+		cluster.Config = clusterConfig
+		changed, _, errUpdate := s.UpdatePolicy([]lang.Base{cluster}, "test")
+		assert.NoError(t, errUpdate, "Policy should be updated without errors")
+		assert.True(t, changed, "Policy should be changed")
+	}
+	assert.Len(t, policyChanges, 1, "Policy changes should be coalesced into a single notification")
+	<-policyChanges
+
+	// policy update without changes should not result in a notification
+	changed, _, err := s.UpdatePolicy([]lang.Base{cluster}, "test")
+	assert.NoError(t, err, "Policy should be updated without errors")
+	assert.False(t, changed, "Policy should not be changed")
+	assert.Len(t, policyChanges, 0, "There should be no notification if policy isn't changed")
+
+	// deleting from policy should result in a notification
+	changed, _, err = s.DeleteFromPolicy([]lang.Base{cluster}, "test")
+	assert.NoError(t, err, "Object should be deleted from policy without errors")
+	assert.True(t, changed, "Policy should be changed")
+	assert.Len(t, policyChanges, 1, "There should be a notification once object is deleted from policy")
+}
+
+func TestPolicyChangesThroughAnotherStore(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	if !assert.NoError(t, s.InitPolicy(), "Policy should be initialized") {
+		t.FailNow()
+	}
+
+	// another store sharing the same DB, as another server replica would do
+	subscriberStore := NewStore(s.(*defaultStore).store).(*defaultStore)
+	subscriberStore.policyChangesPollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	policyChanges := subscriberStore.SubscribePolicyChanges(ctx)
+
+	cluster := &lang.Cluster{
+		TypeKind: lang.ClusterObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: runtime.SystemNS, Name: "cluster"},
+		Type:     "kubernetes",
+		Config:   "something",
+	}
+	changed, _, err := s.UpdatePolicy([]lang.Base{cluster}, "test")
+	assert.NoError(t, err, "Policy should be updated without errors")
+	assert.True(t, changed, "Policy should be changed")
+
+	select {
+	case <-policyChanges:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Policy change made through another store should be notified")
+	}
+
+	// subscription should end once context is cancelled
+	cancel()
+	for idx := 0; idx < 100 && func() bool {
+		subscriberStore.policySubscribersLock.Lock()
+		defer subscriberStore.policySubscribersLock.Unlock()
+		return len(subscriberStore.policySubscribers) > 0
+	}(); idx++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	subscriberStore.policySubscribersLock.Lock()
+	assert.Empty(t, subscriberStore.policySubscribers, "Subscriber should be removed once context is cancelled")
+	subscriberStore.policySubscribersLock.Unlock()
+}
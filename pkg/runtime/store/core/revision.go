@@ -114,22 +114,103 @@ func (ds *defaultStore) UpdateRevision(revision *engine.Revision) error {
 	return nil
 }
 
+// ApproveRevision approves Revision for specified generation, which got blocked or requires manual approval, so
+// it could be applied
+func (ds *defaultStore) ApproveRevision(gen runtime.Generation, approvedBy string) (*engine.Revision, error) {
+	revision, err := ds.getRevisionWaitingForApproval(gen)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.ApprovedBy = approvedBy
+	revision.ApprovedAt = time.Now()
+
+	err = ds.UpdateRevision(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	// approved revision should be applied right away, same way as changed policy
+	err = ds.notifyPolicyChanged()
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// RejectRevision rejects Revision for specified generation, which got blocked or requires manual approval, so it
+// won't be applied
+func (ds *defaultStore) RejectRevision(gen runtime.Generation, rejectedBy string) (*engine.Revision, error) {
+	revision, err := ds.getRevisionWaitingForApproval(gen)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.Status = engine.RevisionStatusRejected
+	revision.RejectedBy = rejectedBy
+	revision.RejectedAt = time.Now()
+
+	err = ds.UpdateRevision(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+func (ds *defaultStore) getRevisionWaitingForApproval(gen runtime.Generation) (*engine.Revision, error) {
+	revision, err := ds.GetRevision(gen)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, fmt.Errorf("revision %d not found", gen)
+	}
+	if !revision.IsWaitingForApproval() {
+		return nil, fmt.Errorf("revision %d is not waiting for approval, its status is %s", revision.GetGeneration(), revision.Status)
+	}
+
+	return revision, nil
+}
+
+// revisionProgressSaveInterval is the minimum interval between saving progress of applying revision. Progress gets
+// updated after every action, so saving revision (including results of all actions) every time would be too expensive
+// for revisions with many actions
+const revisionProgressSaveInterval = time.Second
+
+// GetRevisionProgressUpdater returns progress indicator, which persists progress of applying the given revision along
+// with results of individual actions. Intermediate progress is saved at most once per revisionProgressSaveInterval,
+// while initial and final progress is always saved
 func (ds *defaultStore) GetRevisionProgressUpdater(revision *engine.Revision) progress.Indicator {
-	return &revisionProgressUpdater{ds, revision}
+	return &revisionProgressUpdater{
+		store:        ds,
+		revision:     revision,
+		saveInterval: revisionProgressSaveInterval,
+	}
 }
 
 type revisionProgressUpdater struct {
-	store    store.Core
-	revision *engine.Revision
+	store        store.Core
+	revision     *engine.Revision
+	saveInterval time.Duration
+	savedAt      time.Time
 }
 
 func (p *revisionProgressUpdater) save() {
+	p.savedAt = time.Now()
 	err := p.store.UpdateRevision(p.revision)
 	if err != nil {
 		log.Warnf("Unable to save revision %s progress with err: %s", p.revision.GetGeneration(), err)
 	}
 }
 
+func (p *revisionProgressUpdater) saveIntermediate() {
+	if time.Since(p.savedAt) >= p.saveInterval {
+		p.save()
+	}
+}
+
 func (p *revisionProgressUpdater) SetTotal(total int) {
 	p.revision.Progress.Total = total
 	p.save()
@@ -137,7 +218,7 @@ func (p *revisionProgressUpdater) SetTotal(total int) {
 
 func (p *revisionProgressUpdater) Advance() {
 	p.revision.Progress.Current++
-	p.save()
+	p.saveIntermediate()
 }
 
 func (p *revisionProgressUpdater) Done(success bool) {
@@ -154,6 +235,37 @@ func (p *revisionProgressUpdater) Done(success bool) {
 	p.save()
 }
 
+func (p *revisionProgressUpdater) ActionStarted(idx int, startedAt time.Time) {
+	if idx < 0 || idx >= len(p.revision.Actions) {
+		return
+	}
+	act := p.revision.Actions[idx]
+	act.Status = engine.RevisionActionStatusRunning
+	act.StartedAt = startedAt
+	p.saveIntermediate()
+}
+
+func (p *revisionProgressUpdater) ActionFinished(idx int, finishedAt time.Time, err error, skipped bool) {
+	if idx < 0 || idx >= len(p.revision.Actions) {
+		return
+	}
+	act := p.revision.Actions[idx]
+	act.Status = engine.RevisionActionStatusSuccess
+	if skipped {
+		act.Status = engine.RevisionActionStatusSkipped
+	} else if err != nil {
+		act.Status = engine.RevisionActionStatusError
+	}
+	if err != nil {
+		act.Error = err.Error()
+	}
+	act.FinishedAt = finishedAt
+	if !act.StartedAt.IsZero() {
+		act.Duration = finishedAt.Sub(act.StartedAt)
+	}
+	p.saveIntermediate()
+}
+
 func (p *revisionProgressUpdater) IsDone() bool {
 	return p.revision.Status != engine.RevisionStatusInProgress
 }
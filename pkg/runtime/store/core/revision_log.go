@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"strconv"
+	"strings"
+)
+
+// GetRevisionLog returns event log of the Revision with specified generation (or the last one) or nil if there is no
+// event log stored for it
+func (ds *defaultStore) GetRevisionLog(gen runtime.Generation) (*engine.RevisionLog, error) {
+	if gen == runtime.LastGen {
+		revision, err := ds.GetRevision(runtime.LastGen)
+		if err != nil {
+			return nil, err
+		}
+		if revision == nil {
+			return nil, nil
+		}
+		gen = revision.GetGeneration()
+	}
+
+	revisionLogObj, err := ds.store.Get(runtime.KeyFromParts(runtime.SystemNS, engine.RevisionLogObject.Kind, gen.String()))
+	if err != nil {
+		return nil, err
+	}
+	if revisionLogObj == nil {
+		return nil, nil
+	}
+
+	revisionLog, ok := revisionLogObj.(*engine.RevisionLog)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type while getting RevisionLog from DB")
+	}
+
+	return revisionLog, nil
+}
+
+// SaveRevisionLog appends all entries from the given event log to the event log of the Revision with specified
+// generation and saves it into the store
+func (ds *defaultStore) SaveRevisionLog(gen runtime.Generation, eventLog *event.Log) error {
+	revisionLog, err := ds.GetRevisionLog(gen)
+	if err != nil {
+		return fmt.Errorf("error while getting revision log: %s", err)
+	}
+	if revisionLog == nil {
+		revisionLog = &engine.RevisionLog{
+			TypeKind: engine.RevisionLogObject.GetTypeKind(),
+			Metadata: engine.RevisionLogMetadata{Revision: gen},
+			Entries:  []*event.LogEntry{},
+		}
+	}
+
+	hook := &event.HookStore{}
+	eventLog.Save(hook)
+	revisionLog.Entries = append(revisionLog.Entries, hook.Entries...)
+
+	_, err = ds.store.Save(revisionLog)
+	if err != nil {
+		return fmt.Errorf("error while saving revision log: %s", err)
+	}
+
+	return nil
+}
+
+// DeleteRevisionLogs deletes event logs of all Revisions with generations older than specified one. Revision logs
+// could be large, so they are selected by the generation in their keys without being loaded
+func (ds *defaultStore) DeleteRevisionLogs(olderThan runtime.Generation) error {
+	prefix := runtime.KeyFromParts(runtime.SystemNS, engine.RevisionLogObject.Kind, "")
+	keys, err := ds.store.ListKeys(prefix + runtime.KeySeparator)
+	if err != nil {
+		return fmt.Errorf("error while listing revision logs: %s", err)
+	}
+
+	for _, key := range keys {
+		gen, errParse := strconv.ParseUint(strings.TrimPrefix(key, prefix+runtime.KeySeparator), 10, 64)
+		if errParse != nil || runtime.Generation(gen) >= olderThan {
+			continue
+		}
+
+		err = ds.store.Delete(key)
+		if err != nil {
+			return fmt.Errorf("error while deleting revision log: %s", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRevisionLog(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	// no log initially
+	revisionLog, err := s.GetRevisionLog(runtime.FirstGen)
+	assert.NoError(t, err, "Revision log should be loaded without errors")
+	assert.Nil(t, revisionLog, "Revision log should not exist")
+
+	// save resolve and apply logs for a bunch of revisions
+	for gen := runtime.FirstGen; gen <= 12; gen++ {
+		resolveLog := event.NewLog(fmt.Sprintf("enforce-%d-resolve", gen), false)
+		resolveLog.WithFields(event.Fields{"key": "value"}).Info("resolved")
+		assert.NoError(t, s.SaveRevisionLog(gen, resolveLog), "Resolve log should be saved")
+
+		applyLog := event.NewLog(fmt.Sprintf("enforce-%d-apply", gen), false)
+		applyLog.WithFields(event.Fields{}).Error("apply failed")
+		assert.NoError(t, s.SaveRevisionLog(gen, applyLog), "Apply log should be saved")
+	}
+
+	// both logs should be stored for the revision
+	revisionLog, err = s.GetRevisionLog(runtime.Generation(2))
+	assert.NoError(t, err, "Revision log should be loaded without errors")
+	if assert.NotNil(t, revisionLog, "Revision log should exist") && assert.Len(t, revisionLog.Entries, 2, "Both resolve and apply entries should be stored") {
+		assert.Equal(t, "info", revisionLog.Entries[0].Level, "Entry level should be stored")
+		assert.Equal(t, "resolved", revisionLog.Entries[0].Message, "Entry message should be stored")
+		assert.Equal(t, "enforce-2-resolve", revisionLog.Entries[0].Scope, "Entry scope should be stored")
+		assert.Equal(t, map[string]string{"key": "value"}, revisionLog.Entries[0].Fields, "Entry fields should be stored")
+		assert.Equal(t, "error", revisionLog.Entries[1].Level, "Entries should be stored in order")
+
+		entries, errFilter := event.FilterLogEntries(revisionLog.Entries, "warning")
+		assert.NoError(t, errFilter, "Entries should be filtered without errors")
+		assert.Len(t, entries, 1, "Only entries with warning level or more severe should be returned")
+	}
+
+	// logs of old revisions should be deleted (generations are compared as numbers, not strings)
+	assert.NoError(t, s.DeleteRevisionLogs(runtime.Generation(3)), "Old revision logs should be deleted")
+	for gen := runtime.FirstGen; gen <= 12; gen++ {
+		revisionLog, err = s.GetRevisionLog(gen)
+		assert.NoError(t, err, "Revision log should be loaded without errors")
+		assert.Equal(t, gen >= 3, revisionLog != nil, "Only logs of revisions newer than retention should be kept")
+	}
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/progress"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestApproveRevision(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	// non-existing revision can't be approved
+	_, err := s.ApproveRevision(runtime.FirstGen, "admin")
+	assert.Error(t, err, "Non-existing revision should not be approved")
+
+	// revision, which isn't blocked, can't be approved
+	revision, err := s.NewRevision(runtime.FirstGen)
+	assert.NoError(t, err, "New revision should be created")
+	assert.NoError(t, s.SaveRevision(revision), "Revision should be saved")
+	_, err = s.ApproveRevision(revision.GetGeneration(), "admin")
+	assert.Error(t, err, "Revision in progress should not be approved")
+
+	// blocked revision gets approved
+	revision.Status = engine.RevisionStatusBlocked
+	assert.NoError(t, s.UpdateRevision(revision), "Revision should be updated")
+	approved, err := s.ApproveRevision(revision.GetGeneration(), "admin")
+	assert.NoError(t, err, "Blocked revision should be approved")
+	assert.True(t, approved.IsApproved(), "Revision should be approved")
+
+	loaded, err := s.GetRevision(revision.GetGeneration())
+	assert.NoError(t, err, "Revision should be loaded")
+	assert.Equal(t, "admin", loaded.ApprovedBy, "Revision approval should be persisted")
+	assert.Equal(t, engine.RevisionStatusBlocked, loaded.Status, "Revision should stay blocked until enforcer applies it")
+
+	// approved revision can't be approved or rejected again
+	_, err = s.ApproveRevision(revision.GetGeneration(), "admin")
+	assert.Error(t, err, "Approved revision should not be approved again")
+	_, err = s.RejectRevision(revision.GetGeneration(), "admin")
+	assert.Error(t, err, "Approved revision should not be rejected")
+}
+
+func TestRejectRevision(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	revision, err := s.NewRevision(runtime.FirstGen)
+	assert.NoError(t, err, "New revision should be created")
+	revision.Status = engine.RevisionStatusPendingApproval
+	assert.NoError(t, s.SaveRevision(revision), "Revision should be saved")
+
+	rejected, err := s.RejectRevision(revision.GetGeneration(), "admin")
+	assert.NoError(t, err, "Revision pending approval should be rejected")
+	assert.Equal(t, engine.RevisionStatusRejected, rejected.Status, "Revision should be rejected")
+	assert.Equal(t, "admin", rejected.RejectedBy, "Revision should be rejected by user")
+
+	// rejected revision can't be approved
+	_, err = s.ApproveRevision(revision.GetGeneration(), "admin")
+	assert.Error(t, err, "Rejected revision should not be approved")
+}
+
+func TestRevisionProgressUpdaterTracksActions(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	revision, err := s.NewRevision(runtime.FirstGen)
+	assert.NoError(t, err, "New revision should be created")
+	revision.ResetActions([]string{"create", "update", "delete"})
+	assert.NoError(t, s.SaveRevision(revision), "Revision should be saved")
+
+	updater := s.GetRevisionProgressUpdater(revision)
+	tracker, ok := updater.(progress.ActionTracker)
+	if !assert.True(t, ok, "Revision progress updater should track actions") {
+		t.FailNow()
+	}
+
+	// intermediate progress shouldn't be saved more often than save interval
+	updater.(*revisionProgressUpdater).saveInterval = time.Hour
+	startedAt := time.Now()
+	updater.SetTotal(3)
+	tracker.ActionStarted(0, startedAt)
+	tracker.ActionStarted(1, startedAt)
+	tracker.ActionFinished(0, startedAt.Add(time.Second), nil, false)
+	updater.Advance()
+
+	loaded, err := s.GetRevision(revision.GetGeneration())
+	assert.NoError(t, err, "Revision should be loaded")
+	assert.Equal(t, 3, loaded.Progress.Total, "Initial progress should be saved")
+	assert.Equal(t, 0, loaded.Progress.Current, "Intermediate progress should not be saved within save interval")
+	assert.Equal(t, engine.RevisionActionStatusPending, loaded.Actions[0].Status, "Intermediate action results should not be saved within save interval")
+
+	tracker.ActionFinished(1, startedAt.Add(2*time.Second), fmt.Errorf("update failed"), false)
+	updater.Advance()
+	tracker.ActionFinished(2, startedAt.Add(2*time.Second), fmt.Errorf("skipped"), true)
+	updater.Advance()
+	updater.Done(false)
+
+	// results of actions should be persisted once applying is done
+	loaded, err = s.GetRevision(revision.GetGeneration())
+	assert.NoError(t, err, "Revision should be loaded")
+	if assert.Equal(t, 3, len(loaded.Actions), "Revision actions should be persisted") {
+		assert.Equal(t, engine.RevisionActionStatusSuccess, loaded.Actions[0].Status, "Action should succeed")
+		assert.Equal(t, time.Second, loaded.Actions[0].Duration, "Action duration should be recorded")
+		assert.Equal(t, engine.RevisionActionStatusError, loaded.Actions[1].Status, "Action should fail")
+		assert.Equal(t, "update failed", loaded.Actions[1].Error, "Action error should be recorded")
+		assert.Equal(t, engine.RevisionActionStatusSkipped, loaded.Actions[2].Status, "Action should be skipped")
+		assert.True(t, loaded.Actions[2].StartedAt.IsZero(), "Skipped action should not be started")
+	}
+	assert.Equal(t, engine.RevisionStatusError, loaded.Status, "Revision should be failed")
+}
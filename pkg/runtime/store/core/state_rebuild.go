@@ -0,0 +1,67 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"time"
+)
+
+// GetStateRebuild returns the latest request to rebuild actual state or nil if it has never been requested
+func (ds *defaultStore) GetStateRebuild() (*engine.StateRebuild, error) {
+	rebuildObj, err := ds.store.Get(engine.StateRebuildKey)
+	if err != nil {
+		return nil, err
+	}
+	if rebuildObj == nil {
+		return nil, nil
+	}
+
+	rebuild, ok := rebuildObj.(*engine.StateRebuild)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type while getting StateRebuild from DB")
+	}
+
+	return rebuild, nil
+}
+
+// RequestStateRebuild requests actual state to be rebuilt from the cloud by enforcer. If reset is true, actual state
+// gets reset by enforcer right before being rebuilt. It fails if the previous request hasn't been done yet
+func (ds *defaultStore) RequestStateRebuild(requestedBy string, reset bool) (*engine.StateRebuild, error) {
+	prev, err := ds.GetStateRebuild()
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && prev.IsRequested() {
+		return nil, fmt.Errorf("state rebuild has already been requested by %s at %s", prev.RequestedBy, prev.RequestedAt)
+	}
+
+	rebuild := &engine.StateRebuild{
+		TypeKind:    engine.StateRebuildObject.GetTypeKind(),
+		Status:      engine.StateRebuildStatusRequested,
+		RequestedBy: requestedBy,
+		RequestedAt: time.Now(),
+		Reset:       reset,
+	}
+	err = ds.SaveStateRebuild(rebuild)
+	if err != nil {
+		return nil, err
+	}
+
+	// state should be rebuilt right away, same way as changed policy gets enforced
+	err = ds.notifyPolicyChanged()
+	if err != nil {
+		return nil, err
+	}
+
+	return rebuild, nil
+}
+
+// SaveStateRebuild saves the request to rebuild actual state, overwriting the previous one
+func (ds *defaultStore) SaveStateRebuild(rebuild *engine.StateRebuild) error {
+	_, err := ds.store.Save(rebuild)
+	if err != nil {
+		return fmt.Errorf("error while saving state rebuild: %s", err)
+	}
+
+	return nil
+}
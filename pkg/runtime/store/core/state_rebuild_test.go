@@ -0,0 +1,43 @@
+package core
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRequestStateRebuild(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	// state rebuild hasn't been requested yet
+	rebuild, err := s.GetStateRebuild()
+	assert.NoError(t, err, "State rebuild should be loaded")
+	assert.Nil(t, rebuild, "State rebuild should not exist")
+
+	// request state rebuild
+	rebuild, err = s.RequestStateRebuild("admin", false)
+	assert.NoError(t, err, "State rebuild should be requested")
+	assert.True(t, rebuild.IsRequested(), "State rebuild should be requested")
+
+	// state rebuild can't be requested again, until it's done
+	_, err = s.RequestStateRebuild("admin", true)
+	assert.Error(t, err, "State rebuild should not be requested twice")
+
+	rebuild.Status = engine.StateRebuildStatusSuccess
+	rebuild.Adopted = []string{"key"}
+	assert.NoError(t, s.SaveStateRebuild(rebuild), "State rebuild should be saved")
+
+	loaded, err := s.GetStateRebuild()
+	assert.NoError(t, err, "State rebuild should be loaded")
+	assert.Equal(t, engine.StateRebuildStatusSuccess, loaded.Status, "State rebuild status should be persisted")
+	assert.Equal(t, []string{"key"}, loaded.Adopted, "Adopted component instances should be persisted")
+
+	rebuild, err = s.RequestStateRebuild("admin", true)
+	assert.NoError(t, err, "State rebuild should be requested again once it's done")
+	assert.True(t, rebuild.Reset, "State rebuild should be requested with actual state reset")
+
+	loaded, err = s.GetStateRebuild()
+	assert.NoError(t, err, "State rebuild should be loaded")
+	assert.True(t, loaded.Reset, "Actual state reset should be persisted")
+}
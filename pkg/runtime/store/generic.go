@@ -14,6 +14,8 @@ type Generic interface {
 	GetGen(key string, gen runtime.Generation) (runtime.Versioned, error)
 
 	List(prefix string) ([]runtime.Storable, error)
+	// ListKeys returns keys of all objects with keys starting with the given prefix without loading objects themselves
+	ListKeys(prefix string) ([]string, error)
 	ListGenerations(key string) ([]runtime.Storable, error)
 
 	// Save could create new object in db or create new generation for existing object
@@ -22,6 +24,9 @@ type Generic interface {
 	// Update always updates existing object in db and not creating new generation even for versioned objects
 	// todo(slukjanov): introduce "status" for objects and don't update version when only status changed
 	Update(runtime.Storable) (updated bool, err error)
+	// SaveIf saves non-versioned object only if condition returns true for the object currently stored in db (nil if
+	// there is no such object). Check and save are performed atomically, so it could be used for implementing leases
+	SaveIf(obj runtime.Storable, condition func(existing runtime.Storable) bool) (saved bool, err error)
 
 	Delete(key string) error
 }
@@ -147,6 +147,30 @@ func (bs *boltStore) List(prefix string) ([]runtime.Storable, error) {
 	return result, err
 }
 
+func (bs *boltStore) ListKeys(prefix string) ([]string, error) {
+	result := make([]string, 0)
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(objectsBucket)
+		if bucket == nil {
+			return fmt.Errorf("bucket not found: %s", objectsBucket)
+		}
+
+		c := bucket.Cursor()
+		prefixBytes := []byte(prefix)
+		for k, _ := c.Seek(prefixBytes); k != nil && bytes.HasPrefix(k, prefixBytes); k, _ = c.Next() {
+			// generations of the same object are stored next to each other
+			key := string(k[:bytes.LastIndex(k, []byte(boltSeparator))])
+			if len(result) == 0 || result[len(result)-1] != key {
+				result = append(result, key)
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
 func (bs *boltStore) ListGenerations(key string) ([]runtime.Storable, error) {
 	return bs.List(key + boltSeparator)
 }
@@ -238,6 +262,52 @@ func (bs *boltStore) save(obj runtime.Storable, updateCurrent bool) (bool, error
 	return updated, err
 }
 
+func (bs *boltStore) SaveIf(obj runtime.Storable, condition func(existing runtime.Storable) bool) (bool, error) {
+	info := bs.registry.Get(obj.GetKind())
+	if info == nil {
+		return false, fmt.Errorf("unknown kind: %s", obj.GetKind())
+	}
+	if info.Versioned {
+		return false, fmt.Errorf("conditional saving of versioned objects isn't supported: %s", obj.GetKind())
+	}
+	boltPath := []byte(runtime.KeyForStorable(obj) + boltSeparator + genStr(runtime.LastGen))
+
+	saved := false
+	err := bs.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(objectsBucket)
+		if bucket == nil {
+			return fmt.Errorf("bucket not found: %s", objectsBucket)
+		}
+
+		var existing runtime.Storable
+		if existingData := bucket.Get(boltPath); existingData != nil {
+			existingObj, err := bs.codec.DecodeOne(existingData)
+			if err != nil {
+				return err
+			}
+			storable, ok := existingObj.(runtime.Storable)
+			if !ok {
+				return fmt.Errorf("storable object is expected to be decoded from bolt, but got: %s", existingObj.GetKind())
+			}
+			existing = storable
+		}
+
+		if !condition(existing) {
+			return nil
+		}
+
+		data, err := bs.codec.EncodeOne(obj)
+		if err != nil {
+			return err
+		}
+
+		saved = true
+		return bucket.Put(boltPath, data)
+	})
+
+	return saved, err
+}
+
 func (bs *boltStore) Delete(key string) error {
 	// todo support deleting version objects, potentially we don't want to remove any object, just mark as deleted
 
@@ -0,0 +1,19 @@
+package generic
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/bolt"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/sql"
+	"strings"
+)
+
+// NewGenericStore creates a new object store based on the DB connection scheme. SQL store is used for connections
+// with scheme (e.g. postgres://... or sqlite3://...), BoltDB store is used if connection is just a path to the file
+func NewGenericStore(registry *runtime.Registry, cfg config.DB) store.Generic {
+	if strings.Contains(cfg.Connection, "://") {
+		return sql.NewGenericStore(registry)
+	}
+	return bolt.NewGenericStore(registry)
+}
@@ -0,0 +1,12 @@
+//go:build cgo
+// +build cgo
+
+package sql
+
+import (
+	// register SQL driver for SQLite, which requires cgo
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSupported is true if Aptomi is built with SQLite support (it requires cgo)
+const sqliteSupported = true
@@ -0,0 +1,7 @@
+//go:build !cgo
+// +build !cgo
+
+package sql
+
+// sqliteSupported is false, as Aptomi is built without cgo, which is required by SQLite driver
+const sqliteSupported = false
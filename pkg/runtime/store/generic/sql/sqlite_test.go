@@ -0,0 +1,217 @@
+//go:build cgo
+// +build cgo
+
+package sql
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func newTestStore(t *testing.T) (store.Generic, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "aptomi-sql-store-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		t.FailNow()
+	}
+
+	registry := runtime.NewRegistry().Append(lang.ServiceObject, resolve.ComponentInstanceObject)
+	s := NewGenericStore(registry)
+	err = s.Open(config.DB{Connection: SchemeSQLite + "://" + filepath.Join(dir, "db.sqlite")})
+	if !assert.NoError(t, err, "SQL store should be opened") {
+		t.FailNow()
+	}
+
+	return s, func() {
+		assert.NoError(t, s.Close(), "SQL store should be closed")
+		os.RemoveAll(dir) // nolint: errcheck
+	}
+}
+
+func newService(name string, labels map[string]string) *lang.Service {
+	return &lang.Service{
+		TypeKind: lang.ServiceObject.GetTypeKind(),
+		Metadata: lang.Metadata{
+			Namespace: "main",
+			Name:      name,
+		},
+		Labels: labels,
+	}
+}
+
+func TestSQLStoreVersioned(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	service := newService("service", map[string]string{"a": "1"})
+	key := runtime.KeyForStorable(service)
+
+	// first save creates first generation
+	updated, err := s.Save(service)
+	assert.NoError(t, err, "Object should be saved")
+	assert.True(t, updated, "New object should be saved")
+	assert.Equal(t, runtime.FirstGen, service.GetGeneration(), "First generation should be set")
+
+	// saving the same object doesn't create new generation
+	updated, err = s.Save(newService("service", map[string]string{"a": "1"}))
+	assert.NoError(t, err, "Object should be saved")
+	assert.False(t, updated, "Same object shouldn't be updated")
+
+	// saving changed object creates new generation
+	changed := newService("service", map[string]string{"a": "2"})
+	changed.SetGeneration(service.GetGeneration())
+	updated, err = s.Save(changed)
+	assert.NoError(t, err, "Object should be saved")
+	assert.True(t, updated, "Changed object should be saved")
+	assert.Equal(t, runtime.Generation(2), changed.GetGeneration(), "Next generation should be set")
+
+	// update changes the current generation in place
+	changed.Labels["b"] = "3"
+	updated, err = s.Update(changed)
+	assert.NoError(t, err, "Object should be updated")
+	assert.False(t, updated, "Update shouldn't create new generation")
+	assert.Equal(t, runtime.Generation(2), changed.GetGeneration(), "Generation shouldn't change on update")
+
+	// get specific and last generations
+	obj, err := s.GetGen(key, runtime.FirstGen)
+	assert.NoError(t, err, "Object should be loaded")
+	assert.Equal(t, map[string]string{"a": "1"}, obj.(*lang.Service).Labels, "First generation should be loaded")
+
+	obj, err = s.GetGen(key, runtime.LastGen)
+	assert.NoError(t, err, "Object should be loaded")
+	assert.Equal(t, runtime.Generation(2), obj.GetGeneration(), "Last generation should be loaded")
+	assert.Equal(t, map[string]string{"a": "2", "b": "3"}, obj.(*lang.Service).Labels, "Updated object should be loaded")
+
+	obj, err = s.GetGen(key, runtime.Generation(3))
+	assert.NoError(t, err, "Non-existing generation should be loaded without errors")
+	assert.Nil(t, obj, "Non-existing generation should not be found")
+
+	// list generations
+	gens, err := s.ListGenerations(key)
+	assert.NoError(t, err, "Generations should be listed")
+	if assert.Len(t, gens, 2, "All generations should be listed") {
+		assert.Equal(t, runtime.FirstGen, gens[0].(runtime.Versioned).GetGeneration(), "Generations should be sorted")
+		assert.Equal(t, runtime.Generation(2), gens[1].(runtime.Versioned).GetGeneration(), "Generations should be sorted")
+	}
+
+	// versioned objects can't be deleted
+	assert.Error(t, s.Delete(key), "Versioned object shouldn't be deleted")
+}
+
+func TestSQLStoreVersionedConcurrent(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	_, err := s.Save(newService("service", map[string]string{"n": "0"}))
+	assert.NoError(t, err, "Object should be saved")
+
+	// concurrent saves of different objects should never overwrite each other
+	count := 10
+	wg := &sync.WaitGroup{}
+	for i := 1; i <= count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			service := newService("service", map[string]string{"n": strconv.Itoa(i)})
+			service.SetGeneration(runtime.FirstGen)
+			updated, errSave := s.Save(service)
+			assert.NoError(t, errSave, "Object should be saved")
+			assert.True(t, updated, "New generation should be created")
+		}(i)
+	}
+	wg.Wait()
+
+	gens, err := s.ListGenerations(runtime.KeyForStorable(newService("service", nil)))
+	assert.NoError(t, err, "Generations should be listed")
+	saved := make(map[string]bool)
+	for _, gen := range gens {
+		saved[gen.(*lang.Service).Labels["n"]] = true
+	}
+	assert.Len(t, saved, count+1, "All saved objects should be stored as separate generations")
+}
+
+func TestSQLStoreNotVersioned(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	key1 := &resolve.ComponentInstanceKey{ClusterName: "cluster", Namespace: "main", ContractName: "c_1"}
+	key2 := &resolve.ComponentInstanceKey{ClusterName: "cluster", Namespace: "main", ContractName: "cx1"}
+	for _, key := range []*resolve.ComponentInstanceKey{key1, key2} {
+		instance := &resolve.ComponentInstance{
+			TypeKind: resolve.ComponentInstanceObject.GetTypeKind(),
+			Metadata: &resolve.ComponentInstanceMetadata{Key: key},
+		}
+		_, err := s.Save(instance)
+		assert.NoError(t, err, "Object should be saved")
+	}
+
+	obj, err := s.Get(resolve.KeyForComponentKey(key1.GetKey()))
+	assert.NoError(t, err, "Object should be loaded")
+	if assert.NotNil(t, obj, "Object should be found") {
+		assert.Equal(t, key1.GetKey(), obj.(*resolve.ComponentInstance).GetKey(), "Correct object should be loaded")
+	}
+
+	// special characters in prefix should be matched as is ('_' shouldn't match 'x')
+	objects, err := s.List(runtime.KeyFromParts(runtime.SystemNS, resolve.ComponentInstanceObject.Kind, "cluster#main#c_"))
+	assert.NoError(t, err, "Objects should be listed")
+	assert.Len(t, objects, 1, "Only objects matching prefix should be listed")
+
+	objects, err = s.List(runtime.KeyFromParts(runtime.SystemNS, resolve.ComponentInstanceObject.Kind, ""))
+	assert.NoError(t, err, "Objects should be listed")
+	assert.Len(t, objects, 2, "All objects should be listed")
+
+	keys, err := s.ListKeys(runtime.KeyFromParts(runtime.SystemNS, resolve.ComponentInstanceObject.Kind, "cluster#main#c_"))
+	assert.NoError(t, err, "Object keys should be listed")
+	assert.Equal(t, []string{resolve.KeyForComponentKey(key1.GetKey())}, keys, "Only keys matching prefix should be listed")
+
+	// delete object
+	assert.NoError(t, s.Delete(resolve.KeyForComponentKey(key1.GetKey())), "Object should be deleted")
+	obj, err = s.Get(resolve.KeyForComponentKey(key1.GetKey()))
+	assert.NoError(t, err, "Deleted object should be loaded without errors")
+	assert.Nil(t, obj, "Deleted object should not be found")
+}
+
+func TestSQLStoreSaveIf(t *testing.T) {
+	s, closeFn := newTestStore(t)
+	defer closeFn()
+
+	newInstance := func(contextName string) *resolve.ComponentInstance {
+		return &resolve.ComponentInstance{
+			TypeKind:       resolve.ComponentInstanceObject.GetTypeKind(),
+			Metadata:       &resolve.ComponentInstanceMetadata{Key: &resolve.ComponentInstanceKey{ClusterName: "cluster", Namespace: "main", ContractName: "c", ContextNameWithKeys: contextName}},
+			DataForPlugins: map[string]string{"value": contextName},
+		}
+	}
+	notExists := func(existing runtime.Storable) bool { return existing == nil }
+
+	// object is saved if it doesn't exist
+	saved, err := s.SaveIf(newInstance("ctx"), notExists)
+	assert.NoError(t, err, "Object should be saved")
+	assert.True(t, saved, "Object should be saved when condition is true")
+
+	// object isn't saved if condition is false
+	saved, err = s.SaveIf(newInstance("ctx"), notExists)
+	assert.NoError(t, err, "Object should be saved without errors")
+	assert.False(t, saved, "Object shouldn't be saved when condition is false")
+
+	// existing object is passed into condition
+	saved, err = s.SaveIf(newInstance("ctx"), func(existing runtime.Storable) bool {
+		return existing != nil && existing.(*resolve.ComponentInstance).DataForPlugins["value"] == "ctx"
+	})
+	assert.NoError(t, err, "Object should be saved")
+	assert.True(t, saved, "Object should be saved when condition is true")
+
+	// versioned objects are not supported
+	_, err = s.SaveIf(newService("service", nil), notExists)
+	assert.Error(t, err, "Versioned object shouldn't be saved conditionally")
+}
@@ -1,33 +1,60 @@
 package server
 
-import "fmt"
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"os"
+	"os/signal"
+	"syscall"
+)
 
 type job struct {
-	name     string
-	errors   chan string
-	f        func()
-	infinite bool
+	name   string
+	errors chan string
+	done   <-chan struct{}
+	f      func() error
 }
 
-func (j *job) complete() {
-	if r := recover(); r != nil {
-		j.errors <- fmt.Sprintf("Background job '%s' failed with error (panic): %s", j.name, r)
-	} else if j.infinite {
-		j.errors <- fmt.Sprintf("Infinite background job '%s' completed without error", j.name)
+// fail reports job failure, unless server is shutting down already (so nobody waits for errors anymore)
+func (j *job) fail(err string) {
+	select {
+	case j.errors <- err:
+	case <-j.done:
+		log.Errorf("%s", err)
 	}
 }
 
 func (j *job) start() {
-	defer j.complete()
-	j.f()
+	defer func() {
+		if r := recover(); r != nil {
+			j.fail(fmt.Sprintf("Background job '%s' failed with error (panic): %s", j.name, r))
+		}
+	}()
+
+	err := j.f()
+	if err != nil {
+		j.fail(fmt.Sprintf("Background job '%s' failed with error: %s", j.name, err))
+	}
 }
 
-func (server *Server) runInBackground(name string, infinite bool, f func()) {
-	p := job{name, server.backgroundErrors, f, infinite}
+// runInBackground runs the given function in background. If it returns error or panics, server gets stopped (see
+// wait), while returning nil means that job completed cleanly (e.g. as server is shutting down)
+func (server *Server) runInBackground(name string, f func() error) {
+	p := &job{name, server.backgroundErrors, server.ctx.Done(), f}
 	go p.start()
 }
 
+// wait blocks until one of the background jobs fails (causing panic) or server receives a termination signal (causing
+// graceful shutdown)
 func (server *Server) wait() {
-	err := <-server.backgroundErrors
-	panic(err)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-server.backgroundErrors:
+		panic(err)
+	case sig := <-signals:
+		log.Infof("Received signal %s, shutting down", sig)
+		server.shutdown()
+	}
 }
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/drift"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/Sirupsen/logrus"
+	"time"
+)
+
+// checkDriftIfNeeded checks drift between actual state and the cloud, if drift checks are enabled and drift check
+// interval has passed since the last check. As it's called by enforcer, drift check interval is effectively rounded
+// up to the enforcer interval
+func (server *Server) checkDriftIfNeeded() {
+	interval := server.cfg.Enforcer.DriftCheckInterval
+	if interval <= 0 || time.Since(server.lastDriftCheck) < interval {
+		return
+	}
+	server.lastDriftCheck = time.Now()
+
+	err := server.checkDrift()
+	if err != nil {
+		log.Errorf("Error while checking drift: %s", err)
+	}
+}
+
+// checkDrift compares component instances in the actual state with what is really running in the cloud and saves
+// the results into the store. If drift correction is enabled, drifted component instances get marked in the actual
+// state, so they get updated by the next enforcement
+func (server *Server) checkDrift() (errResult error) {
+	defer func() {
+		if err := recover(); err != nil {
+			errResult = fmt.Errorf("panic: %s", err)
+		}
+	}()
+
+	policy, _, err := server.store.GetPolicy(runtime.LastGen)
+	if err != nil {
+		return fmt.Errorf("error while getting policy: %s", err)
+	}
+	if policy == nil {
+		return fmt.Errorf("policy does not exist in the store")
+	}
+
+	actualState, err := server.store.GetActualState()
+	if err != nil {
+		return fmt.Errorf("error while getting actual state: %s", err)
+	}
+
+	// drift check gets interrupted same way as revision, once it takes too long or server is shutting down
+	ctx, cancel := server.revisionContext()
+	defer cancel()
+
+	eventLog := event.NewLog("drift-check", true)
+	drifted := drift.Detect(ctx, policy, actualState, server.newPluginRegistry(), eventLog)
+
+	report := &engine.DriftReport{
+		TypeKind:  engine.DriftReportObject.GetTypeKind(),
+		CheckedAt: time.Now(),
+		Drifted:   drifted,
+	}
+
+	if server.cfg.Enforcer.DriftCorrection && len(drifted) > 0 {
+		err = server.markDrifted(drifted)
+		if err != nil {
+			return err
+		}
+		report.Corrected = true
+	}
+
+	// drift report is saved only if current replica is still the leader
+	err = server.leader.Fence()
+	if err != nil {
+		return fmt.Errorf("not saving drift report: %s", err)
+	}
+	err = server.store.SaveDriftReport(report)
+	if err != nil {
+		return err
+	}
+
+	if len(drifted) > 0 {
+		log.Warningf("(drift-check) %d component instances have drifted from the actual state, correction enabled: %t", len(drifted), server.cfg.Enforcer.DriftCorrection)
+	}
+
+	return nil
+}
+
+// markDrifted marks drifted component instances in the actual state, so they get updated by the next enforcement.
+// Drift detection could take a while, so component instances are re-read from the store right before being marked, in
+// order not to overwrite changes made in the meantime. Component instances, which got deleted, are skipped
+func (server *Server) markDrifted(drifted map[string]string) error {
+	actualState, err := server.store.GetActualState()
+	if err != nil {
+		return fmt.Errorf("error while getting actual state: %s", err)
+	}
+
+	updater := server.store.GetActualStateUpdater()
+	for key, reason := range drifted {
+		instance, ok := actualState.ComponentInstanceMap[key]
+		if !ok {
+			continue
+		}
+		instance.Drift = reason
+
+		// component instance is updated only if current replica is still the leader
+		err = server.leader.Fence()
+		if err != nil {
+			return fmt.Errorf("not marking component instance '%s' as drifted: %s", key, err)
+		}
+		err = updater.Save(instance)
+		if err != nil {
+			return fmt.Errorf("error while marking component instance '%s' as drifted: %s", key, err)
+		}
+	}
+
+	return nil
+}
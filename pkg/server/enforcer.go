@@ -1,15 +1,23 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/engine/apply"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
 	"github.com/Aptomi/aptomi/pkg/engine/diff"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/plugin"
 	"github.com/Aptomi/aptomi/pkg/plugin/helm"
+	"github.com/Aptomi/aptomi/pkg/plugin/k8sraw"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util/retry"
 	log "github.com/Sirupsen/logrus"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -17,16 +25,64 @@ func logError(err interface{}) {
 	log.Errorf("Error while enforcing policy: %s", err)
 }
 
+// enforceLoop enforces policy every time it gets changed through any server replica, as well as periodically (as a
+// safety net, e.g. when failed components need to be retried)
 func (server *Server) enforceLoop() error {
+	defer close(server.enforcerStopped)
+	policyChanges := server.store.SubscribePolicyChanges(server.ctx)
 	for {
-		err := server.enforce()
-		if err != nil {
-			logError(err)
+		if server.leader.IsLeader() {
+			// actual state gets rebuilt before enforcement, so adopted component instances don't get created again
+			server.rebuildStateIfRequested()
+
+			// orphans get found and deleted before enforcement as well, while no revision is being applied
+			server.collectGarbageIfRequested()
+
+			// drift gets checked right before enforcement, so corrective actions are applied right away
+			server.checkDriftIfNeeded()
+
+			// readiness reported by API gets refreshed, as component instances could become ready long after changes
+			server.refreshReadinessIfNeeded()
+
+			err := server.enforce()
+			if err != nil {
+				logError(err)
+			}
+		}
+
+		if !server.waitForNextEnforcement(policyChanges) {
+			log.Infof("Policy enforcer stopped")
+			return nil
 		}
-		time.Sleep(server.cfg.Enforcer.Interval)
 	}
 }
 
+// waitForNextEnforcement waits until policy gets changed or enforcer interval expires. Once policy gets changed, it
+// waits a bit more to coalesce a burst of policy changes into a single enforcement. It returns false if enforcer
+// should be stopped, as server is shutting down
+func (server *Server) waitForNextEnforcement(policyChanges <-chan struct{}) bool {
+	select {
+	case <-server.ctx.Done():
+		return false
+	case <-time.After(server.cfg.Enforcer.Interval):
+		return true
+	case <-policyChanges:
+	}
+
+	select {
+	case <-server.ctx.Done():
+		return false
+	case <-time.After(server.cfg.Enforcer.Debounce):
+	}
+
+	// changes made during debounce are going to be enforced now, so pending notification should be dropped
+	select {
+	case <-policyChanges:
+	default:
+	}
+	return true
+}
+
 func (server *Server) enforce() error {
 	server.enforcementIdx++
 
@@ -53,65 +109,160 @@ func (server *Server) enforce() error {
 
 	eventLog := event.NewLog(fmt.Sprintf("enforce-%d-resolve", server.enforcementIdx), true)
 	resolver := resolve.NewPolicyResolver(desiredPolicy, server.externalData, eventLog)
-	desiredState, err := resolver.ResolveAllDependencies()
+	desiredState, err := resolver.ResolveAllDependenciesPartially()
 	if err != nil {
 		// todo save eventlog
 		// todo - when printing maps with large # of entries, the errors are pretty long and hard to understand. should not write maps here
 		return fmt.Errorf("cannot resolve desiredPolicy: %v %v %v", err, desiredState, actualState)
 	}
 
+	// failed dependencies don't stop enforcement for others, but their component instances should be left untouched
+	if dependencyErrors := desiredState.GetDependencyErrors(); len(dependencyErrors) > 0 {
+		log.Warningf("(enforce-%d) %d dependencies failed to resolve and will be skipped", server.enforcementIdx, len(dependencyErrors))
+		desiredState.PreserveFailedDependencies(actualState)
+	}
+
 	// todo think about initial state when there is no revision at all
 	currRevision, err := server.store.GetRevision(runtime.LastGen)
 	if err != nil {
 		return fmt.Errorf("unable to get curr revision: %s", err)
 	}
 
-	stateDiff := diff.NewPolicyResolutionDiff(desiredState, actualState)
-
-	nextRevision, err := server.store.NewRevision(desiredPolicyGen)
-	if err != nil {
-		return fmt.Errorf("unable to get next revision: %s", err)
+	// changes of component instances in scope of active freeze windows get deferred
+	freezeWindows := []*lang.FreezeWindow{}
+	for _, obj := range desiredPolicy.GetObjectsByKind(lang.FreezeWindowObject.Kind) {
+		freezeWindows = append(freezeWindows, obj.(*lang.FreezeWindow))
 	}
+	stateDiff := diff.NewPolicyResolutionDiffWithPolicy(desiredPolicy, desiredState, actualState, freezeWindows)
+	if len(stateDiff.Deferred) > 0 {
+		log.Infof("(enforce-%d) Changes of %d component instances got deferred by freeze windows", server.enforcementIdx, len(stateDiff.Deferred))
+	}
+
+	// revisions deleting too many component instances are blocked, while revisions changing component instances in
+	// certain namespaces require manual approval. Either way they aren't applied until approved by a user
+	errLimits := stateDiff.CheckDeleteLimits(server.deleteLimits())
+	approvalNamespaces := server.approvalNamespaces(stateDiff)
 
-	// policy changed while no actions needed to achieve desired state
-	if len(stateDiff.Actions) <= 0 && currRevision != nil && currRevision.Policy == nextRevision.Policy {
-		log.Infof("(enforce-%d) No changes, policy gen %d", server.enforcementIdx, desiredPolicyGen)
+	var nextRevision *engine.Revision
+	if currRevision != nil && currRevision.Policy == desiredPolicyGen && currRevision.Status == engine.RevisionStatusRejected {
+		log.Infof("(enforce-%d) Revision %d got rejected by %s, waiting for policy to change", server.enforcementIdx, currRevision.GetGeneration(), currRevision.RejectedBy)
 		return nil
-	}
-	log.Infof("(enforce-%d) New revision %d, policy gen %d, %d actions need to be applied", server.enforcementIdx, nextRevision.GetGeneration(), desiredPolicyGen, len(stateDiff.Actions))
+	} else if currRevision != nil && currRevision.Policy == desiredPolicyGen && (currRevision.Status == engine.RevisionStatusBlocked || currRevision.Status == engine.RevisionStatusPendingApproval) {
+		if currRevision.IsWaitingForApproval() && (errLimits != nil || len(approvalNamespaces) > 0) {
+			log.Infof("(enforce-%d) Revision %d is %s, waiting for approval: %s", server.enforcementIdx, currRevision.GetGeneration(), currRevision.Status, currRevision.BlockedReason)
+			return nil
+		}
 
-	// todo save eventlog (if there were changes?)
+		// approval only covers actions planned at the time of approval, so limits and approval namespaces are only
+		// skipped for exactly the same actions. Revision gets blocked again if actions have changed since then and
+		// they exceed delete limits or change component instances in namespaces requiring approval
+		actionNames := getActionNames(stateDiff.Actions)
+		if currRevision.IsApproved() && !sameActions(currRevision.PlannedActions, actionNames) && (errLimits != nil || len(approvalNamespaces) > 0) {
+			blockRevision(currRevision, errLimits, approvalNamespaces)
+			currRevision.PlannedActions = actionNames
+			currRevision.ApprovedBy = ""
+			currRevision.ApprovedAt = time.Time{}
 
-	// todo if policy gen changed, we still need to save revision but with progress == done
+			err = server.leader.Fence()
+			if err != nil {
+				return fmt.Errorf("not updating approved revision: %s", err)
+			}
+			err = server.store.UpdateRevision(currRevision)
+			if err != nil {
+				return fmt.Errorf("error while blocking approved revision: %s", err)
+			}
+			log.Warningf("(enforce-%d) Revision %d is %s again, as actions changed since approval: %s", server.enforcementIdx, currRevision.GetGeneration(), currRevision.Status, currRevision.BlockedReason)
+			return nil
+		}
 
-	// Save revision
-	err = server.store.SaveRevision(nextRevision)
-	if err != nil {
-		return fmt.Errorf("error while saving new revision: %s", err)
+		// revision got approved (or doesn't require approval anymore), so it could be applied now
+		nextRevision = currRevision
+		nextRevision.Status = engine.RevisionStatusInProgress
+		nextRevision.Deferred = stateDiff.Deferred
+		log.Infof("(enforce-%d) Revision %d, policy gen %d, is going to be applied after approval, %d actions need to be applied", server.enforcementIdx, nextRevision.GetGeneration(), desiredPolicyGen, len(stateDiff.Actions))
+
+		err = server.leader.Fence()
+		if err != nil {
+			return fmt.Errorf("not updating approved revision: %s", err)
+		}
+		err = server.store.UpdateRevision(nextRevision)
+		if err != nil {
+			return fmt.Errorf("error while updating approved revision: %s", err)
+		}
+	} else {
+		nextRevision, err = server.store.NewRevision(desiredPolicyGen)
+		if err != nil {
+			return fmt.Errorf("unable to get next revision: %s", err)
+		}
+
+		// policy changed while no actions needed to achieve desired state
+		if len(stateDiff.Actions) <= 0 && currRevision != nil && currRevision.Policy == nextRevision.Policy {
+			log.Infof("(enforce-%d) No changes, policy gen %d", server.enforcementIdx, desiredPolicyGen)
+			return nil
+		}
+
+		nextRevision.Deferred = stateDiff.Deferred
+		blockRevision(nextRevision, errLimits, approvalNamespaces)
+		if nextRevision.Status != engine.RevisionStatusInProgress {
+			nextRevision.PlannedActions = getActionNames(stateDiff.Actions)
+		}
+
+		// todo if policy gen changed, we still need to save revision but with progress == done
+
+		// Save revision (only if current replica is still the leader)
+		err = server.leader.Fence()
+		if err != nil {
+			return fmt.Errorf("not saving new revision: %s", err)
+		}
+		err = server.store.SaveRevision(nextRevision)
+		if err != nil {
+			return fmt.Errorf("error while saving new revision: %s", err)
+		}
+
+		if nextRevision.Status != engine.RevisionStatusInProgress {
+			server.saveRevisionLog(nextRevision.GetGeneration(), eventLog)
+			log.Warningf("(enforce-%d) New revision %d, policy gen %d, is %s until approved: %s", server.enforcementIdx, nextRevision.GetGeneration(), desiredPolicyGen, nextRevision.Status, nextRevision.BlockedReason)
+			return nil
+		}
+		log.Infof("(enforce-%d) New revision %d, policy gen %d, %d actions need to be applied", server.enforcementIdx, nextRevision.GetGeneration(), desiredPolicyGen, len(stateDiff.Actions))
 	}
 
+	// Results of actions get recorded into the revision as they are applied
+	nextRevision.ResetActions(getActionNames(stateDiff.Actions))
+
+	// Save resolve event log for the revision
+	server.saveRevisionLog(nextRevision.GetGeneration(), eventLog)
+
 	// Build plugin registry
-	var pluginRegistry plugin.Registry
 	if server.cfg.Enforcer.Noop {
 		log.Infof("(enforce-%d) Applying changes in noop mode (sleep per action = %d seconds)", server.enforcementIdx, server.cfg.Enforcer.NoopSleep)
-		pluginRegistry = &plugin.MockRegistry{
-			DeployPlugin:      &plugin.MockDeployPlugin{SleepTime: time.Second * time.Duration(server.cfg.Enforcer.NoopSleep)},
-			PostProcessPlugin: &plugin.MockPostProcessPlugin{},
-		}
 	} else {
 		log.Infof("(enforce-%d) Applying changes", server.enforcementIdx)
-		helmIstio := helm.NewPlugin(server.cfg.Helm)
-		pluginRegistry = plugin.NewRegistry(
-			[]plugin.DeployPlugin{helmIstio},
-			[]plugin.PostProcessPlugin{helmIstio},
-		)
 	}
+	pluginRegistry := server.newPluginRegistry()
+
+	// Revision gets interrupted once it takes too long or server is shutting down
+	ctx, cancel := server.revisionContext()
+	defer cancel()
 
 	eventLog = event.NewLog(fmt.Sprintf("enforce-%d-apply", server.enforcementIdx), true)
-	applier := apply.NewEngineApply(desiredPolicy, desiredState, actualState, server.store.GetActualStateUpdater(), server.externalData, pluginRegistry, stateDiff.Actions, eventLog, server.store.GetRevisionProgressUpdater(nextRevision))
-	_, err = applier.Apply()
+	applier := apply.NewEngineApply(desiredPolicy, desiredState, actualState, server.store.GetActualStateUpdater(), server.externalData, pluginRegistry, stateDiff.Actions, eventLog, server.store.GetRevisionProgressUpdater(nextRevision), server.cfg.Enforcer.MaxConcurrentActions, server.retryBackoff(), server.readiness(), server.cfg.Enforcer.ActionTimeout, server.leader.Fence)
+	_, err = applier.Apply(ctx)
+
+	// Save apply event log for the revision
+	server.saveRevisionLog(nextRevision.GetGeneration(), eventLog)
 
-	// todo save eventlog
+	if ctx.Err() != nil {
+		nextRevision.Status = engine.RevisionStatusInterrupted
+		errUpdate := server.leader.Fence()
+		if errUpdate == nil {
+			errUpdate = server.store.UpdateRevision(nextRevision)
+		}
+		if errUpdate != nil {
+			log.Warningf("(enforce-%d) Unable to mark revision %d as interrupted: %s", server.enforcementIdx, nextRevision.GetGeneration(), errUpdate)
+		}
+		return fmt.Errorf("revision %d got interrupted: %s", nextRevision.GetGeneration(), ctx.Err())
+	}
 
 	if err != nil {
 		return fmt.Errorf("error while applying new revision: %s", err)
@@ -120,3 +271,159 @@ func (server *Server) enforce() error {
 
 	return nil
 }
+
+// newPluginRegistry creates registry of plugins, which make changes in the cloud. In noop mode plugins do nothing
+func (server *Server) newPluginRegistry() plugin.Registry {
+	if server.cfg.Enforcer.Noop {
+		return &plugin.MockRegistry{
+			DeployPlugin:      &plugin.MockDeployPlugin{SleepTime: time.Second * time.Duration(server.cfg.Enforcer.NoopSleep)},
+			PostProcessPlugin: &plugin.MockPostProcessPlugin{},
+		}
+	}
+
+	helmIstio := helm.NewPlugin(server.cfg.Helm)
+	k8sRaw := k8sraw.NewPlugin()
+	return plugin.NewRegistry(
+		[]plugin.DeployPlugin{helmIstio, k8sRaw},
+		[]plugin.PostProcessPlugin{helmIstio},
+	)
+}
+
+// getActionNames returns names of the given actions, preserving their order
+func getActionNames(actions []action.Base) []string {
+	result := make([]string, len(actions))
+	for idx, act := range actions {
+		result[idx] = act.GetName()
+	}
+	return result
+}
+
+// blockRevision marks revision as blocked if delete limits got exceeded or as pending approval if it changes component
+// instances in namespaces requiring approval. Revision status isn't changed otherwise
+func blockRevision(revision *engine.Revision, errLimits error, approvalNamespaces []string) {
+	if errLimits != nil {
+		revision.Status = engine.RevisionStatusBlocked
+		revision.BlockedReason = errLimits.Error()
+	} else if len(approvalNamespaces) > 0 {
+		revision.Status = engine.RevisionStatusPendingApproval
+		revision.BlockedReason = fmt.Sprintf("component instances would be changed in namespaces requiring approval: %s", strings.Join(approvalNamespaces, ", "))
+	}
+	if errLimits != nil || len(approvalNamespaces) > 0 {
+		revision.ApprovalNamespaces = approvalNamespaces
+	}
+}
+
+// sameActions returns true if both lists contain the same actions (by their names), regardless of the order
+func sameActions(names1 []string, names2 []string) bool {
+	if len(names1) != len(names2) {
+		return false
+	}
+
+	sorted1 := append([]string{}, names1...)
+	sorted2 := append([]string{}, names2...)
+	sort.Strings(sorted1)
+	sort.Strings(sorted2)
+	for idx := range sorted1 {
+		if sorted1[idx] != sorted2[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// saveRevisionLog persists event log for the given revision and deletes event logs of the revisions, which are out of
+// retention. Errors are only logged, as they shouldn't stop enforcement
+func (server *Server) saveRevisionLog(gen runtime.Generation, eventLog *event.Log) {
+	err := server.store.SaveRevisionLog(gen, eventLog)
+	if err != nil {
+		log.Warningf("(enforce-%d) Unable to save event log for revision %d: %s", server.enforcementIdx, gen, err)
+		return
+	}
+
+	retention := runtime.Generation(server.cfg.Enforcer.LogRetention)
+	if retention > 0 && gen > retention {
+		err = server.store.DeleteRevisionLogs(gen - retention + 1)
+		if err != nil {
+			log.Warningf("(enforce-%d) Unable to delete old revision event logs: %s", server.enforcementIdx, err)
+		}
+	}
+}
+
+// retryBackoff returns backoff for retrying failed component changes, as configured for enforcer
+func (server *Server) retryBackoff() retry.Backoff {
+	return retry.Backoff{
+		Base:        server.cfg.Enforcer.RetryBackoffBase,
+		Max:         server.cfg.Enforcer.RetryBackoffMax,
+		MaxAttempts: server.cfg.Enforcer.RetryMaxAttempts,
+	}
+}
+
+// readiness returns whether and how long to wait for component instances to become ready, as configured for enforcer
+func (server *Server) readiness() action.Readiness {
+	return action.Readiness{
+		Wait:          server.cfg.Enforcer.WaitForReady,
+		Timeout:       server.cfg.Enforcer.ReadyTimeout,
+		CheckInterval: server.cfg.Enforcer.ReadyCheckInterval,
+	}
+}
+
+// deleteLimits returns how many component instances a revision could delete without approval, as configured for enforcer
+func (server *Server) deleteLimits() diff.DeleteLimits {
+	return diff.DeleteLimits{
+		MaxDeleteActions: server.cfg.Enforcer.MaxDeleteActions,
+		MaxDeletePercent: server.cfg.Enforcer.MaxDeletePercent,
+	}
+}
+
+// approvalNamespaces returns namespaces, which require manual approval, where component instances are going to be
+// changed by the given diff
+func (server *Server) approvalNamespaces(stateDiff *diff.PolicyResolutionDiff) []string {
+	required := make(map[string]bool)
+	for _, namespace := range strings.Split(server.cfg.Enforcer.ApprovalNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); len(namespace) > 0 {
+			required[namespace] = true
+		}
+	}
+
+	result := []string{}
+	for _, namespace := range stateDiff.ChangedNamespaces() {
+		if required[namespace] {
+			result = append(result, namespace)
+		}
+	}
+	return result
+}
+
+// resolveStates returns the latest policy along with actual state and desired state it resolves into. Dependencies,
+// which failed to resolve, are skipped
+func (server *Server) resolveStates(eventLog *event.Log) (*lang.Policy, *resolve.PolicyResolution, *resolve.PolicyResolution, error) {
+	desiredPolicy, _, err := server.store.GetPolicy(runtime.LastGen)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error while getting policy: %s", err)
+	}
+	if desiredPolicy == nil {
+		return nil, nil, nil, fmt.Errorf("policy does not exist in the store")
+	}
+
+	actualState, err := server.store.GetActualState()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error while getting actual state: %s", err)
+	}
+
+	resolver := resolve.NewPolicyResolver(desiredPolicy, server.externalData, eventLog)
+	desiredState, err := resolver.ResolveAllDependenciesPartially()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot resolve policy: %s", err)
+	}
+
+	return desiredPolicy, actualState, desiredState, nil
+}
+
+// revisionContext returns context for applying a single revision. It gets cancelled on server shutdown or once
+// configured revision timeout expires
+func (server *Server) revisionContext() (context.Context, context.CancelFunc) {
+	if server.cfg.Enforcer.RevisionTimeout > 0 {
+		return context.WithTimeout(server.ctx, server.cfg.Enforcer.RevisionTimeout)
+	}
+	return context.WithCancel(server.ctx)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/gc"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	log "github.com/Sirupsen/logrus"
+	"time"
+)
+
+// collectGarbageIfRequested finds or deletes orphaned deployments, if it has been requested through the API. It's
+// called by enforcer, so deployments being created by the revision in progress never get reported as orphans
+func (server *Server) collectGarbageIfRequested() {
+	collection, err := server.store.GetGarbageCollection()
+	if err != nil {
+		log.Errorf("Error while getting garbage collection: %s", err)
+		return
+	}
+	if collection == nil || !collection.IsInProgress() {
+		return
+	}
+
+	if collection.Status == engine.GarbageCollectionStatusPlanRequested {
+		log.Infof("(gc) Looking for orphaned deployments, as requested by %s", collection.PlanRequestedBy)
+		collection.Orphans, err = server.findOrphans()
+		collection.PlannedAt = time.Now()
+		if err == nil {
+			log.Infof("(gc) %d orphaned deployments found", len(collection.Orphans))
+			collection.Status = engine.GarbageCollectionStatusPlanned
+		}
+	} else {
+		log.Infof("(gc) Deleting %d orphaned deployments, as confirmed by %s", len(collection.Orphans), collection.RunRequestedBy)
+		collection.Deleted, err = server.deleteOrphans(collection.Orphans)
+		collection.FinishedAt = time.Now()
+		if err == nil {
+			log.Infof("(gc) %d orphaned deployments deleted", len(collection.Deleted))
+			collection.Status = engine.GarbageCollectionStatusDone
+		}
+	}
+
+	if err != nil {
+		log.Errorf("Error while collecting garbage: %s", err)
+		collection.Status = engine.GarbageCollectionStatusError
+		collection.Error = err.Error()
+		collection.FinishedAt = time.Now()
+	}
+
+	err = server.store.SaveGarbageCollection(collection)
+	if err != nil {
+		log.Errorf("Error while saving garbage collection: %s", err)
+	}
+}
+
+// findOrphans resolves the latest policy and finds deployments in the cloud, which aren't referenced by any component
+// instance from actual or desired state
+func (server *Server) findOrphans() ([]*gc.Orphan, error) {
+	var result []*gc.Orphan
+	err := server.runGarbageCollection(func(ctx context.Context, policy *lang.Policy, actualState *resolve.PolicyResolution, desiredState *resolve.PolicyResolution, eventLog *event.Log) error {
+		var errFind error
+		result, errFind = gc.Find(ctx, policy, actualState, desiredState, server.newPluginRegistry(), eventLog)
+		return errFind
+	})
+	return result, err
+}
+
+// deleteOrphans deletes the given orphans, which are still not referenced by any component instance from actual or
+// desired state
+func (server *Server) deleteOrphans(orphans []*gc.Orphan) ([]*gc.Orphan, error) {
+	var result []*gc.Orphan
+	err := server.runGarbageCollection(func(ctx context.Context, policy *lang.Policy, actualState *resolve.PolicyResolution, desiredState *resolve.PolicyResolution, eventLog *event.Log) error {
+		var errDelete error
+		result, errDelete = gc.Delete(ctx, policy, actualState, desiredState, orphans, server.newPluginRegistry(), eventLog)
+		return errDelete
+	})
+	return result, err
+}
+
+// runGarbageCollection runs the given garbage collection step against the latest policy, recovering from panics
+func (server *Server) runGarbageCollection(step func(ctx context.Context, policy *lang.Policy, actualState *resolve.PolicyResolution, desiredState *resolve.PolicyResolution, eventLog *event.Log) error) (errResult error) {
+	defer func() {
+		if err := recover(); err != nil {
+			errResult = fmt.Errorf("panic: %s", err)
+		}
+	}()
+
+	eventLog := event.NewLog("gc", true)
+	policy, actualState, desiredState, err := server.resolveStates(eventLog)
+	if err != nil {
+		return err
+	}
+
+	// garbage collection gets interrupted same way as revision, once it takes too long or server is shutting down
+	ctx, cancel := server.revisionContext()
+	defer cancel()
+
+	return step(ctx, policy, actualState, desiredState, eventLog)
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	log "github.com/Sirupsen/logrus"
+	"os"
+	"sync"
+	"time"
+)
+
+// leaderElector periodically tries to acquire (or renew) the leader lease in the store, so only one of the Aptomi
+// server replicas sharing the same store could be the leader at any given moment of time
+type leaderElector struct {
+	store         store.Lease
+	id            string
+	leaseDuration time.Duration
+
+	lock      sync.Mutex
+	expiresAt time.Time
+	token     uint64 // fencing token of the lease held by current replica
+	released  bool   // true once lease got released on shutdown, so it shouldn't be acquired anymore
+}
+
+// defaultLeaseDuration is used if lease duration isn't specified in config
+const defaultLeaseDuration = 15 * time.Second
+
+func newLeaderElector(store store.Lease, id string, leaseDuration time.Duration) *leaderElector {
+	if leaseDuration <= 0 {
+		leaseDuration = defaultLeaseDuration
+	}
+	return &leaderElector{
+		store:         store,
+		id:            id,
+		leaseDuration: leaseDuration,
+	}
+}
+
+// getServerID returns ID which identifies current Aptomi server replica
+func getServerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// IsLeader returns true if current replica holds the leader lease and it's not expired yet
+func (le *leaderElector) IsLeader() bool {
+	le.lock.Lock()
+	defer le.lock.Unlock()
+
+	return time.Now().Before(le.expiresAt)
+}
+
+// Fence returns error if current replica isn't the leader anymore. Unlike IsLeader, it checks the lease in the store,
+// so changes aren't made by a replica, which lost the lease without noticing (e.g. while a long revision is being
+// applied, lease could expire and be taken over by another replica)
+func (le *leaderElector) Fence() error {
+	le.lock.Lock()
+	expiresAt, token := le.expiresAt, le.token
+	le.lock.Unlock()
+
+	if !time.Now().Before(expiresAt) {
+		return fmt.Errorf("leader lease isn't held by %s", le.id)
+	}
+
+	lease, err := le.store.GetLease(engine.LeaderLeaseName)
+	if err != nil {
+		return fmt.Errorf("error while checking leader lease: %s", err)
+	}
+	if lease == nil || lease.Holder != le.id || lease.Token != token || lease.IsExpired(time.Now()) {
+		return fmt.Errorf("leader lease has been lost by %s", le.id)
+	}
+
+	return nil
+}
+
+// release stops acquiring the leader lease and releases it, so another replica could take over right away. It's
+// called on server shutdown
+func (le *leaderElector) release() {
+	le.lock.Lock()
+	le.released = true
+	le.expiresAt = time.Time{}
+	le.lock.Unlock()
+
+	err := le.store.ReleaseLease(engine.LeaderLeaseName, le.id)
+	if err != nil {
+		log.Warningf("Error while releasing leader lease: %s", err)
+	}
+}
+
+// run is trying to acquire or renew the leader lease several times per lease duration, so the lease gets renewed
+// long before it expires. It stops once the lease gets released on shutdown
+func (le *leaderElector) run() error {
+	for !le.isReleased() {
+		le.tryAcquire()
+		time.Sleep(le.leaseDuration / 3)
+	}
+	return nil
+}
+
+func (le *leaderElector) isReleased() bool {
+	le.lock.Lock()
+	defer le.lock.Unlock()
+	return le.released
+}
+
+func (le *leaderElector) tryAcquire() {
+	wasLeader := le.IsLeader()
+
+	// calculate expiration time before making a request, so it's never later than one stored in the lease
+	expiresAt := time.Now().Add(le.leaseDuration)
+	lease, acquired, err := le.store.AcquireLease(engine.LeaderLeaseName, le.id, le.leaseDuration)
+	if err != nil {
+		// keep current state, if we were the leader, we'll stop being the leader once the lease expires
+		log.Errorf("Error while acquiring leader lease: %s", err)
+		return
+	}
+
+	le.lock.Lock()
+	if le.released {
+		le.lock.Unlock()
+		return
+	}
+	if acquired {
+		le.expiresAt = expiresAt
+		le.token = lease.Token
+	} else {
+		le.expiresAt = time.Time{}
+	}
+	le.lock.Unlock()
+
+	if acquired && !wasLeader {
+		log.Infof("Leader lease acquired by %s, starting policy enforcement", le.id)
+	} else if !acquired && wasLeader {
+		log.Warningf("Leader lease lost (current leader: %s), stopping policy enforcement", lease.Holder)
+	} else if !acquired && lease != nil {
+		log.Debugf("Leader lease is held by %s until %s", lease.Holder, lease.ExpiresAt)
+	}
+}
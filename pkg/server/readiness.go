@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/readiness"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/Sirupsen/logrus"
+	"time"
+)
+
+// refreshReadinessIfNeeded checks readiness of component instances in the cloud and records it into the actual state,
+// so readiness reported by API doesn't stay the same as it was right after component instances got created or updated.
+// It's called by enforcer while no revision is being applied, but readiness only gets refreshed if refreshes are
+// enabled and readiness refresh interval has passed since the last refresh, as it queries every component in the cloud
+func (server *Server) refreshReadinessIfNeeded() {
+	interval := server.cfg.Enforcer.ReadinessInterval
+	if interval <= 0 || time.Since(server.lastReadinessRefresh) < interval {
+		return
+	}
+	server.lastReadinessRefresh = time.Now()
+
+	err := server.refreshReadinessOnce()
+	if err != nil {
+		log.Errorf("Error while refreshing readiness: %s", err)
+	}
+}
+
+func (server *Server) refreshReadinessOnce() (errResult error) {
+	defer func() {
+		if err := recover(); err != nil {
+			errResult = fmt.Errorf("panic: %s", err)
+		}
+	}()
+
+	policy, _, err := server.store.GetPolicy(runtime.LastGen)
+	if err != nil {
+		return fmt.Errorf("error while getting policy: %s", err)
+	}
+	if policy == nil {
+		return fmt.Errorf("policy does not exist in the store")
+	}
+
+	actualState, err := server.store.GetActualState()
+	if err != nil {
+		return fmt.Errorf("error while getting actual state: %s", err)
+	}
+
+	// readiness check gets interrupted same way as revision, once it takes too long or server is shutting down
+	ctx, cancel := server.revisionContext()
+	defer cancel()
+
+	eventLog := event.NewLog("readiness-refresh", true)
+	changed, err := readiness.Refresh(ctx, policy, actualState, server.store.GetActualStateUpdater(), server.newPluginRegistry(), eventLog)
+	if err != nil {
+		return err
+	}
+
+	if len(changed) > 0 {
+		log.Infof("(readiness-refresh) Readiness of %d component instances has changed", len(changed))
+	}
+
+	return nil
+}
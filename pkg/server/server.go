@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/api"
 	"github.com/Aptomi/aptomi/pkg/api/middleware"
@@ -11,7 +12,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/runtime/store"
 	"github.com/Aptomi/aptomi/pkg/runtime/store/core"
-	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/bolt"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic"
 	"github.com/Aptomi/aptomi/pkg/server/ui"
 	log "github.com/Sirupsen/logrus"
 	"github.com/gorilla/handlers"
@@ -33,6 +34,23 @@ type Server struct {
 	httpServer *http.Server
 
 	enforcementIdx uint
+
+	// id identifies this server replica, leader elector makes sure only one replica enforces policy
+	id     string
+	leader *leaderElector
+
+	// ctx gets cancelled on server shutdown, interrupting revision in progress
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// enforcerStopped gets closed once enforcer stops after server shutdown
+	enforcerStopped chan struct{}
+
+	// lastDriftCheck is when drift between actual state and the cloud was checked last time
+	lastDriftCheck time.Time
+
+	// lastReadinessRefresh is when readiness of component instances was refreshed last time
+	lastReadinessRefresh time.Time
 }
 
 // NewServer creates a new Aptomi Server
@@ -40,7 +58,9 @@ func NewServer(cfg *config.Server) *Server {
 	s := &Server{
 		cfg:              cfg,
 		backgroundErrors: make(chan string),
+		id:               getServerID(),
 	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 
 	return s
 }
@@ -58,10 +78,36 @@ func (server *Server) Start() {
 	server.startHTTPServer()
 	server.startEnforcer()
 
-	// Wait for jobs to complete (it essentially hangs forever)
+	// Wait for jobs to complete (it essentially hangs forever, until server gets shut down)
 	server.wait()
 }
 
+// shutdown gracefully stops Aptomi server. It interrupts the revision in progress (waiting for enforcer to stop, but
+// not longer than configured shutdown timeout) and then stops HTTP server
+func (server *Server) shutdown() {
+	server.cancel()
+
+	if server.enforcerStopped != nil {
+		select {
+		case <-server.enforcerStopped:
+		case <-time.After(server.cfg.Enforcer.ShutdownTimeout):
+			log.Warningf("Enforcer hasn't stopped within %s, shutting down anyway", server.cfg.Enforcer.ShutdownTimeout)
+		}
+	}
+
+	// release leader lease, so another replica could take over enforcement without waiting for it to expire
+	if server.leader != nil {
+		server.leader.release()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := server.httpServer.Shutdown(ctx)
+	if err != nil {
+		log.Warningf("Error while shutting down HTTP server: %s", err)
+	}
+}
+
 func (server *Server) initPolicyOnFirstRun() {
 	policy, _, err := server.store.GetPolicy(runtime.LastGen)
 	if err != nil {
@@ -86,15 +132,19 @@ func (server *Server) initExternalData() {
 	for _, file := range server.cfg.Users.File {
 		userLoaders = append(userLoaders, users.NewUserLoaderFromFile(file, server.cfg.DomainAdminOverrides))
 	}
+	secretLoader, err := secrets.NewSecretLoader(server.cfg.Secrets)
+	if err != nil {
+		panic(fmt.Sprintf("Can't initialize secret loader: %s", err))
+	}
 	server.externalData = external.NewData(
 		users.NewUserLoaderMultipleSources(userLoaders),
-		secrets.NewSecretLoaderFromDir(server.cfg.SecretsDir),
+		secretLoader,
 	)
 }
 
 func (server *Server) initStore() {
 	registry := runtime.NewRegistry().Append(store.Objects...)
-	b := bolt.NewGenericStore(registry)
+	b := generic.NewGenericStore(registry, server.cfg.DB)
 	err := b.Open(server.cfg.DB)
 	if err != nil {
 		panic(fmt.Sprintf("Can't open object store: %s", err))
@@ -105,14 +155,15 @@ func (server *Server) initStore() {
 func (server *Server) startHTTPServer() {
 	router := httprouter.New()
 
-	api.Serve(router, server.store, server.externalData)
+	api.Serve(router, server.store, server.externalData, server.cfg.Auth)
 	server.serveUI(router)
 
 	var handler http.Handler = router
 
 	// todo write to logrus
 	handler = handlers.CombinedLoggingHandler(os.Stdout, handler) // todo(slukjanov): make it at least somehow configurable - for example, select file to write to with rotation
-	handler = cors.Default().Handler(handler)
+	handler = middleware.NewAuthHandler(handler, server.cfg.Auth.Secret)
+	handler = cors.New(cors.Options{AllowedHeaders: []string{"Authorization", "Content-Type"}}).Handler(handler)
 	handler = middleware.NewPanicHandler(handler)
 	// todo(slukjanov): add configurable handlers.ProxyHeaders to f behind the nginx or any other proxy
 	// todo(slukjanov): add compression handler and compress by default in client
@@ -125,8 +176,12 @@ func (server *Server) startHTTPServer() {
 	}
 
 	// Start HTTP server
-	server.runInBackground("HTTP Server / API", true, func() {
-		panic(server.httpServer.ListenAndServe())
+	server.runInBackground("HTTP Server / API", func() error {
+		err := server.httpServer.ListenAndServe()
+		if err != http.ErrServerClosed {
+			return err
+		}
+		return nil
 	})
 }
 
@@ -155,10 +210,11 @@ func (server *Server) serveUI(router *httprouter.Router) {
 }
 
 func (server *Server) startEnforcer() {
-	// Start policy enforcement job
+	// Start leader election and policy enforcement job (only the leader replica enforces policy)
 	if !server.cfg.Enforcer.Disabled {
-		server.runInBackground("Policy Enforcer", true, func() {
-			panic(server.enforceLoop())
-		})
+		server.enforcerStopped = make(chan struct{})
+		server.leader = newLeaderElector(server.store, server.id, server.cfg.Enforcer.LeaseDuration)
+		server.runInBackground("Leader Election", server.leader.run)
+		server.runInBackground("Policy Enforcer", server.enforceLoop)
 	}
 }
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/engine/adopt"
+	"github.com/Aptomi/aptomi/pkg/event"
+	log "github.com/Sirupsen/logrus"
+	"time"
+)
+
+// RebuildState rebuilds actual state from what is really running in the cloud without starting the server, e.g.
+// after the store got lost. Component instances found in the cloud get adopted into the actual state, so they don't
+// get created again once the server is started. It returns keys of adopted component instances. It fails if the
+// leader lease is held by a running server replica, so actual state doesn't get changed while policy is being enforced
+func (server *Server) RebuildState() ([]string, error) {
+	server.initStore()
+	server.initExternalData()
+
+	server.leader = newLeaderElector(server.store, server.id, server.cfg.Enforcer.LeaseDuration)
+	server.leader.tryAcquire()
+	if !server.leader.IsLeader() {
+		lease, err := server.store.GetLease(engine.LeaderLeaseName)
+		if err != nil {
+			return nil, fmt.Errorf("error while checking leader lease: %s", err)
+		}
+		if lease != nil {
+			return nil, fmt.Errorf("leader lease is held by %s until %s, stop Aptomi server or request state rebuild through the API", lease.Holder, lease.ExpiresAt)
+		}
+		return nil, fmt.Errorf("leader lease can't be acquired by %s", server.id)
+	}
+
+	// keep renewing the lease while state is being rebuilt, so no server replica takes over enforcement meanwhile
+	go server.leader.run() // nolint: errcheck
+	defer server.leader.release()
+
+	return server.rebuildState()
+}
+
+// rebuildStateIfRequested rebuilds actual state, if it has been requested through the API. It's called by enforcer,
+// so state doesn't get changed while revision is being applied
+func (server *Server) rebuildStateIfRequested() {
+	rebuild, err := server.store.GetStateRebuild()
+	if err != nil {
+		log.Errorf("Error while getting state rebuild: %s", err)
+		return
+	}
+	if rebuild == nil || !rebuild.IsRequested() {
+		return
+	}
+
+	log.Infof("(state-rebuild) Rebuilding actual state, as requested by %s", rebuild.RequestedBy)
+	if rebuild.Reset {
+		err = server.resetActualState()
+	}
+	if err == nil {
+		rebuild.Adopted, err = server.rebuildState()
+	}
+	rebuild.FinishedAt = time.Now()
+	if err != nil {
+		log.Errorf("Error while rebuilding actual state: %s", err)
+		rebuild.Status = engine.StateRebuildStatusError
+		rebuild.Error = err.Error()
+	} else {
+		log.Infof("(state-rebuild) Actual state rebuilt, %d component instances adopted", len(rebuild.Adopted))
+		rebuild.Status = engine.StateRebuildStatusSuccess
+	}
+
+	err = server.store.SaveStateRebuild(rebuild)
+	if err != nil {
+		log.Errorf("Error while saving state rebuild: %s", err)
+	}
+}
+
+// resetActualState resets actual state right before it gets rebuilt (only if current replica is still the leader)
+func (server *Server) resetActualState() error {
+	err := server.leader.Fence()
+	if err != nil {
+		return fmt.Errorf("not resetting actual state: %s", err)
+	}
+
+	log.Infof("(state-rebuild) Resetting actual state")
+	return server.store.ResetActualState()
+}
+
+// rebuildState resolves the latest policy and looks up component instances, which are missing in the actual state,
+// in the cloud. The ones found in the cloud get adopted into the actual state
+func (server *Server) rebuildState() (adopted []string, errResult error) {
+	defer func() {
+		if err := recover(); err != nil {
+			errResult = fmt.Errorf("panic: %s", err)
+		}
+	}()
+
+	eventLog := event.NewLog("state-rebuild", true)
+	desiredPolicy, actualState, desiredState, err := server.resolveStates(eventLog)
+	if err != nil {
+		return nil, err
+	}
+
+	// state rebuild gets interrupted same way as revision, once it takes too long or server is shutting down
+	ctx, cancel := server.revisionContext()
+	defer cancel()
+
+	return adopt.Adopt(ctx, desiredPolicy, desiredState, actualState, server.store.GetActualStateUpdater(), server.newPluginRegistry(), eventLog)
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// RunWithContext runs the given function and waits for it to complete, unless the given context gets done first. In
+// that case context error is returned right away, while the function keeps running in background until it completes
+// (so it could be used to stop waiting for operations which don't support cancellation, e.g. hung network calls).
+// Panic in the function is converted into an error
+func RunWithContext(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return wait(ctx, start(f, func() {}))
+}
+
+// exclusive contains keys of functions started by RunExclusiveWithContext, which are still running
+var exclusive = struct {
+	sync.Mutex
+	running map[string]bool
+}{running: make(map[string]bool)}
+
+// RunExclusiveWithContext is the same as RunWithContext, but it also makes sure that only one function with the given
+// key is running at a time. If function with the same key has been abandoned (i.e. context got done before it
+// completed) and it's still running in background, error is returned right away without running the given function.
+// It should be used for operations, which shouldn't overlap with abandoned ones (e.g. changing the same deployment
+// in the cloud), so they get retried later instead
+func RunExclusiveWithContext(ctx context.Context, key string, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	exclusive.Lock()
+	if exclusive.running[key] {
+		exclusive.Unlock()
+		return fmt.Errorf("previous operation on '%s' is still running", key)
+	}
+	exclusive.running[key] = true
+	exclusive.Unlock()
+
+	return wait(ctx, start(f, func() {
+		exclusive.Lock()
+		delete(exclusive.running, key)
+		exclusive.Unlock()
+	}))
+}
+
+// start runs the given function in background and returns channel to receive its result from. Function completion
+// gets reported by calling completed before the result gets sent
+func start(f func() error, completed func()) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				completed()
+				done <- fmt.Errorf("panic: %s", r)
+			}
+		}()
+		err := f()
+		completed()
+		done <- err
+	}()
+	return done
+}
+
+// wait waits for the result of function started in background, unless the given context gets done first
+func wait(ctx context.Context, done <-chan error) error {
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRunExclusiveWithContext(t *testing.T) {
+	// function gets abandoned once context is done, but keeps running in background
+	release := make(chan struct{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := RunExclusiveWithContext(ctx, "key", func() error {
+		<-release
+		return nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err, "Abandoned function should result in context error")
+
+	// function with the same key can't be run while the abandoned one is still running, while other keys can
+	called := false
+	err = RunExclusiveWithContext(context.Background(), "key", func() error {
+		called = true
+		return nil
+	})
+	assert.Error(t, err, "Function should not be run while the abandoned one is still running")
+	assert.False(t, called, "Function should not be called while the abandoned one is still running")
+	assert.NoError(t, RunExclusiveWithContext(context.Background(), "another-key", func() error { return nil }), "Function with another key should be run")
+
+	// once abandoned function completes, function with the same key could be run again
+	close(release)
+	for i := 0; i < 100; i++ {
+		err = RunExclusiveWithContext(context.Background(), "key", func() error { return nil })
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(t, err, "Function should be run once the abandoned one completed")
+
+	// panics are converted into errors and don't keep the key locked
+	err = RunExclusiveWithContext(context.Background(), "key", func() error { panic("boom") })
+	if assert.Error(t, err, "Panic should be converted into error") {
+		assert.Contains(t, err.Error(), "boom", "Panic should be reported")
+	}
+	assert.NoError(t, RunExclusiveWithContext(context.Background(), "key", func() error { return nil }), "Key should be released after panic")
+}
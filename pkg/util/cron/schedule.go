@@ -0,0 +1,148 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field defines range of values for a single field of the cron expression
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+// fields of the standard cron expression in their order: minute, hour, day of month, month and day of week (0 is Sunday)
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Schedule is a parsed cron expression in the standard 5-field format ("minute hour day-of-month month day-of-week").
+// Every field supports '*', single values, ranges ("1-5"), lists ("1,3,5") and steps ("*/15", "0-30/10").
+//
+// Same as in vixie cron, if both day of month and day of week are restricted (i.e. don't start with '*'), schedule
+// fires when either of them matches. Schedule is evaluated in the location of the given time, so it's up to the
+// caller to convert time into the right time zone.
+type Schedule struct {
+	values [][]bool
+
+	// anyDay is true if either day of month or day of week isn't restricted, so both of them should match
+	anyDay bool
+}
+
+// Parse parses cron expression into Schedule
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression '%s' should have %d fields, but found %d", expr, len(fields), len(parts))
+	}
+
+	result := &Schedule{values: make([][]bool, len(fields))}
+	for idx, f := range fields {
+		values, err := parseField(parts[idx], f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in cron expression '%s': %s", f.name, expr, err)
+		}
+		result.values[idx] = values
+	}
+	result.anyDay = strings.HasPrefix(parts[2], "*") || strings.HasPrefix(parts[4], "*")
+
+	return result, nil
+}
+
+func parseField(expr string, f field) ([]bool, error) {
+	result := make([]bool, f.max+1)
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			var err error
+			rangeExpr = item[:idx]
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step '%s'", item[idx+1:])
+			}
+		}
+
+		from, to := f.min, f.max
+		if rangeExpr != "*" {
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			from, err = parseValue(bounds[0], f)
+			if err != nil {
+				return nil, err
+			}
+			to = from
+			if len(bounds) > 1 {
+				to, err = parseValue(bounds[1], f)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if from > to {
+				return nil, fmt.Errorf("invalid range '%s'", rangeExpr)
+			}
+		}
+
+		for value := from; value <= to; value += step {
+			result[value] = true
+		}
+	}
+	return result, nil
+}
+
+func parseValue(expr string, f field) (int, error) {
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("value '%s' should be within [%d, %d]", expr, f.min, f.max)
+	}
+	return value, nil
+}
+
+// Matches returns true if schedule fires at the given time (with a minute precision)
+func (schedule *Schedule) Matches(t time.Time) bool {
+	return schedule.values[0][t.Minute()] && schedule.values[1][t.Hour()] && schedule.matchesDay(t)
+}
+
+// matchesDay returns true if schedule fires on the day of the given time
+func (schedule *Schedule) matchesDay(t time.Time) bool {
+	if !schedule.values[3][int(t.Month())] {
+		return false
+	}
+
+	dayOfMonth := schedule.values[2][t.Day()]
+	dayOfWeek := schedule.values[4][int(t.Weekday())]
+	if schedule.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// LastBefore returns the latest time within (t - lookback, t], when schedule fires. It returns false if schedule
+// doesn't fire within that period. Days and hours, when schedule doesn't fire, are skipped as a whole, so long
+// lookback periods are cheap to check
+func (schedule *Schedule) LastBefore(t time.Time, lookback time.Duration) (time.Time, bool) {
+	earliest := t.Add(-lookback)
+	fire := t.Truncate(time.Minute)
+	for fire.After(earliest) {
+		year, month, day := fire.Date()
+		switch {
+		case !schedule.matchesDay(fire):
+			// go to the last minute of the previous day
+			fire = time.Date(year, month, day, 0, 0, 0, 0, fire.Location()).Add(-time.Minute)
+		case !schedule.values[1][fire.Hour()]:
+			// go to the last minute of the previous hour
+			fire = time.Date(year, month, day, fire.Hour(), 0, 0, 0, fire.Location()).Add(-time.Minute)
+		case !schedule.values[0][fire.Minute()]:
+			fire = fire.Add(-time.Minute)
+		default:
+			return fire, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	valid := []string{"* * * * *", "0 9 * * 1-5", "*/15 0-6,22-23 1 1,6,12 0", "0-30/10 * * * *"}
+	for _, expr := range valid {
+		_, err := Parse(expr)
+		assert.NoError(t, err, "Cron expression '%s' should be valid", expr)
+	}
+
+	invalid := []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 7", "5-1 * * * *", "*/0 * * * *", "a * * * *"}
+	for _, expr := range invalid {
+		_, err := Parse(expr)
+		assert.Error(t, err, "Cron expression '%s' should be invalid", expr)
+	}
+}
+
+func TestScheduleMatches(t *testing.T) {
+	// every weekday at 9:00
+	schedule, err := Parse("0 9 * * 1-5")
+	if !assert.NoError(t, err, "Cron expression should be valid") {
+		t.FailNow()
+	}
+
+	monday := time.Date(2018, time.January, 1, 9, 0, 0, 0, time.UTC)
+	assert.True(t, schedule.Matches(monday), "Schedule should fire on Monday at 9:00")
+	assert.True(t, schedule.Matches(monday.Add(30*time.Second)), "Schedule should match with a minute precision")
+	assert.False(t, schedule.Matches(monday.Add(time.Minute)), "Schedule should not fire at 9:01")
+	assert.False(t, schedule.Matches(monday.AddDate(0, 0, 6)), "Schedule should not fire on Sunday")
+
+	fire, found := schedule.LastBefore(monday.Add(2*time.Hour), 3*time.Hour)
+	assert.True(t, found, "Schedule should fire within lookback period")
+	assert.Equal(t, monday, fire, "Schedule should fire on Monday at 9:00")
+
+	_, found = schedule.LastBefore(monday.Add(2*time.Hour), time.Hour)
+	assert.False(t, found, "Schedule should not fire within lookback period")
+}
+
+func TestScheduleMatchesDayOfMonthOrWeek(t *testing.T) {
+	// on the 1st day of month and every Friday at midnight (either of them should match, same as in vixie cron)
+	schedule, err := Parse("0 0 1 * 5")
+	if !assert.NoError(t, err, "Cron expression should be valid") {
+		t.FailNow()
+	}
+
+	assert.True(t, schedule.Matches(time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)), "Schedule should fire on the 1st day of month (Monday)")
+	assert.True(t, schedule.Matches(time.Date(2018, time.January, 5, 0, 0, 0, 0, time.UTC)), "Schedule should fire on Friday")
+	assert.False(t, schedule.Matches(time.Date(2018, time.January, 2, 0, 0, 0, 0, time.UTC)), "Schedule should not fire on Tuesday")
+
+	// last Friday or 1st day of month is found within a long lookback period (2018-03-16 is Friday)
+	fire, found := schedule.LastBefore(time.Date(2018, time.March, 20, 12, 0, 0, 0, time.UTC), 90*24*time.Hour)
+	assert.True(t, found, "Schedule should fire within lookback period")
+	assert.Equal(t, time.Date(2018, time.March, 16, 0, 0, 0, 0, time.UTC), fire, "Schedule should fire on the last Friday")
+	fire, found = schedule.LastBefore(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC), 90*24*time.Hour)
+	assert.True(t, found, "Schedule should fire within lookback period")
+	assert.Equal(t, time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC), fire, "Schedule should fire on the 1st day of month")
+
+	// if only one of them is restricted, both should match
+	schedule, err = Parse("0 0 1-7 * *")
+	if !assert.NoError(t, err, "Cron expression should be valid") {
+		t.FailNow()
+	}
+	assert.True(t, schedule.Matches(time.Date(2018, time.January, 2, 0, 0, 0, 0, time.UTC)), "Schedule should fire within the first week")
+	assert.False(t, schedule.Matches(time.Date(2018, time.January, 8, 0, 0, 0, 0, time.UTC)), "Schedule should not fire after the first week")
+}
+
+func TestScheduleLocation(t *testing.T) {
+	// schedule is evaluated in the location of the given time
+	schedule, err := Parse("0 9 * * *")
+	if !assert.NoError(t, err, "Cron expression should be valid") {
+		t.FailNow()
+	}
+
+	location := time.FixedZone("UTC+5:30", 5*3600+30*60)
+	now := time.Date(2018, time.January, 1, 10, 0, 0, 0, location)
+	fire, found := schedule.LastBefore(now, 2*time.Hour)
+	assert.True(t, found, "Schedule should fire within lookback period")
+	assert.Equal(t, time.Date(2018, time.January, 1, 9, 0, 0, 0, location), fire, "Schedule should fire at 9:00 in the given location")
+
+	_, found = schedule.LastBefore(now.UTC(), 2*time.Hour)
+	assert.False(t, found, "Schedule should not fire at 9:00 UTC")
+}
@@ -1,6 +1,11 @@
 package util
 
-import "hash/fnv"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"gopkg.in/yaml.v2"
+	"hash/fnv"
+)
 
 // HashFnv calculates 32-bit fnv.New32a hash, given a string s
 func HashFnv(s string) uint32 {
@@ -11,3 +16,15 @@ func HashFnv(s string) uint32 {
 	}
 	return hash.Sum32()
 }
+
+// HashObject calculates hex-encoded sha256 hash of the given object serialized into YAML. YAML serializes maps with
+// sorted keys, so the same object always results in the same hash. It could be used to check whether object changed
+// without storing the object itself (e.g. params which may contain user secrets)
+func HashObject(obj interface{}) string {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		panic("Internal error. Can't serialize object to calculate hash: " + err.Error())
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
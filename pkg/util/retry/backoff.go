@@ -0,0 +1,35 @@
+package retry
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff defines exponential backoff for retrying failed operations. Delay before the next attempt doubles after
+// every failed attempt, starting from Base and capped by Max. If MaxAttempts is set, operation shouldn't be retried
+// once it failed that many times
+type Backoff struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// Delay returns delay before the next attempt, given the number of failed attempts so far
+func (backoff Backoff) Delay(attempts int) time.Duration {
+	delay := backoff.Base
+	for i := 1; i < attempts && delay < math.MaxInt64/2; i++ {
+		delay *= 2
+		if backoff.Max > 0 && delay >= backoff.Max {
+			break
+		}
+	}
+	if backoff.Max > 0 && delay > backoff.Max {
+		delay = backoff.Max
+	}
+	return delay
+}
+
+// Exhausted returns true if operation shouldn't be retried anymore, given the number of failed attempts so far
+func (backoff Backoff) Exhausted(attempts int) bool {
+	return backoff.MaxAttempts > 0 && attempts >= backoff.MaxAttempts
+}
@@ -0,0 +1,21 @@
+package retry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{Base: time.Second, Max: 10 * time.Second, MaxAttempts: 5}
+
+	assert.Equal(t, time.Second, backoff.Delay(1), "First retry should happen after base delay")
+	assert.Equal(t, 2*time.Second, backoff.Delay(2), "Delay should double after every attempt")
+	assert.Equal(t, 8*time.Second, backoff.Delay(4), "Delay should double after every attempt")
+	assert.Equal(t, 10*time.Second, backoff.Delay(5), "Delay should be capped by max")
+	assert.Equal(t, 10*time.Second, backoff.Delay(100), "Delay should be capped by max without overflow")
+
+	assert.False(t, backoff.Exhausted(4), "Backoff shouldn't be exhausted before max attempts")
+	assert.True(t, backoff.Exhausted(5), "Backoff should be exhausted after max attempts")
+	assert.False(t, Backoff{Base: time.Second}.Exhausted(100), "Backoff without max attempts should never be exhausted")
+}
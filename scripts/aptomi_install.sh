@@ -193,8 +193,11 @@ api:
 db:
   connection: ${APTOMI_DB_DIR}/db.bolt
 
+auth:
+  secret: $(head -c 32 /dev/urandom | base64 | tr -dc 'a-zA-Z0-9')
+
 enforcer:
-  interval: 5s
+  interval: 30s
 
 users:
   file:
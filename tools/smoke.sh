@@ -53,6 +53,9 @@ api:
 db:
   connection: ${CONF_DIR}/db.bolt
 
+auth:
+  secret: smoke-test-secret
+
 enforcer:
   noop: true
   interval: 0.1s
@@ -78,7 +81,8 @@ users:
         short-description: role
         deactivated: deactivated
 
-secretsDir: ${CONF_DIR}
+secrets:
+  dir: ${CONF_DIR}
 EOL
 
 aptomi server --config ${CONF_DIR} &>${CONF_DIR}/server.log &
@@ -94,7 +98,7 @@ if [ -z "$SERVER_RUNNING" ]; then
     exit 1
 fi
 
-if aptomictl --config ${CONF_DIR} policy --username Alice apply -f ${POLICY_DIR}/policy &>/dev/null ; then
+if aptomictl --config ${CONF_DIR} policy --username Alice --password alice apply -f ${POLICY_DIR}/policy &>/dev/null ; then
     echo "Alice shouldn't be able to upload full policy"
     exit 1
 fi
@@ -103,7 +107,7 @@ function check_policy() {
     expected="$1"
     query="$2"
 
-    actual="$(aptomictl --config ${CONF_DIR} policy show --username Sam -o json | jq "$2")"
+    actual="$(aptomictl --config ${CONF_DIR} policy show --username Sam --password sam -o json | jq "$2")"
 
     if [ "$actual" -eq "$expected" ]; then
         echo "Found value is equal to expected $actual for query $query"
@@ -122,43 +126,43 @@ WAIT_FLAGS="--wait --wait-interval 0.1s --wait-attempts 20"
 
 # apply full policy (w/o Carol)
 check_policy_version 1
-aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Sam -f ${POLICY_DIR}/policy/Sam
+aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Sam --password sam -f ${POLICY_DIR}/policy/Sam
 check_policy_version 2
-aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Frank -f ${POLICY_DIR}/policy/Frank
+aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Frank --password frank -f ${POLICY_DIR}/policy/Frank
 check_policy_version 3
-aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username John -f ${POLICY_DIR}/policy/John
+aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username John --password john -f ${POLICY_DIR}/policy/John
 check_policy_version 4
-aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username John -f ${POLICY_DIR}/policy/john-prod-ts.yaml
+aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username John --password john -f ${POLICY_DIR}/policy/john-prod-ts.yaml
 check_policy_version 5
-aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Alice -f ${POLICY_DIR}/policy/alice-stage-ts.yaml
+aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Alice --password alice -f ${POLICY_DIR}/policy/alice-stage-ts.yaml
 check_policy_version 6
-aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Bob -f ${POLICY_DIR}/policy/bob-stage-ts.yaml
+aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Bob --password bob -f ${POLICY_DIR}/policy/bob-stage-ts.yaml
 check_policy_version 7
 
 check_policy 3 ".Objects.main.dependency | length"
 
 # delete Alice's dependency
-aptomictl --config ${CONF_DIR} policy delete ${WAIT_FLAGS} --username Alice -f ${POLICY_DIR}/policy/alice-stage-ts.yaml
+aptomictl --config ${CONF_DIR} policy delete ${WAIT_FLAGS} --username Alice --password alice -f ${POLICY_DIR}/policy/alice-stage-ts.yaml
 check_policy_version 8
 check_policy 2 ".Objects.main.dependency | length"
 
 # upgrade prod dependency
 sed -e 's/demo11/demo12/g' ${POLICY_DIR}/policy/john-prod-ts.yaml > ${POLICY_DIR_TMP}/john-prod-ts-changed.yaml
-aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username John -f ${POLICY_DIR_TMP}/john-prod-ts-changed.yaml
+aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username John --password john -f ${POLICY_DIR_TMP}/john-prod-ts-changed.yaml
 check_policy_version 9
 
 # apply Carol's dependency
-aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Carol -f ${POLICY_DIR}/policy/carol-stage-ts.yaml
+aptomictl --config ${CONF_DIR} policy apply ${WAIT_FLAGS} --username Carol --password carol -f ${POLICY_DIR}/policy/carol-stage-ts.yaml
 check_policy_version 10
 check_policy 3 ".Objects.main.dependency | length"
 
 # delete all dependencies
-aptomictl --config ${CONF_DIR} policy delete ${WAIT_FLAGS} --username Sam -f "${POLICY_DIR}/policy/*-ts.yaml"
+aptomictl --config ${CONF_DIR} policy delete ${WAIT_FLAGS} --username Sam --password sam -f "${POLICY_DIR}/policy/*-ts.yaml"
 check_policy_version 11
 check_policy 0 ".Objects.main.dependency | length"
 
 # delete all definitions
-aptomictl --config ${CONF_DIR} policy delete ${WAIT_FLAGS} --username Sam -f ${POLICY_DIR}/policy
+aptomictl --config ${CONF_DIR} policy delete ${WAIT_FLAGS} --username Sam --password sam -f ${POLICY_DIR}/policy
 check_policy_version 12
 check_policy 0 ".Objects.main.contract | length"
 check_policy 0 ".Objects.main.dependency | length"
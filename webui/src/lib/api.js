@@ -1,3 +1,4 @@
+/* globals localStorage */
 const async = true
 const sync = false
 
@@ -247,9 +248,18 @@ function callAPI (handler, isAsync, successFunc, errorFunc, formData = null) {
   }
   if (formData == null) {
     xhr.open('GET', path, isAsync)
+    setAuthHeader(xhr)
     xhr.send()
   } else {
     xhr.open('POST', path, isAsync)
+    setAuthHeader(xhr)
     xhr.send(formData)
   }
 }
+
+// sets auth token (received on login) for an API call to Aptomi
+function setAuthHeader (xhr) {
+  if (localStorage.token) {
+    xhr.setRequestHeader('Authorization', 'Bearer ' + localStorage.token)
+  }
+}
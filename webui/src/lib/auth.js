@@ -53,7 +53,7 @@ function authenticate (username, password, cb) {
       // eslint-disable-next-line
       cb({
         authenticated: true,
-        token: Math.random().toString(36).substring(7)
+        token: data['token']
       })
     }, this)
 